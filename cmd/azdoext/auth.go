@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/gitexec"
+
+	"github.com/charmbracelet/x/term"
+)
+
+const authUsage = `Usage: azdoext auth <command> [--org <organization url>]
+
+Commands:
+  login    prompt for a PAT, validate it and store it encrypted for the organization
+  status   list the organizations with a stored PAT and whether it is still valid
+  logout   remove the stored PAT of the organization
+
+--org defaults to the organization of the origin remote of the current repository.`
+
+// runAuth runs the auth subcommand and returns the exit code
+func runAuth(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, authUsage)
+		return 2
+	}
+	fs := flag.NewFlagSet("auth "+args[0], flag.ContinueOnError)
+	orgFlag := fs.String("org", "", "Organization (or collection) url, e.g. https://dev.azure.com/myorg")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	dir, err := azdo.DefaultCredentialStoreDir()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	store := azdo.NewCredentialStore(dir)
+
+	switch args[0] {
+	case "login":
+		err = authLogin(store, *orgFlag)
+	case "status":
+		err = authStatus(store)
+	case "logout":
+		err = authLogout(store, *orgFlag)
+	default:
+		fmt.Fprintln(os.Stderr, authUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// originOrgUrl returns orgUrl if set, otherwise the organization url of the origin remote
+func originOrgUrl(orgUrl string) (string, error) {
+	if orgUrl != "" {
+		return strings.TrimRight(orgUrl, "/"), nil
+	}
+	gitconf, err := gitexec.Config()
+	if err != nil {
+		return "", fmt.Errorf("unable to find the organization from the origin remote, use --org: %w", err)
+	}
+	return azdo.GetOrgUrl(gitconf.Origin)
+}
+
+func authLogin(store *azdo.CredentialStore, orgFlag string) error {
+	orgUrl, err := originOrgUrl(orgFlag)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Personal access token for %s: ", orgUrl)
+	pat, err := readSecret()
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return fmt.Errorf("unable to read token: %w", err)
+	}
+	if pat == "" {
+		return errors.New("no token provided")
+	}
+	if !azdo.ValidatePAT(orgUrl, pat) {
+		return fmt.Errorf("token is expired, invalid or has no access to %s", orgUrl)
+	}
+	if err := store.Set(orgUrl, pat); err != nil {
+		return fmt.Errorf("unable to store token: %w", err)
+	}
+	fmt.Printf("Logged in to %s\n", orgUrl)
+	return nil
+}
+
+// readSecret reads a line from stdin without echoing it when stdin is a terminal, so the token can also be piped in
+func readSecret() (string, error) {
+	if term.IsTerminal(os.Stdin.Fd()) {
+		secret, err := term.ReadPassword(os.Stdin.Fd())
+		return strings.TrimSpace(string(secret)), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func authStatus(store *azdo.CredentialStore) error {
+	credentials, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(credentials) == 0 {
+		fmt.Println("No stored credentials, run 'azdoext auth login'")
+		return nil
+	}
+	for _, credential := range credentials {
+		state := "valid"
+		if !azdo.ValidatePAT(credential.OrgUrl, credential.PAT) {
+			state = "expired or invalid"
+		}
+		fmt.Printf("%s: %s (saved %s)\n", credential.OrgUrl, state, credential.SavedAt.Format("2006-01-02"))
+	}
+	return nil
+}
+
+func authLogout(store *azdo.CredentialStore, orgFlag string) error {
+	orgUrl, err := originOrgUrl(orgFlag)
+	if err != nil {
+		return err
+	}
+	deleted, err := store.Delete(orgUrl)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("no stored credential for %s", orgUrl)
+	}
+	fmt.Printf("Logged out of %s\n", orgUrl)
+	return nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/gitexec"
+	"azdoext/pkg/logger"
+)
+
+// exit codes of the non-interactive commands, the build ones let scripts gate on a run result
+const (
+	exitOK            = 0
+	exitError         = 1
+	exitUsage         = 2
+	exitBuildFailed   = 3
+	exitBuildPartial  = 4
+	exitBuildCanceled = 5
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+const cliUsage = `Usage: azdoext [command]
+
+Without a command the terminal UI is started.
+
+Commands:
+  pipelines list           list the pipelines of the repository and their last run
+  run <pipeline>           queue a pipeline by name or id and wait for the result
+  logs <runId>             print the logs of a run
+  pr create --title <t>    open a pull request from the current branch
+  auth <login|status|logout>
+
+Run 'azdoext <command> --help' for the flags of a command.`
+
+// cliOptions are the flags shared by every non-interactive command
+type cliOptions struct {
+	output        string
+	auth          string
+	refreshConfig bool
+}
+
+func (o *cliOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.output, "output", outputText, "Output format, 'text' or 'json'")
+	fs.StringVar(&o.auth, "auth", "", "Comma separated auth methods to try in order, e.g. 'pat,azure-cli'")
+	fs.BoolVar(&o.refreshConfig, "refresh-config", false, "Ignore the cached Azure DevOps config and resolve it again")
+}
+
+func (o *cliOptions) validate() error {
+	if o.output != outputText && o.output != outputJSON {
+		return fmt.Errorf("invalid --output %q, use 'text' or 'json'", o.output)
+	}
+	return nil
+}
+
+// parseArgs parses fs allowing flags after positional arguments, e.g. 'azdoext run build --branch main'
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// cliEnv is what the non-interactive commands need to talk to Azure DevOps, resolved the same way as the TUI
+type cliEnv struct {
+	ctx          context.Context
+	logger       *logger.Logger
+	azdoconfig   azdo.Config
+	authProvider azdo.AuthProvider
+	buildclient  azdo.BuildClientInterface
+	gitclient    azdo.GitClientInterface
+}
+
+func newCLIEnv(ctx context.Context, opts cliOptions) (*cliEnv, error) {
+	logger := logger.NewLogger("cli")
+	authMethods, err := loadAuthMethods(opts.auth)
+	if err != nil {
+		return nil, err
+	}
+	gitconf, err := gitexec.Config()
+	if err != nil {
+		return nil, err
+	}
+	authProvider, err := newAuthProvider(gitconf, authMethods)
+	if err != nil {
+		return nil, err
+	}
+	azdoconfig, err := loadAzdoConfig(gitconf, authProvider, newConfigCache(), opts.refreshConfig, logger)
+	if err != nil {
+		var configErr azdo.ConfigError
+		if errors.As(err, &configErr) {
+			return nil, fmt.Errorf("%w\n%s", err, configErr.Hint())
+		}
+		return nil, err
+	}
+	buildclient, err := azdo.NewBuildClient(ctx, azdoconfig.OrgUrl, azdoconfig.ProjectId, authProvider)
+	if err != nil {
+		return nil, err
+	}
+	gitclient, err := azdo.NewGitClient(ctx, azdoconfig.OrgUrl, azdoconfig.ProjectId, authProvider)
+	if err != nil {
+		return nil, err
+	}
+	return &cliEnv{
+		ctx:          ctx,
+		logger:       logger,
+		azdoconfig:   azdoconfig,
+		authProvider: authProvider,
+		buildclient:  buildclient,
+		gitclient:    gitclient,
+	}, nil
+}
+
+func (e *cliEnv) runUrl(runId int) string {
+	return fmt.Sprintf("%s/%s/_build/results?buildId=%d", e.azdoconfig.OrgUrl, url.PathEscape(e.azdoconfig.ProjectName), runId)
+}
+
+func (e *cliEnv) pullRequestUrl(prId int) string {
+	return fmt.Sprintf("%s/%s/_git/%s/pullrequest/%d", e.azdoconfig.OrgUrl, url.PathEscape(e.azdoconfig.ProjectName), url.PathEscape(e.azdoconfig.RepositoryName), prId)
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// fail prints err and returns exitError, for commands to end with 'return fail(err)'
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	return exitError
+}
+
+// resultExitCode maps a build result to the exit code of the command that waited on it
+func resultExitCode(result string) int {
+	switch result {
+	case "succeeded":
+		return exitOK
+	case "partiallySucceeded":
+		return exitBuildPartial
+	case "canceled":
+		return exitBuildCanceled
+	}
+	return exitBuildFailed
+}
+
+// runCLI runs the non-interactive command in args, ok is false if args is not a command
+func runCLI(args []string) (code int, ok bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	ctx := context.Background()
+	switch args[0] {
+	case "auth":
+		return runAuth(args[1:]), true
+	case "pipelines":
+		if len(args) < 2 || args[1] != "list" {
+			fmt.Fprintln(os.Stderr, "Usage: azdoext pipelines list [--output json]")
+			return exitUsage, true
+		}
+		return runPipelinesList(ctx, args[2:]), true
+	case "run":
+		return runPipelineRun(ctx, args[1:]), true
+	case "logs":
+		return runLogs(ctx, args[1:]), true
+	case "pr":
+		if len(args) < 2 || args[1] != "create" {
+			fmt.Fprintln(os.Stderr, "Usage: azdoext pr create --title <title> [--description <description>] [--target <branch>]")
+			return exitUsage, true
+		}
+		return runPRCreate(ctx, args[2:]), true
+	case "help", "-h", "--help", "-help":
+		fmt.Println(cliUsage)
+		return exitOK, true
+	}
+	if !strings.HasPrefix(args[0], "-") {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", args[0], cliUsage)
+		return exitUsage, true
+	}
+	return 0, false
+}
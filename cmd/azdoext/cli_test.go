@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/build"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		positional []string
+		branch     string
+		wait       bool
+		wantErr    bool
+	}{
+		{"flags first", []string{"--branch", "main", "ci"}, []string{"ci"}, "main", true, false},
+		{"flags after positional", []string{"ci", "--branch", "main", "--wait=false"}, []string{"ci"}, "main", false, false},
+		{"several positional", []string{"pipelines", "list", "--wait=false"}, []string{"pipelines", "list"}, "", false, false},
+		{"no args", nil, nil, "", true, false},
+		{"unknown flag", []string{"ci", "--unknown"}, nil, "", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			branch := fs.String("branch", "", "")
+			wait := fs.Bool("wait", true, "")
+			positional, err := parseArgs(fs, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(positional, tt.positional) || *branch != tt.branch || *wait != tt.wait {
+				t.Errorf("parseArgs() = %q branch %q wait %v, want %q branch %q wait %v", positional, *branch, *wait, tt.positional, tt.branch, tt.wait)
+			}
+		})
+	}
+}
+
+type definitionsClient struct {
+	azdo.BuildClientInterface
+	definitions []build.BuildDefinitionReference
+}
+
+func (c *definitionsClient) GetDefinitions(ctx context.Context, args build.GetDefinitionsArgs) ([]build.BuildDefinitionReference, error) {
+	if len(c.definitions) == 0 {
+		return nil, azdo.ErrNoBuildsFound{}
+	}
+	return c.definitions, nil
+}
+
+func TestFindDefinition(t *testing.T) {
+	definitions := []build.BuildDefinitionReference{
+		{Id: utils.Ptr(12), Name: utils.Ptr("ci"), Path: utils.Ptr(`\`)},
+		{Id: utils.Ptr(34), Name: utils.Ptr("Deploy"), Path: utils.Ptr(`\release`)},
+	}
+	tests := []struct {
+		name        string
+		definitions []build.BuildDefinitionReference
+		pipeline    string
+		wantId      int
+	}{
+		{"by id", definitions, "34", 34},
+		{"by name ignoring case", definitions, "deploy", 34},
+		{"by path and name", definitions, `release\Deploy`, 34},
+		{"at the root", definitions, "ci", 12},
+		{"unknown", definitions, "nightly", 0},
+		{"unknown id", definitions, "56", 0},
+		{"no pipelines", nil, "ci", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &cliEnv{
+				buildclient: &definitionsClient{definitions: tt.definitions},
+				azdoconfig:  azdo.Config{RepositoryId: uuid.New(), RepositoryName: "repo"},
+			}
+			definition, err := findDefinition(context.Background(), env, tt.pipeline)
+			if tt.wantId == 0 {
+				if err == nil {
+					t.Errorf("findDefinition(%q) = %d, want an error", tt.pipeline, *definition.Id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findDefinition(%q) error = %v", tt.pipeline, err)
+			}
+			if *definition.Id != tt.wantId {
+				t.Errorf("findDefinition(%q) = %d, want %d", tt.pipeline, *definition.Id, tt.wantId)
+			}
+		})
+	}
+}
+
+func TestResultExitCode(t *testing.T) {
+	tests := []struct {
+		result string
+		want   int
+	}{
+		{"succeeded", exitOK},
+		{"partiallySucceeded", exitBuildPartial},
+		{"canceled", exitBuildCanceled},
+		{"failed", exitBuildFailed},
+		{"", exitBuildFailed},
+	}
+	for _, tt := range tests {
+		if got := resultExitCode(tt.result); got != tt.want {
+			t.Errorf("resultExitCode(%q) = %d, want %d", tt.result, got, tt.want)
+		}
+	}
+}
@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"azdoext/pkg/azdo"
 	"azdoext/pkg/gitexec"
@@ -12,6 +14,7 @@ import (
 	"azdoext/pkg/logger"
 	"azdoext/pkg/pages"
 	"azdoext/pkg/sections"
+	"azdoext/pkg/settings"
 	"azdoext/pkg/styles"
 	"azdoext/pkg/teamsg"
 
@@ -23,6 +26,7 @@ import (
 type model struct {
 	logger       *logger.Logger
 	initError    string
+	initHint     string
 	ctx          context.Context
 	cancel       context.CancelFunc
 	pages        map[pages.PageName]pages.PageInterface
@@ -31,6 +35,10 @@ type model struct {
 	width        int
 	spinner      spinner.Model
 	authProvider azdo.AuthProvider
+	// configCache is nil when the cache location can't be determined
+	configCache   *azdo.ConfigCache
+	refreshConfig bool
+	authMethods   []azdo.AuthMethod
 }
 
 var version string
@@ -42,46 +50,89 @@ var azdoextLogo = `
 \_/\_(____(____/\__(____(_/\_)(__) 
 `
 
-func initialModel(authProvider azdo.AuthProvider) model {
+func initialModel(authProvider azdo.AuthProvider, configCache *azdo.ConfigCache, refreshConfig bool, authMethods []azdo.AuthMethod) model {
 	ctx, cancel := context.WithCancel(context.Background())
 	spnr := spinner.New()
 	spnr.Spinner = spinner.Line
 	spnr.Style = styles.SpinnerStyle
 
-	logger := logger.NewLogger("main.log")
+	logger := logger.NewLogger("main")
 	helpPage := pages.NewHelpPage()
 	pagesMap := map[pages.PageName]pages.PageInterface{
 		pages.Help: helpPage,
 	}
 	pageStack := pages.Stack{}
 	m := model{
-		logger:       logger,
-		ctx:          ctx,
-		cancel:       cancel,
-		pages:        pagesMap,
-		pageStack:    pageStack,
-		spinner:      spnr,
-		authProvider: authProvider,
+		logger:        logger,
+		ctx:           ctx,
+		cancel:        cancel,
+		pages:         pagesMap,
+		pageStack:     pageStack,
+		spinner:       spnr,
+		authProvider:  authProvider,
+		configCache:   configCache,
+		refreshConfig: refreshConfig,
+		authMethods:   authMethods,
 	}
 	return m
 }
 
-func getAzdoConfig(authProvider azdo.AuthProvider) tea.Cmd {
+func (m *model) getAzdoConfig() tea.Cmd {
+	ctx := m.ctx
+	authProvider := m.authProvider
+	configCache := m.configCache
+	refreshConfig := m.refreshConfig
+	logger := m.logger
 	return func() tea.Msg {
 		gitconf, err := gitexec.Config()
 		if err != nil {
 			return teamsg.AzdoConfigErrorMsg(err)
 		}
-		azdoconfig, err := azdo.GetAzdoConfig(context.Background(), gitconf.Origin, gitconf.CurrentBranch, authProvider)
+		azdoconfig, err := loadAzdoConfig(gitconf, authProvider, configCache, refreshConfig, logger)
 		if err != nil {
 			return teamsg.AzdoConfigErrorMsg(err)
 		}
-		return teamsg.AzdoConfigMsg(azdoconfig)
+		// the clients are created here so that an error creating them is shown like a config error
+		clients, err := azdo.NewClients(ctx, azdoconfig, authProvider)
+		if err != nil {
+			return teamsg.AzdoConfigErrorMsg(err)
+		}
+		return teamsg.AzdoConfigMsg{Config: azdoconfig, Clients: clients}
+	}
+}
+
+// loadAzdoConfig uses the cached config when there is one, validating it in the background so the next start picks up any change.
+// The authorization header is still resolved, so an expired token fails here like it does when resolving the config.
+// Otherwise, or when refreshConfig is set, the config is resolved and cached.
+func loadAzdoConfig(gitconf gitexec.GitConfig, authProvider azdo.AuthProvider, configCache *azdo.ConfigCache, refreshConfig bool, logger *logger.Logger) (azdo.Config, error) {
+	if configCache != nil && !refreshConfig {
+		if azdoconfig, ok := configCache.Load(gitconf.Origin, gitconf.CurrentBranch); ok {
+			if _, err := authProvider(context.Background()); err != nil {
+				return azdo.Config{}, azdo.ErrAuthFailed{OrgUrl: azdoconfig.OrgUrl, Err: err}
+			}
+			logger.Info("using cached azure devops config")
+			go func() {
+				if err := configCache.Validate(context.Background(), gitconf.Origin, gitconf.CurrentBranch, authProvider); err != nil {
+					logger.Warn("cached azure devops config validation failed", "error", err)
+				}
+			}()
+			return azdoconfig, nil
+		}
+	}
+	azdoconfig, err := azdo.GetAzdoConfig(context.Background(), gitconf.Origin, gitconf.CurrentBranch, authProvider)
+	if err != nil {
+		return azdo.Config{}, err
 	}
+	if configCache != nil {
+		if err := configCache.Store(gitconf.Origin, azdoconfig); err != nil {
+			logger.Warn("unable to cache azure devops config", "error", err)
+		}
+	}
+	return azdoconfig, nil
 }
 
 func (m *model) Init() tea.Cmd {
-	return tea.Batch(getAzdoConfig(m.authProvider), m.spinner.Tick)
+	return tea.Batch(m.getAzdoConfig(), m.spinner.Tick)
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -94,17 +145,21 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner = spnr
 	case teamsg.AzdoConfigErrorMsg:
 		m.initError = msg.Error()
+		var configErr azdo.ConfigError
+		if errors.As(msg, &configErr) {
+			m.initHint = configErr.Hint()
+		}
 		return m, nil
 	case teamsg.AzdoConfigMsg:
-		buildclient := azdo.NewBuildClient(m.ctx, msg.OrgUrl, msg.ProjectId, msg.AuthHeader)
-
-		gitclient := azdo.NewGitClient(m.ctx, msg.OrgUrl, msg.ProjectId, msg.AuthHeader)
-		gitpage := pages.NewGitPage(m.ctx, gitclient, azdo.Config(msg))
-		pipelistpage := pages.NewPipelineListPage(m.ctx, buildclient, azdo.Config(msg))
-		pipelinetaskpage := pages.NewPipelineRunPage(m.ctx, buildclient, azdo.Config(msg))
+		clients := msg.Clients
+		gitpage := pages.NewGitPage(m.ctx, clients.Git, clients.Teams, msg.Config, m.authProvider)
+		pipelistpage := pages.NewPipelineListPage(m.ctx, clients.Build, clients.Pipelines, msg.Config)
+		pipelinetaskpage := pages.NewPipelineRunPage(m.ctx, clients.Build, clients.Approvals, msg.Config, m.authProvider)
+		pullrequestspage := pages.NewPullRequestsPage(m.ctx, clients.Git, clients.Policy, msg.Config)
 		m.pages[pages.Git] = gitpage
 		m.pages[pages.PipelineList] = pipelistpage
 		m.pages[pages.PipelineRun] = pipelinetaskpage
+		m.pages[pages.PullRequests] = pullrequestspage
 		m.addPage(pages.Git)
 		return m, nil
 	case tea.KeyPressMsg:
@@ -114,6 +169,11 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cancel()
 				return m, tea.Quit
 			}
+		case "r":
+			if m.initError != "" {
+				m.cancel()
+				return restart(m.configCache, m.refreshConfig, m.authMethods)
+			}
 		case "ctrl+c":
 			m.cancel()
 
@@ -123,12 +183,21 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.addPage(pages.Help)
 			}
 			return m, nil
+		case "ctrl+o":
+			if len(m.pageStack) == 0 {
+				return m, nil
+			}
+			if current := m.pageStack.Peek().GetPageName(); current == pages.Git || current == pages.PipelineList {
+				m.addPage(pages.PullRequests)
+				return m, func() tea.Msg { return teamsg.OpenPullRequestsMsg{} }
+			}
+			return m, nil
 		case "ctrl+b":
 			m.removeCurrentPage()
 			return m, nil
 		case "ctrl+r":
 			m.cancel()
-			return restart()
+			return restart(m.configCache, m.refreshConfig, m.authMethods)
 		}
 	case tea.WindowSizeMsg:
 		m.height = msg.Height
@@ -139,22 +208,27 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	case teamsg.SubmitChoiceMsg:
-		m.logger.LogToFile("debug", fmt.Sprintf("choice received: %s", msg))
+		m.logger.Debug("choice received", "choice", msg)
 		switch listitems.OptionName(msg) {
 		case sections.Options.GoToPipelines:
 			m.addPage(pages.PipelineList)
 		}
 	case teamsg.NothingToCommitMsg:
-		m.logger.LogToFile("info", "nothing to commit")
+		m.logger.Info("nothing to commit")
 		m.addPage(pages.PipelineList)
 
 	case teamsg.GitPRCreatedMsg:
-		m.logger.LogToFile("info", "PR created")
-		m.addPage(pages.PipelineList)
+		m.logger.Info("PR created", "pullRequestId", msg.Id)
+		// the pull requests page shows whether the branch policies of the new PR are satisfied
+		m.addPage(pages.PullRequests)
+		cmds = append(cmds, func() tea.Msg { return teamsg.OpenPullRequestsMsg{} })
 
 	case teamsg.PipelineRunIdMsg:
-		m.logger.LogToFile("info", fmt.Sprintf("received run id: %d", msg.RunId))
-		m.addPage(pages.PipelineRun)
+		m.logger.Info("received run id", "runId", msg.RunId)
+		// a rerun from the run page replaces the monitored run instead of stacking another run page
+		if len(m.pageStack) == 0 || m.pageStack.Peek().GetPageName() != pages.PipelineRun {
+			m.addPage(pages.PipelineRun)
+		}
 	}
 	// update all pages
 	updatedPages := make(map[pages.PageName]pages.PageInterface)
@@ -169,7 +243,11 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m *model) View() tea.View {
 	if m.initError != "" {
-		return tea.NewView(lipgloss.NewStyle().Foreground(styles.Red).Bold(true).Render(m.initError) + "\n\nPress 'enter' or 'ctrl+c' to exit")
+		errorView := lipgloss.NewStyle().Foreground(styles.Red).Bold(true).Render(m.initError)
+		if m.initHint != "" {
+			errorView += "\n\n" + m.initHint
+		}
+		return tea.NewView(errorView + "\n\nPress 'r' to retry, 'enter' or 'ctrl+c' to exit")
 	}
 	loadingStr := lipgloss.NewStyle().Bold(true).Render("Loading...")
 
@@ -191,7 +269,7 @@ func (m *model) addPage(pageName pages.PageName) {
 		for k := range m.pages {
 			availablePages = append(availablePages, string(k))
 		}
-		m.logger.LogToFile("error", fmt.Sprintf("page %s not found, available pages are: %s", pageName, availablePages))
+		m.logger.Error("page not found", "page", pageName, "availablePages", availablePages)
 		return
 	}
 	p.SetAsCurrentPage()
@@ -207,15 +285,24 @@ func (m *model) removeCurrentPage() {
 	m.pageStack.Peek().SetAsCurrentPage()
 }
 
-func restart() (*model, tea.Cmd) {
-	authProvider := resolveAuth()
-	model := initialModel(authProvider)
+func restart(configCache *azdo.ConfigCache, refreshConfig bool, authMethods []azdo.AuthMethod) (*model, tea.Cmd) {
+	authProvider := resolveAuth(authMethods)
+	model := initialModel(authProvider, configCache, refreshConfig, authMethods)
 	return &model, model.Init()
 }
 
+// newConfigCache returns nil if there is no user cache directory, in which case the config is resolved on every start
+func newConfigCache() *azdo.ConfigCache {
+	path, err := azdo.DefaultConfigCachePath()
+	if err != nil {
+		return nil
+	}
+	return azdo.NewConfigCache(path, azdo.DefaultConfigCacheTTL)
+}
+
 // resolveAuth discovers the git remote and resolves authentication before the TUI starts.
 // This ensures interactive prompts (device code) are visible to the user.
-func resolveAuth() azdo.AuthProvider {
+func resolveAuth(authMethods []azdo.AuthMethod) azdo.AuthProvider {
 	gitconf, err := gitexec.Config()
 	if err != nil {
 		// Return a provider that always errors; the TUI will show the error
@@ -223,8 +310,7 @@ func resolveAuth() azdo.AuthProvider {
 			return "", err
 		}
 	}
-	orgUrl := azdo.GetOrgUrl(gitconf.Origin)
-	authProvider, err := azdo.NewAuthProvider(orgUrl)
+	authProvider, err := newAuthProvider(gitconf, authMethods)
 	if err != nil {
 		return func(ctx context.Context) (string, error) {
 			return "", err
@@ -233,16 +319,47 @@ func resolveAuth() azdo.AuthProvider {
 	return authProvider
 }
 
+func newAuthProvider(gitconf gitexec.GitConfig, authMethods []azdo.AuthMethod) (azdo.AuthProvider, error) {
+	orgUrl, err := azdo.GetOrgUrl(gitconf.Origin)
+	if err != nil {
+		return nil, err
+	}
+	return azdo.NewAuthProviderChain(orgUrl, authMethods)
+}
+
+// loadAuthMethods returns the methods of the --auth flag, or of the settings file if the flag is not set.
+// No methods means azdo.DefaultAuthChain.
+func loadAuthMethods(authFlag string) ([]azdo.AuthMethod, error) {
+	if authFlag != "" {
+		return azdo.ParseAuthMethods(strings.Split(authFlag, ","))
+	}
+	s, err := settings.Load()
+	if err != nil {
+		return nil, err
+	}
+	return azdo.ParseAuthMethods(s.Auth)
+}
+
 func main() {
+	if code, ok := runCLI(os.Args[1:]); ok {
+		os.Exit(code)
+	}
 	versionFlag := flag.Bool("version", false, "Print the version and exit")
+	refreshConfigFlag := flag.Bool("refresh-config", false, "Ignore the cached Azure DevOps config and resolve it again")
+	authFlag := flag.String("auth", "", "Comma separated auth methods to try in order, e.g. 'pat,azure-cli'")
 	flag.Parse()
 
 	if *versionFlag {
 		fmt.Println(version)
 		os.Exit(0)
 	}
-	authProvider := resolveAuth()
-	m := initialModel(authProvider)
+	authMethods, err := loadAuthMethods(*authFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	authProvider := resolveAuth(authMethods)
+	m := initialModel(authProvider, newConfigCache(), *refreshConfigFlag, authMethods)
 	if _, err := tea.NewProgram(&m).Run(); err != nil {
 		fmt.Println("Error running program:", err)
 	}
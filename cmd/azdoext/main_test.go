@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/gitexec"
+	"azdoext/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+func TestLoadAzdoConfigCacheHitChecksAuth(t *testing.T) {
+	gitconf := gitexec.GitConfig{Origin: "https://dev.azure.com/org/project/_git/repo", CurrentBranch: "refs/heads/main"}
+	cache := azdo.NewConfigCache(filepath.Join(t.TempDir(), "config.json"), time.Hour)
+	if err := cache.Store(gitconf.Origin, azdo.Config{ProjectId: "project-id", RepositoryId: uuid.New()}); err != nil {
+		t.Fatal(err)
+	}
+	expired := func(ctx context.Context) (string, error) { return "", errors.New("token expired") }
+
+	_, err := loadAzdoConfig(gitconf, expired, cache, false, logger.NewLogger("test"))
+	var authErr azdo.ErrAuthFailed
+	if !errors.As(err, &authErr) || authErr.OrgUrl != "https://dev.azure.com/org" {
+		t.Errorf("loadAzdoConfig() error = %v, want ErrAuthFailed for the organization of the cached config", err)
+	}
+}
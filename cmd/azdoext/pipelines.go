@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/utils"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/build"
+)
+
+// runPollInterval is how often 'azdoext run' checks on the queued run
+const runPollInterval = 5 * time.Second
+
+type pipelineOutput struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Status    string `json:"status"`
+	Result    string `json:"result,omitempty"`
+	LastRunId int    `json:"lastRunId,omitempty"`
+}
+
+type runOutput struct {
+	Id           int    `json:"id"`
+	Pipeline     string `json:"pipeline"`
+	SourceBranch string `json:"sourceBranch"`
+	Status       string `json:"status"`
+	Result       string `json:"result,omitempty"`
+	Url          string `json:"url"`
+}
+
+type logOutput struct {
+	Record string   `json:"record"`
+	Lines  []string `json:"lines"`
+}
+
+func stringValue[T ~string](v *T) string {
+	if v == nil {
+		return ""
+	}
+	return string(*v)
+}
+
+func runPipelinesList(ctx context.Context, args []string) int {
+	var opts cliOptions
+	fs := flag.NewFlagSet("pipelines list", flag.ContinueOnError)
+	opts.register(fs)
+	if _, err := parseArgs(fs, args); err != nil {
+		return exitUsage
+	}
+	if err := opts.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	env, err := newCLIEnv(ctx, opts)
+	if err != nil {
+		return fail(err)
+	}
+	definitions, err := env.buildclient.GetDefinitions(ctx, build.GetDefinitionsArgs{
+		RepositoryId:   utils.Ptr(env.azdoconfig.RepositoryId.String()),
+		RepositoryType: utils.Ptr("TfsGit"),
+	})
+	if err != nil && !errors.Is(err, azdo.ErrNoBuildsFound{}) {
+		return fail(err)
+	}
+	definitionIds := make([]int, 0, len(definitions))
+	for _, definition := range definitions {
+		definitionIds = append(definitionIds, *definition.Id)
+	}
+	latestBuilds, err := azdo.LatestBuilds(ctx, env.buildclient, definitionIds, "", 1)
+	if err != nil {
+		return fail(err)
+	}
+	pipelines := make([]pipelineOutput, 0, len(definitions))
+	for _, definition := range definitions {
+		pipeline := pipelineOutput{
+			Id:     *definition.Id,
+			Name:   *definition.Name,
+			Path:   stringValue(definition.Path),
+			Status: "noRuns",
+		}
+		if builds := latestBuilds[*definition.Id]; len(builds) > 0 {
+			pipeline.Status = stringValue(builds[0].Status)
+			pipeline.Result = stringValue(builds[0].Result)
+			pipeline.LastRunId = *builds[0].Id
+		}
+		pipelines = append(pipelines, pipeline)
+	}
+
+	if opts.output == outputJSON {
+		if err := printJSON(pipelines); err != nil {
+			return fail(err)
+		}
+		return exitOK
+	}
+	if len(pipelines) == 0 {
+		fmt.Println("No pipelines found for this repository")
+		return exitOK
+	}
+	for _, p := range pipelines {
+		state := utils.StatusOrResult(&p.Status, &p.Result)
+		lastRun := "-"
+		if p.LastRunId != 0 {
+			lastRun = strconv.Itoa(p.LastRunId)
+		}
+		fmt.Printf("%-8d %-40s %-20s %s\n", p.Id, p.Name, state, lastRun)
+	}
+	return exitOK
+}
+
+// findDefinition matches pipeline against the id, name or path\name of the repository definitions
+func findDefinition(ctx context.Context, env *cliEnv, pipeline string) (build.BuildDefinitionReference, error) {
+	definitions, err := env.buildclient.GetDefinitions(ctx, build.GetDefinitionsArgs{
+		RepositoryId:   utils.Ptr(env.azdoconfig.RepositoryId.String()),
+		RepositoryType: utils.Ptr("TfsGit"),
+	})
+	if err != nil && !errors.Is(err, azdo.ErrNoBuildsFound{}) {
+		return build.BuildDefinitionReference{}, err
+	}
+	id, idErr := strconv.Atoi(pipeline)
+	for _, definition := range definitions {
+		if idErr == nil && *definition.Id == id {
+			return definition, nil
+		}
+		fullName := strings.TrimPrefix(stringValue(definition.Path)+`\`+*definition.Name, `\`)
+		if strings.EqualFold(*definition.Name, pipeline) || strings.EqualFold(fullName, pipeline) {
+			return definition, nil
+		}
+	}
+	return build.BuildDefinitionReference{}, fmt.Errorf("pipeline %q not found in repository %s", pipeline, env.azdoconfig.RepositoryName)
+}
+
+func runPipelineRun(ctx context.Context, args []string) int {
+	var opts cliOptions
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	opts.register(fs)
+	branch := fs.String("branch", "", "Branch to run the pipeline on, defaults to the current branch")
+	wait := fs.Bool("wait", true, "Wait for the run to complete, the exit code reflects its result")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: azdoext run <pipeline name or id> [--branch <branch>] [--wait=false] [--output json]")
+		return exitUsage
+	}
+	if err := opts.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	env, err := newCLIEnv(ctx, opts)
+	if err != nil {
+		return fail(err)
+	}
+	definition, err := findDefinition(ctx, env, positional[0])
+	if err != nil {
+		return fail(err)
+	}
+	sourceBranch := *branch
+	if sourceBranch == "" {
+		sourceBranch = env.azdoconfig.CurrentBranch
+	}
+	runId, err := env.buildclient.QueueBuild(ctx, build.QueueBuildArgs{
+		Project: &env.azdoconfig.ProjectId,
+		Build: &build.Build{
+			SourceBranch: utils.Ptr(sourceBranch),
+			Definition: &build.DefinitionReference{
+				Id: definition.Id,
+			},
+		},
+	})
+	if err != nil {
+		return fail(err)
+	}
+	run := runOutput{
+		Id:           runId,
+		Pipeline:     *definition.Name,
+		SourceBranch: sourceBranch,
+		Status:       string(build.BuildStatusValues.NotStarted),
+		Url:          env.runUrl(runId),
+	}
+	if opts.output == outputText {
+		fmt.Printf("Queued run %d of %s on %s\n%s\n", runId, run.Pipeline, sourceBranch, run.Url)
+	}
+	if !*wait {
+		if opts.output == outputJSON {
+			if err := printJSON(run); err != nil {
+				return fail(err)
+			}
+		}
+		return exitOK
+	}
+
+	for run.Status != string(build.BuildStatusValues.Completed) {
+		if err := utils.SleepWithContext(ctx, runPollInterval); err != nil {
+			return fail(err)
+		}
+		builds, err := env.buildclient.GetBuilds(ctx, build.GetBuildsArgs{BuildIds: &[]int{runId}})
+		if err != nil {
+			return fail(err)
+		}
+		if len(builds) == 0 {
+			return fail(fmt.Errorf("run %d not found", runId))
+		}
+		status := stringValue(builds[0].Status)
+		if status != run.Status && opts.output == outputText {
+			fmt.Printf("%s: %s\n", time.Now().Format(time.TimeOnly), status)
+		}
+		run.Status = status
+		run.Result = stringValue(builds[0].Result)
+	}
+
+	if opts.output == outputJSON {
+		if err := printJSON(run); err != nil {
+			return fail(err)
+		}
+	} else {
+		fmt.Printf("Run %d %s\n", runId, run.Result)
+	}
+	return resultExitCode(run.Result)
+}
+
+func runLogs(ctx context.Context, args []string) int {
+	var opts cliOptions
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	opts.register(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: azdoext logs <runId> [--output json]")
+		return exitUsage
+	}
+	runId, err := strconv.Atoi(positional[0])
+	if err != nil {
+		return fail(fmt.Errorf("invalid run id %q", positional[0]))
+	}
+	if err := opts.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	env, err := newCLIEnv(ctx, opts)
+	if err != nil {
+		return fail(err)
+	}
+	records, err := env.buildclient.GetBuildTimelineRecords(ctx, build.GetBuildTimelineArgs{
+		Project: &env.azdoconfig.ProjectId,
+		BuildId: &runId,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	// only tasks carry their own logs, jobs and stages logs just repeat them
+	records = tasksWithLogs(records)
+
+	logs := make([]logOutput, 0, len(records))
+	for _, record := range records {
+		reader, err := env.buildclient.GetTimelineRecordLog(ctx, build.GetBuildLogArgs{
+			Project: &env.azdoconfig.ProjectId,
+			BuildId: &runId,
+			LogId:   record.Log.Id,
+		})
+		if err != nil {
+			return fail(err)
+		}
+		var lines []string
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		reader.Close()
+		if err := scanner.Err(); err != nil {
+			return fail(err)
+		}
+		logs = append(logs, logOutput{Record: *record.Name, Lines: lines})
+	}
+
+	if opts.output == outputJSON {
+		if err := printJSON(logs); err != nil {
+			return fail(err)
+		}
+		return exitOK
+	}
+	for _, log := range logs {
+		fmt.Printf("##[section] %s\n", log.Record)
+		for _, line := range log.Lines {
+			fmt.Println(line)
+		}
+	}
+	return exitOK
+}
+
+// tasksWithLogs keeps the task records that have a log, in the order they ran
+func tasksWithLogs(records []build.TimelineRecord) []build.TimelineRecord {
+	var tasks []build.TimelineRecord
+	for _, record := range records {
+		if record.Type != nil && *record.Type == "Task" && record.Log != nil && record.Log.Id != nil {
+			tasks = append(tasks, record)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].StartTime == nil || tasks[j].StartTime == nil {
+			return tasks[j].StartTime == nil && tasks[i].StartTime != nil
+		}
+		return tasks[i].StartTime.Time.Before(tasks[j].StartTime.Time)
+	})
+	return tasks
+}
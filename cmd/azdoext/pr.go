@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"azdoext/pkg/utils"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+type pullRequestOutput struct {
+	Id           int    `json:"id"`
+	Title        string `json:"title"`
+	SourceBranch string `json:"sourceBranch"`
+	TargetBranch string `json:"targetBranch"`
+	IsDraft      bool   `json:"isDraft"`
+	Url          string `json:"url"`
+}
+
+func runPRCreate(ctx context.Context, args []string) int {
+	var opts cliOptions
+	fs := flag.NewFlagSet("pr create", flag.ContinueOnError)
+	opts.register(fs)
+	title := fs.String("title", "", "Title of the pull request (required)")
+	description := fs.String("description", "", "Description of the pull request")
+	source := fs.String("source", "", "Source branch, defaults to the current branch")
+	target := fs.String("target", "", "Target branch, defaults to the default branch of the repository")
+	draft := fs.Bool("draft", false, "Create the pull request as a draft")
+	if _, err := parseArgs(fs, args); err != nil {
+		return exitUsage
+	}
+	if *title == "" {
+		fmt.Fprintln(os.Stderr, "Usage: azdoext pr create --title <title> [--description <description>] [--target <branch>]")
+		return exitUsage
+	}
+	if err := opts.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	env, err := newCLIEnv(ctx, opts)
+	if err != nil {
+		return fail(err)
+	}
+	sourceBranch := *source
+	if sourceBranch == "" {
+		sourceBranch = env.azdoconfig.CurrentBranch
+	}
+	targetBranch := *target
+	if targetBranch == "" {
+		targetBranch = env.azdoconfig.DefaultBranch
+	}
+	sourceBranch = utils.FormatBranchName(sourceBranch)
+	targetBranch = utils.FormatBranchName(targetBranch)
+	if sourceBranch == targetBranch {
+		return fail(errors.New("source and target branch are the same, checkout a different branch or use --source"))
+	}
+
+	env.logger.Info("creating PR", "title", *title, "source", sourceBranch, "target", targetBranch)
+	pr, err := env.gitclient.CreatePullRequest(ctx, git.CreatePullRequestArgs{
+		RepositoryId: utils.Ptr(env.azdoconfig.RepositoryId.String()),
+		Project:      &env.azdoconfig.ProjectId,
+		GitPullRequestToCreate: &git.GitPullRequest{
+			Title:         title,
+			Description:   description,
+			SourceRefName: &sourceBranch,
+			TargetRefName: &targetBranch,
+			IsDraft:       draft,
+		},
+	})
+	if err != nil {
+		return fail(err)
+	}
+	output := pullRequestOutput{
+		Id:           *pr.PullRequestId,
+		Title:        *title,
+		SourceBranch: sourceBranch,
+		TargetBranch: targetBranch,
+		IsDraft:      *draft,
+		Url:          env.pullRequestUrl(*pr.PullRequestId),
+	}
+	if opts.output == outputJSON {
+		if err := printJSON(output); err != nil {
+			return fail(err)
+		}
+		return exitOK
+	}
+	fmt.Printf("Created pull request %d: %s\n%s\n", output.Id, output.Title, output.Url)
+	return exitOK
+}
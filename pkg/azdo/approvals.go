@@ -0,0 +1,148 @@
+package azdo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/pipelinesapproval"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+)
+
+// ApprovalStep is one of the approvers of an approval and what they did
+type ApprovalStep struct {
+	Approver string
+	Status   string
+	Comment  string
+}
+
+// Approval is a manual approval check of an environment or resource a stage is waiting on,
+// its id is the id of the Checkpoint.Approval record of the run timeline
+type Approval struct {
+	Id                   uuid.UUID
+	Status               string
+	Instructions         string
+	MinRequiredApprovers int
+	Steps                []ApprovalStep
+}
+
+func (a Approval) IsPending() bool {
+	return a.Status == string(pipelinesapproval.ApprovalStatusValues.Pending)
+}
+
+type ApprovalsClientInterface interface {
+	GetApprovals(ctx context.Context, approvalIds []uuid.UUID) (map[uuid.UUID]Approval, error)
+	UpdateApproval(ctx context.Context, approvalId uuid.UUID, approve bool, comment string) error
+}
+
+type ApprovalsClient struct {
+	client    pipelinesapproval.Client
+	projectid string
+}
+
+func NewApprovalsClient(ctx context.Context, orgurl, projectid string, authProvider AuthProvider) (ApprovalsClientInterface, error) {
+	azdoconn, err := newAuthConnection(ctx, orgurl, authProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create approvals client: %w", err)
+	}
+	client, err := pipelinesapproval.NewClient(ctx, azdoconn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create approvals client: %w", err)
+	}
+	withAuthProvider(&client.(*pipelinesapproval.ClientImpl).Client, authProvider)
+	return &ApprovalsClient{
+		client:    client,
+		projectid: projectid,
+	}, nil
+}
+
+// GetApprovals returns the approvals with their steps, keyed by id
+func (a *ApprovalsClient) GetApprovals(ctx context.Context, approvalIds []uuid.UUID) (map[uuid.UUID]Approval, error) {
+	approvals, err := a.client.QueryApprovals(ctx, pipelinesapproval.QueryApprovalsArgs{
+		Project:     &a.projectid,
+		ApprovalIds: &approvalIds,
+		Expand:      &pipelinesapproval.ApprovalDetailsExpandParameterValues.Steps,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approvals: %w", err)
+	}
+	byId := make(map[uuid.UUID]Approval)
+	if approvals == nil {
+		return byId, nil
+	}
+	for _, approval := range *approvals {
+		if approval.Id == nil {
+			continue
+		}
+		byId[*approval.Id] = convertApproval(approval)
+	}
+	return byId, nil
+}
+
+// UpdateApproval approves or rejects an approval on behalf of the authenticated user
+func (a *ApprovalsClient) UpdateApproval(ctx context.Context, approvalId uuid.UUID, approve bool, comment string) error {
+	status, action := pipelinesapproval.ApprovalStatusValues.Rejected, "reject"
+	if approve {
+		status, action = pipelinesapproval.ApprovalStatusValues.Approved, "approve"
+	}
+	_, err := a.client.UpdateApprovals(ctx, pipelinesapproval.UpdateApprovalsArgs{
+		Project: &a.projectid,
+		UpdateParameters: &[]pipelinesapproval.ApprovalUpdateParameters{{
+			ApprovalId: &approvalId,
+			Status:     &status,
+			Comment:    &comment,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to %s approval: %s", action, serviceMessage(err))
+	}
+	return nil
+}
+
+func convertApproval(approval pipelinesapproval.Approval) Approval {
+	converted := Approval{Id: *approval.Id}
+	if approval.Status != nil {
+		converted.Status = string(*approval.Status)
+	}
+	if approval.Instructions != nil {
+		converted.Instructions = *approval.Instructions
+	}
+	if approval.MinRequiredApprovers != nil {
+		converted.MinRequiredApprovers = *approval.MinRequiredApprovers
+	}
+	if approval.Steps == nil {
+		return converted
+	}
+	for _, step := range *approval.Steps {
+		converted.Steps = append(converted.Steps, convertApprovalStep(step))
+	}
+	return converted
+}
+
+// convertApprovalStep names the approver, groups are assigned but a member actually approves
+func convertApprovalStep(step pipelinesapproval.ApprovalStep) ApprovalStep {
+	converted := ApprovalStep{Approver: identityName(step.AssignedApprover)}
+	if actual := identityName(step.ActualApprover); actual != "" && actual != converted.Approver {
+		converted.Approver = fmt.Sprintf("%s (for %s)", actual, converted.Approver)
+	}
+	if step.Status != nil {
+		converted.Status = string(*step.Status)
+	}
+	if step.Comment != nil {
+		converted.Comment = *step.Comment
+	}
+	return converted
+}
+
+func identityName(identity *webapi.IdentityRef) string {
+	if identity == nil {
+		return ""
+	}
+	if identity.DisplayName != nil && *identity.DisplayName != "" {
+		return *identity.DisplayName
+	}
+	if identity.UniqueName != nil {
+		return *identity.UniqueName
+	}
+	return ""
+}
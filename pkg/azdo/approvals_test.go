@@ -0,0 +1,64 @@
+package azdo
+
+import (
+	"reflect"
+	"testing"
+
+	"azdoext/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/pipelinesapproval"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+)
+
+func TestConvertApproval(t *testing.T) {
+	id := uuid.New()
+	approval := pipelinesapproval.Approval{
+		Id:                   &id,
+		Status:               &pipelinesapproval.ApprovalStatusValues.Pending,
+		Instructions:         utils.Ptr("check the release notes"),
+		MinRequiredApprovers: utils.Ptr(1),
+		Steps: &[]pipelinesapproval.ApprovalStep{
+			{
+				AssignedApprover: &webapi.IdentityRef{DisplayName: utils.Ptr("Release Managers")},
+				ActualApprover:   &webapi.IdentityRef{DisplayName: utils.Ptr("Alex")},
+				Status:           &pipelinesapproval.ApprovalStatusValues.Rejected,
+				Comment:          utils.Ptr("not today"),
+			},
+			{
+				AssignedApprover: &webapi.IdentityRef{UniqueName: utils.Ptr("sam@example.com")},
+				Status:           &pipelinesapproval.ApprovalStatusValues.Pending,
+			},
+		},
+	}
+	got := convertApproval(approval)
+	want := Approval{
+		Id:                   id,
+		Status:               "pending",
+		Instructions:         "check the release notes",
+		MinRequiredApprovers: 1,
+		Steps: []ApprovalStep{
+			{Approver: "Alex (for Release Managers)", Status: "rejected", Comment: "not today"},
+			{Approver: "sam@example.com", Status: "pending"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("convertApproval() = %+v, want %+v", got, want)
+	}
+	if !got.IsPending() {
+		t.Error("expected approval to be pending")
+	}
+}
+
+func TestIsCheckRecord(t *testing.T) {
+	for recordType, want := range map[string]bool{
+		"Checkpoint.Approval":  true,
+		"Checkpoint.TaskCheck": true,
+		"Checkpoint":           false,
+		"Stage":                false,
+	} {
+		if got := IsCheckRecord(recordType); got != want {
+			t.Errorf("IsCheckRecord(%q) = %v, want %v", recordType, got, want)
+		}
+	}
+}
@@ -5,74 +5,142 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"azdoext/pkg/logger"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
 )
 
 // AuthProvider returns the Authorization header value ("Basic ..." or "Bearer ...").
+// It is called on every request, so long sessions pick up refreshed tokens.
 type AuthProvider func(ctx context.Context) (string, error)
 
+// tokenRefreshMargin is how long before expiry a cached OAuth token is refreshed
+const tokenRefreshMargin = 5 * time.Minute
+
 // azureDevOpsScope is the OAuth scope for Azure DevOps.
 const azureDevOpsScope = "499b84ac-1321-427f-aa17-267ca6975798/.default"
 
-// NewAuthProvider creates an AuthProvider using the following resolution order:
-// 1. PAT (if AZDO_PERSONAL_ACCESS_TOKEN env var is set and valid)
-// 2. Azure CLI credential (reuses az login tokens)
-func NewAuthProvider(orgUrl string) (AuthProvider, error) {
-	if pat := os.Getenv("AZDO_PERSONAL_ACCESS_TOKEN"); pat != "" {
-		provider := newPatAuthProvider(pat)
-		if validateAuthHeader(provider) {
-			return provider, nil
-		}
-		fmt.Fprintln(os.Stderr, "PAT validation failed (expired or invalid), falling back to Azure CLI auth")
-	}
+const defaultHTTPTimeout = 30 * time.Second
 
-	cliCred, err := azidentity.NewAzureCLICredential(nil)
-	if err != nil {
-		return nil, fmt.Errorf("no valid authentication found.\n\nEither:\n  - Run 'az login'\n  - Set AZDO_PERSONAL_ACCESS_TOKEN env var")
-	}
-	provider := newOAuthProvider(cliCred)
-	if _, err := provider(context.Background()); err != nil {
-		return nil, fmt.Errorf("Azure CLI credential failed.\n\nEither:\n  - Run 'az login'\n  - Set AZDO_PERSONAL_ACCESS_TOKEN env var")
+// HTTPTimeout bounds every request made to Azure DevOps, it can be overridden with AZDOEXT_HTTP_TIMEOUT (e.g. "90s")
+var HTTPTimeout = httpTimeoutFromEnv()
+
+// httpClient is shared by every raw http call of this package so connections are reused
+var httpClient = &http.Client{Timeout: HTTPTimeout}
+
+func httpTimeoutFromEnv() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("AZDOEXT_HTTP_TIMEOUT"))
+	if err != nil || timeout <= 0 {
+		return defaultHTTPTimeout
 	}
-	return provider, nil
+	return timeout
+}
+
+// NewAuthProvider creates an AuthProvider using DefaultAuthChain, see NewAuthProviderChain
+func NewAuthProvider(orgUrl string) (AuthProvider, error) {
+	return NewAuthProviderChain(orgUrl, DefaultAuthChain)
 }
 
 func newPatAuthProvider(pat string) AuthProvider {
-	header := fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(":"+pat)))
+	encoded := base64.StdEncoding.EncodeToString([]byte(":" + pat))
+	logger.RegisterSecret(pat)
+	logger.RegisterSecret(encoded)
+	header := "Basic " + encoded
 	return func(ctx context.Context) (string, error) {
 		return header, nil
 	}
 }
 
+// newOAuthProvider caches the token until it is about to expire, getting a token from the Azure CLI spawns a process
 func newOAuthProvider(cred azcore.TokenCredential) AuthProvider {
+	var mu sync.Mutex
+	var token azcore.AccessToken
 	return func(ctx context.Context) (string, error) {
-		token, err := cred.GetToken(ctx, policy.TokenRequestOptions{
+		mu.Lock()
+		defer mu.Unlock()
+		if token.Token != "" && time.Until(token.ExpiresOn) > tokenRefreshMargin {
+			return "Bearer " + token.Token, nil
+		}
+		newToken, err := cred.GetToken(ctx, policy.TokenRequestOptions{
 			Scopes: []string{azureDevOpsScope},
 		})
 		if err != nil {
 			return "", fmt.Errorf("failed to get OAuth token: %w", err)
 		}
+		token = newToken
+		logger.RegisterSecret(token.Token)
 		return "Bearer " + token.Token, nil
 	}
 }
 
+// authTransport sets the Authorization header of every request from an AuthProvider
+type authTransport struct {
+	authProvider AuthProvider
+	base         http.RoundTripper
+}
+
+func (t authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	header, err := t.authProvider(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authorization header: %w", err)
+	}
+	// a RoundTripper must not modify the original request
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", header)
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		throttle.record(resp, time.Now())
+	}
+	return resp, err
+}
+
+// newAuthHTTPClient returns an http client that authenticates every request with authProvider
+func newAuthHTTPClient(authProvider AuthProvider) *http.Client {
+	return &http.Client{
+		Timeout: HTTPTimeout,
+		Transport: authTransport{
+			authProvider: authProvider,
+			base:         http.DefaultTransport,
+		},
+	}
+}
+
+// newAuthConnection creates a connection whose clients should be passed to withAuthProvider.
+// The current header is only used to resolve the resource area of each client.
+func newAuthConnection(ctx context.Context, orgUrl string, authProvider AuthProvider) (*azuredevops.Connection, error) {
+	authHeader, err := authProvider(ctx)
+	if err != nil {
+		return nil, ErrAuthFailed{OrgUrl: orgUrl, Err: err}
+	}
+	return NewConnection(orgUrl, authHeader), nil
+}
+
+// withAuthProvider makes client get its Authorization header from authProvider on every request instead of the
+// header captured by the connection
+func withAuthProvider(client *azuredevops.Client, authProvider AuthProvider) {
+	azuredevops.WithHTTPClient(newAuthHTTPClient(authProvider))(client)
+}
+
 // validateAuthHeader checks if the auth header is valid by making a lightweight API call.
-func validateAuthHeader(provider AuthProvider) bool {
+func validateAuthHeader(orgUrl string, provider AuthProvider) bool {
 	header, err := provider(context.Background())
 	if err != nil {
 		return false
 	}
-	req, err := http.NewRequest("GET", "https://app.vssps.visualstudio.com/_apis/profile/profiles/me?api-version=7.1", nil)
+	req, err := http.NewRequest("GET", validationUrl(orgUrl), nil)
 	if err != nil {
 		return false
 	}
 	req.Header.Add("Authorization", header)
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return false
 	}
@@ -80,11 +148,22 @@ func validateAuthHeader(provider AuthProvider) bool {
 	return resp.StatusCode == http.StatusOK
 }
 
+// validationUrl returns the profile endpoint for Azure DevOps Services and the projects endpoint for Azure DevOps Server,
+// which has no profile service
+func validationUrl(orgUrl string) string {
+	u, err := url.Parse(orgUrl)
+	if err == nil && !(Remote{Host: u.Host}).IsCloud() {
+		return strings.TrimSuffix(orgUrl, "/") + "/_apis/projects?$top=1"
+	}
+	return "https://app.vssps.visualstudio.com/_apis/profile/profiles/me?api-version=7.1"
+}
+
 // NewConnection creates an azuredevops.Connection using the given auth header.
 func NewConnection(orgUrl string, authHeader string) *azuredevops.Connection {
 	return &azuredevops.Connection{
 		AuthorizationString:     authHeader,
 		BaseUrl:                 orgUrl,
 		SuppressFedAuthRedirect: true,
+		Timeout:                 &HTTPTimeout,
 	}
 }
@@ -0,0 +1,76 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+type fakeCredential struct {
+	calls     int
+	expiresIn time.Duration
+}
+
+func (f *fakeCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	f.calls++
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(f.expiresIn)}, nil
+}
+
+func TestOAuthProviderCachesToken(t *testing.T) {
+	cred := &fakeCredential{expiresIn: time.Hour}
+	provider := newOAuthProvider(cred)
+	for range 3 {
+		header, err := provider(context.Background())
+		if err != nil {
+			t.Fatalf("provider returned error: %v", err)
+		}
+		if header != "Bearer token" {
+			t.Errorf("provider returned %q; want %q", header, "Bearer token")
+		}
+	}
+	if cred.calls != 1 {
+		t.Errorf("GetToken called %d times; want 1", cred.calls)
+	}
+}
+
+func TestOAuthProviderRefreshesExpiringToken(t *testing.T) {
+	cred := &fakeCredential{expiresIn: tokenRefreshMargin - time.Minute}
+	provider := newOAuthProvider(cred)
+	for range 2 {
+		if _, err := provider(context.Background()); err != nil {
+			t.Fatalf("provider returned error: %v", err)
+		}
+	}
+	if cred.calls != 2 {
+		t.Errorf("GetToken called %d times; want 2", cred.calls)
+	}
+}
+
+func TestAuthHTTPClientUsesCurrentHeader(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	current := "Bearer first"
+	client := newAuthHTTPClient(func(ctx context.Context) (string, error) { return current, nil })
+	for _, header := range []string{"Bearer first", "Bearer second"} {
+		current = header
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		req.Header.Set("Authorization", "Bearer stale")
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		res.Body.Close()
+	}
+	if len(received) != 2 || received[0] != "Bearer first" || received[1] != "Bearer second" {
+		t.Errorf("server received %v; want [Bearer first Bearer second]", received)
+	}
+}
@@ -0,0 +1,224 @@
+package azdo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"azdoext/pkg/logger"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+type AuthMethod string
+
+const (
+	AuthPAT               AuthMethod = "pat"
+	AuthStoredPAT         AuthMethod = "stored-pat"
+	AuthSystemAccessToken AuthMethod = "system-access-token"
+	AuthServicePrincipal  AuthMethod = "service-principal"
+	AuthWorkloadIdentity  AuthMethod = "workload-identity"
+	AuthManagedIdentity   AuthMethod = "managed-identity"
+	AuthAzureCLI          AuthMethod = "azure-cli"
+	AuthDeviceCode        AuthMethod = "device-code"
+)
+
+// AllAuthMethods lists every supported method, in the order they are documented
+var AllAuthMethods = []AuthMethod{
+	AuthPAT,
+	AuthStoredPAT,
+	AuthSystemAccessToken,
+	AuthServicePrincipal,
+	AuthWorkloadIdentity,
+	AuthManagedIdentity,
+	AuthAzureCLI,
+	AuthDeviceCode,
+}
+
+// DefaultAuthChain is used when no method is configured. Managed identity is left out because probing the
+// metadata endpoint outside Azure takes a while, device code because it is interactive.
+var DefaultAuthChain = []AuthMethod{
+	AuthPAT,
+	AuthStoredPAT,
+	AuthSystemAccessToken,
+	AuthServicePrincipal,
+	AuthWorkloadIdentity,
+	AuthAzureCLI,
+}
+
+// managedIdentityTimeout bounds the managed identity token request, which hangs outside Azure
+const managedIdentityTimeout = 10 * time.Second
+
+// ErrAuthSkipped explains why an auth method of the chain was not used
+type ErrAuthSkipped struct {
+	Method AuthMethod
+	Reason string
+}
+
+func (e ErrAuthSkipped) Error() string {
+	return fmt.Sprintf("%s: %s", e.Method, e.Reason)
+}
+
+// ParseAuthMethods converts method names (e.g. from the --auth flag) to AuthMethods
+func ParseAuthMethods(names []string) ([]AuthMethod, error) {
+	var methods []AuthMethod
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		method := AuthMethod(name)
+		if !isAuthMethod(method) {
+			valid := make([]string, len(AllAuthMethods))
+			for i, m := range AllAuthMethods {
+				valid[i] = string(m)
+			}
+			return nil, fmt.Errorf("unknown auth method %q, valid methods are: %s", name, strings.Join(valid, ", "))
+		}
+		methods = append(methods, method)
+	}
+	return methods, nil
+}
+
+func isAuthMethod(method AuthMethod) bool {
+	for _, m := range AllAuthMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// NewAuthProviderChain returns the provider of the first method that is usable for orgUrl.
+// If none is, the error lists why each method was skipped.
+func NewAuthProviderChain(orgUrl string, methods []AuthMethod) (AuthProvider, error) {
+	if len(methods) == 0 {
+		methods = DefaultAuthChain
+	}
+	var skipped []error
+	for _, method := range methods {
+		provider, err := newMethodAuthProvider(orgUrl, method)
+		if err == nil {
+			return provider, nil
+		}
+		skipped = append(skipped, ErrAuthSkipped{Method: method, Reason: err.Error()})
+	}
+	reasons := make([]string, len(skipped))
+	for i, err := range skipped {
+		reasons[i] = "  - " + err.Error()
+	}
+	return nil, fmt.Errorf("no valid authentication found, tried:\n%s\n\n%w", strings.Join(reasons, "\n"), errors.Join(skipped...))
+}
+
+func newMethodAuthProvider(orgUrl string, method AuthMethod) (AuthProvider, error) {
+	switch method {
+	case AuthPAT:
+		pat := os.Getenv("AZDO_PERSONAL_ACCESS_TOKEN")
+		if pat == "" {
+			return nil, errors.New("AZDO_PERSONAL_ACCESS_TOKEN is not set")
+		}
+		provider := newPatAuthProvider(pat)
+		if !validateAuthHeader(orgUrl, provider) {
+			return nil, errors.New("AZDO_PERSONAL_ACCESS_TOKEN is expired or invalid")
+		}
+		return provider, nil
+	case AuthStoredPAT:
+		dir, err := DefaultCredentialStoreDir()
+		if err != nil {
+			return nil, err
+		}
+		pat, ok, err := NewCredentialStore(dir).Get(orgUrl)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("no PAT stored for %s, run 'azdoext auth login'", orgUrl)
+		}
+		provider := newPatAuthProvider(pat)
+		if !validateAuthHeader(orgUrl, provider) {
+			return nil, fmt.Errorf("stored PAT for %s is expired or invalid, run 'azdoext auth login' again", orgUrl)
+		}
+		return provider, nil
+	case AuthSystemAccessToken:
+		// the job access token of Azure Pipelines, it has no access to the profile service so it isn't validated
+		token := os.Getenv("SYSTEM_ACCESSTOKEN")
+		if token == "" {
+			return nil, errors.New("SYSTEM_ACCESSTOKEN is not set, map it in the pipeline step with 'env: SYSTEM_ACCESSTOKEN: $(System.AccessToken)'")
+		}
+		logger.RegisterSecret(token)
+		header := "Bearer " + token
+		return func(ctx context.Context) (string, error) {
+			return header, nil
+		}, nil
+	case AuthServicePrincipal:
+		if os.Getenv("AZURE_TENANT_ID") == "" || os.Getenv("AZURE_CLIENT_ID") == "" {
+			return nil, errors.New("AZURE_TENANT_ID and AZURE_CLIENT_ID are not set")
+		}
+		if os.Getenv("AZURE_CLIENT_SECRET") == "" && os.Getenv("AZURE_CLIENT_CERTIFICATE_PATH") == "" {
+			return nil, errors.New("neither AZURE_CLIENT_SECRET nor AZURE_CLIENT_CERTIFICATE_PATH is set")
+		}
+		cred, err := azidentity.NewEnvironmentCredential(nil)
+		if err != nil {
+			return nil, err
+		}
+		return newValidatedOAuthProvider(cred, 0)
+	case AuthWorkloadIdentity:
+		if os.Getenv("AZURE_FEDERATED_TOKEN_FILE") == "" {
+			return nil, errors.New("AZURE_FEDERATED_TOKEN_FILE is not set")
+		}
+		cred, err := azidentity.NewWorkloadIdentityCredential(nil)
+		if err != nil {
+			return nil, err
+		}
+		return newValidatedOAuthProvider(cred, 0)
+	case AuthManagedIdentity:
+		var opts *azidentity.ManagedIdentityCredentialOptions
+		if clientId := os.Getenv("AZURE_CLIENT_ID"); clientId != "" {
+			opts = &azidentity.ManagedIdentityCredentialOptions{ID: azidentity.ClientID(clientId)}
+		}
+		cred, err := azidentity.NewManagedIdentityCredential(opts)
+		if err != nil {
+			return nil, err
+		}
+		return newValidatedOAuthProvider(cred, managedIdentityTimeout)
+	case AuthAzureCLI:
+		cred, err := azidentity.NewAzureCLICredential(nil)
+		if err != nil {
+			return nil, err
+		}
+		provider, err := newValidatedOAuthProvider(cred, 0)
+		if err != nil {
+			return nil, fmt.Errorf("run 'az login': %w", err)
+		}
+		return provider, nil
+	case AuthDeviceCode:
+		cred, err := azidentity.NewDeviceCodeCredential(&azidentity.DeviceCodeCredentialOptions{
+			TenantID: os.Getenv("AZURE_TENANT_ID"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return newValidatedOAuthProvider(cred, 0)
+	}
+	return nil, fmt.Errorf("unknown auth method")
+}
+
+// newValidatedOAuthProvider gets a first token so an unusable credential is skipped right away.
+// A zero timeout means no timeout other than the credential's own.
+func newValidatedOAuthProvider(cred azcore.TokenCredential, timeout time.Duration) (AuthProvider, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	provider := newOAuthProvider(cred)
+	if _, err := provider(ctx); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
@@ -0,0 +1,69 @@
+package azdo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseAuthMethods(t *testing.T) {
+	methods, err := ParseAuthMethods([]string{"pat", " azure-cli ", ""})
+	if err != nil {
+		t.Fatalf("ParseAuthMethods returned error: %v", err)
+	}
+	if len(methods) != 2 || methods[0] != AuthPAT || methods[1] != AuthAzureCLI {
+		t.Errorf("ParseAuthMethods() = %v; want [pat azure-cli]", methods)
+	}
+	if _, err := ParseAuthMethods([]string{"kerberos"}); err == nil {
+		t.Errorf("ParseAuthMethods(kerberos) returned no error")
+	}
+}
+
+func TestNewAuthProviderChainSkipped(t *testing.T) {
+	for _, env := range []string{"AZDO_PERSONAL_ACCESS_TOKEN", "SYSTEM_ACCESSTOKEN", "AZURE_TENANT_ID", "AZURE_CLIENT_ID", "AZURE_FEDERATED_TOKEN_FILE"} {
+		t.Setenv(env, "")
+	}
+	methods := []AuthMethod{AuthPAT, AuthSystemAccessToken, AuthServicePrincipal, AuthWorkloadIdentity}
+	_, err := NewAuthProviderChain("https://dev.azure.com/org", methods)
+	if err == nil {
+		t.Fatal("NewAuthProviderChain returned no error")
+	}
+	for _, method := range methods {
+		found := false
+		for e := range skippedErrors(err) {
+			if e.Method == method && e.Reason != "" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("error doesn't report why %s was skipped: %v", method, err)
+		}
+	}
+}
+
+func TestNewAuthProviderChainSystemAccessToken(t *testing.T) {
+	t.Setenv("AZDO_PERSONAL_ACCESS_TOKEN", "")
+	t.Setenv("SYSTEM_ACCESSTOKEN", "job-token")
+	provider, err := NewAuthProviderChain("https://dev.azure.com/org", []AuthMethod{AuthPAT, AuthSystemAccessToken})
+	if err != nil {
+		t.Fatalf("NewAuthProviderChain returned error: %v", err)
+	}
+	header, err := provider(context.Background())
+	if err != nil || header != "Bearer job-token" {
+		t.Errorf("provider() = %q, %v; want %q", header, err, "Bearer job-token")
+	}
+}
+
+func skippedErrors(err error) map[ErrAuthSkipped]struct{} {
+	found := make(map[ErrAuthSkipped]struct{})
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		for _, e := range joined.Unwrap() {
+			var skipped ErrAuthSkipped
+			if errors.As(e, &skipped) {
+				found[skipped] = struct{}{}
+			}
+		}
+	}
+	return found
+}
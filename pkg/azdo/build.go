@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"io"
 	"slices"
+	"strconv"
+	"strings"
+
+	"azdoext/pkg/utils"
 
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/build"
 )
@@ -23,6 +27,11 @@ type BuildClientInterface interface {
 	QueueBuild(context.Context, build.QueueBuildArgs) (int, error)
 	GetDefinitions(context.Context, build.GetDefinitionsArgs) ([]build.BuildDefinitionReference, error)
 	GetBuilds(context.Context, build.GetBuildsArgs) ([]build.Build, error)
+	GetBuildsPage(context.Context, build.GetBuildsArgs) ([]build.Build, string, error)
+	CancelBuild(ctx context.Context, buildId int) error
+	RetryFailedJobs(ctx context.Context, buildId int) error
+	RetryStage(ctx context.Context, buildId int, stageRefName string) error
+	RerunBuild(ctx context.Context, buildId int) (int, error)
 }
 
 type BuildClient struct {
@@ -30,16 +39,20 @@ type BuildClient struct {
 	projectid string
 }
 
-func NewBuildClient(ctx context.Context, orgurl, projectid, authHeader string) BuildClientInterface {
-	azdoconn := NewConnection(orgurl, authHeader)
+func NewBuildClient(ctx context.Context, orgurl, projectid string, authProvider AuthProvider) (BuildClientInterface, error) {
+	azdoconn, err := newAuthConnection(ctx, orgurl, authProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create build client: %w", err)
+	}
 	client, err := build.NewClient(ctx, azdoconn)
 	if err != nil {
-		panic(fmt.Sprintf("failed to create build client: %v", err))
+		return nil, fmt.Errorf("failed to create build client: %w", err)
 	}
+	withAuthProvider(&client.(*build.ClientImpl).Client, authProvider)
 	return BuildClient{
 		projectid: projectid,
 		Client:    client,
-	}
+	}, nil
 }
 
 func (b BuildClient) GetBuildTimelineRecords(ctx context.Context, args build.GetBuildTimelineArgs) ([]build.TimelineRecord, error) {
@@ -51,7 +64,7 @@ func (b BuildClient) GetBuildTimelineRecords(ctx context.Context, args build.Get
 	return *timeline.Records, nil
 }
 
-// GetFilteredBuildTimelineRecords returning only stage, job and task records
+// GetFilteredBuildTimelineRecords returning only stage, job and task records, and the approvals and checks stages wait on
 func (b BuildClient) GetFilteredBuildTimelineRecords(ctx context.Context, args build.GetBuildTimelineArgs) ([]build.TimelineRecord, error) {
 	args.Project = &b.projectid
 	timeline, err := b.Client.GetBuildTimeline(ctx, args)
@@ -63,11 +76,17 @@ func (b BuildClient) GetFilteredBuildTimelineRecords(ctx context.Context, args b
 			"Stage",
 			"Job",
 			"Task",
-			"Phase", // Phase is required because it sits between Stage and Jobs, so Jobs's parents are actually Phase, not Stage
-		}, *record.Type)
+			"Phase",      // Phase is required because it sits between Stage and Jobs, so Jobs's parents are actually Phase, not Stage
+			"Checkpoint", // same for Checkpoint, the parent of the approvals and checks a stage waits on
+		}, *record.Type) && !IsCheckRecord(*record.Type)
 	}), nil
 }
 
+// IsCheckRecord tells whether a timeline record type is an approval or check of a stage, e.g. Checkpoint.Approval
+func IsCheckRecord(recordType string) bool {
+	return strings.HasPrefix(recordType, "Checkpoint.")
+}
+
 func (b BuildClient) QueueBuild(ctx context.Context, args build.QueueBuildArgs) (int, error) {
 	args.Project = &b.projectid
 	build, err := b.Client.QueueBuild(ctx, args)
@@ -106,6 +125,171 @@ func (b BuildClient) GetBuilds(ctx context.Context, args build.GetBuildsArgs) ([
 	return builds, nil
 }
 
+// CancelBuild requests the cancellation of a queued or running build
+func (b BuildClient) CancelBuild(ctx context.Context, buildId int) error {
+	_, err := b.Client.UpdateBuild(ctx, build.UpdateBuildArgs{
+		Project: &b.projectid,
+		BuildId: &buildId,
+		Build:   &build.Build{Status: &build.BuildStatusValues.Cancelling},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel build: %w", err)
+	}
+	return nil
+}
+
+// RetryFailedJobs reruns the failed and canceled jobs of a completed build, keeping the successful ones
+func (b BuildClient) RetryFailedJobs(ctx context.Context, buildId int) error {
+	retry := true
+	_, err := b.Client.UpdateBuild(ctx, build.UpdateBuildArgs{
+		Project: &b.projectid,
+		BuildId: &buildId,
+		Build:   &build.Build{},
+		Retry:   &retry,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retry build: %w", err)
+	}
+	return nil
+}
+
+// RetryStage reruns the failed jobs of a stage, stageRefName is the stage identifier in the yaml, not its display name
+func (b BuildClient) RetryStage(ctx context.Context, buildId int, stageRefName string) error {
+	forceRetryAllJobs := false
+	err := b.Client.UpdateStage(ctx, build.UpdateStageArgs{
+		Project:      &b.projectid,
+		BuildId:      &buildId,
+		StageRefName: &stageRefName,
+		UpdateParameters: &build.UpdateStageParameters{
+			ForceRetryAllJobs: &forceRetryAllJobs,
+			State:             &build.StageUpdateTypeValues.Retry,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retry stage %s: %w", stageRefName, err)
+	}
+	return nil
+}
+
+// RerunBuild queues a new build of the same definition, source version and parameters as buildId
+func (b BuildClient) RerunBuild(ctx context.Context, buildId int) (int, error) {
+	original, err := b.Client.GetBuild(ctx, build.GetBuildArgs{
+		Project: &b.projectid,
+		BuildId: &buildId,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get build %d: %w", buildId, err)
+	}
+	rerun, err := b.Client.QueueBuild(ctx, build.QueueBuildArgs{
+		Project: &b.projectid,
+		Build: &build.Build{
+			Definition:         &build.DefinitionReference{Id: original.Definition.Id},
+			SourceBranch:       original.SourceBranch,
+			SourceVersion:      original.SourceVersion,
+			Parameters:         original.Parameters,
+			TemplateParameters: original.TemplateParameters,
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to rerun build %d: %w", buildId, err)
+	}
+	return *rerun.Id, nil
+}
+
+// GetBuildsPage returns one page of builds and the continuation token of the next one, empty on the last page.
+// Unlike GetBuilds, an empty page is not an error
+func (b BuildClient) GetBuildsPage(ctx context.Context, args build.GetBuildsArgs) ([]build.Build, string, error) {
+	args.Project = &b.projectid
+	buildsResponse, err := b.Client.GetBuilds(ctx, args)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get builds: %w", err)
+	}
+	return buildsResponse.Value, buildsResponse.ContinuationToken, nil
+}
+
+// ResolveDefinitions returns the definitions referenced by id or by path, e.g. \infra\deploy-app, with a single request.
+// Paths are matched case insensitively, like Azure DevOps does, and references matching no definition are left out
+func ResolveDefinitions(ctx context.Context, client BuildClientInterface, refs []string) ([]build.BuildDefinitionReference, error) {
+	var ids []int
+	paths := make(map[string]bool)
+	for _, ref := range refs {
+		if id, err := strconv.Atoi(strings.TrimSpace(ref)); err == nil {
+			ids = append(ids, id)
+			continue
+		}
+		paths[strings.ToLower(normalizeDefinitionPath(ref))] = true
+	}
+	args := build.GetDefinitionsArgs{}
+	if len(paths) == 0 {
+		if len(ids) == 0 {
+			return nil, nil
+		}
+		args.DefinitionIds = &ids
+	}
+	definitions, err := client.GetDefinitions(ctx, args)
+	if err != nil {
+		if errors.Is(err, ErrNoBuildsFound{}) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var resolved []build.BuildDefinitionReference
+	for _, definition := range definitions {
+		if slices.Contains(ids, *definition.Id) || paths[strings.ToLower(DefinitionPath(definition))] {
+			resolved = append(resolved, definition)
+		}
+	}
+	return resolved, nil
+}
+
+// DefinitionPath returns the folder and name of a definition, e.g. \infra\deploy-app
+func DefinitionPath(definition build.BuildDefinitionReference) string {
+	folder := ""
+	if definition.Path != nil {
+		folder = *definition.Path
+	}
+	return normalizeDefinitionPath(folder + `\` + *definition.Name)
+}
+
+func normalizeDefinitionPath(path string) string {
+	path = strings.ReplaceAll(strings.TrimSpace(path), "/", `\`)
+	return `\` + strings.Trim(path, `\`)
+}
+
+// LatestBuilds returns up to perDefinition of the latest builds of each definition, newest first, with a single request
+// instead of one per definition. If branch is set only builds of that branch are returned
+func LatestBuilds(ctx context.Context, client BuildClientInterface, definitionIds []int, branch string, perDefinition int) (map[int][]build.Build, error) {
+	latest := make(map[int][]build.Build)
+	if len(definitionIds) == 0 {
+		return latest, nil
+	}
+	args := build.GetBuildsArgs{
+		Definitions:            &definitionIds,
+		MaxBuildsPerDefinition: &perDefinition,
+		QueryOrder:             &build.BuildQueryOrderValues.QueueTimeDescending,
+	}
+	if branch != "" {
+		args.BranchName = utils.Ptr(utils.FormatBranchName(branch))
+	}
+	builds, err := client.GetBuilds(ctx, args)
+	if err != nil {
+		if errors.Is(err, ErrNoBuildsFound{}) {
+			return latest, nil
+		}
+		return nil, err
+	}
+	for _, b := range builds {
+		if b.Definition == nil || b.Definition.Id == nil {
+			continue
+		}
+		id := *b.Definition.Id
+		if len(latest[id]) < perDefinition {
+			latest[id] = append(latest[id], b)
+		}
+	}
+	return latest, nil
+}
+
 func (b BuildClient) GetTimelineRecordLog(ctx context.Context, args build.GetBuildLogArgs) (io.ReadCloser, error) {
 	logReader, err := b.Client.GetBuildLog(ctx, args)
 	if err != nil {
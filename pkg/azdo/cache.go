@@ -0,0 +1,159 @@
+package azdo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultConfigCacheTTL is how long a resolved config is trusted before GetAzdoConfig runs again on startup
+const DefaultConfigCacheTTL = 24 * time.Hour
+
+type cachedConfig struct {
+	AccountId     string    `json:"accountId"`
+	ProjectId     string    `json:"projectId"`
+	RepositoryId  uuid.UUID `json:"repositoryId"`
+	DefaultBranch string    `json:"defaultBranch"`
+	CachedAt      time.Time `json:"cachedAt"`
+}
+
+// ConfigCache stores the ids resolved by GetAzdoConfig on disk, keyed by remote url,
+// so startup doesn't have to wait on the lookups every time.
+type ConfigCache struct {
+	mu   sync.Mutex
+	path string
+	ttl  time.Duration
+}
+
+func NewConfigCache(path string, ttl time.Duration) *ConfigCache {
+	return &ConfigCache{
+		path: path,
+		ttl:  ttl,
+	}
+}
+
+// DefaultConfigCachePath returns config-cache.json under the user cache directory (e.g. ~/.cache/azdoext)
+func DefaultConfigCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "azdoext", "config-cache.json"), nil
+}
+
+func (c *ConfigCache) read() (map[string]cachedConfig, error) {
+	entries := make(map[string]cachedConfig)
+	content, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		// a corrupted cache is the same as an empty one, it gets overwritten on the next write
+		return make(map[string]cachedConfig), nil
+	}
+	return entries, nil
+}
+
+func (c *ConfigCache) write(entries map[string]cachedConfig) error {
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// Load builds a Config from the cache entry of remoteUrl, it returns false if there is no entry or it has expired
+func (c *ConfigCache) Load(remoteUrl, currentBranch string) (Config, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries, err := c.read()
+	if err != nil {
+		return Config{}, false
+	}
+	entry, ok := entries[remoteUrl]
+	if !ok || time.Since(entry.CachedAt) > c.ttl {
+		return Config{}, false
+	}
+	remote, err := ParseRemoteUrl(remoteUrl)
+	if err != nil {
+		return Config{}, false
+	}
+	return Config{
+		AccoundId:      entry.AccountId,
+		OrgUrl:         remote.BaseUrl(),
+		OrgName:        remote.Collection,
+		ProjectName:    remote.Project,
+		ProjectId:      entry.ProjectId,
+		RepositoryName: remote.Repository,
+		RepositoryId:   entry.RepositoryId,
+		CurrentBranch:  currentBranch,
+		DefaultBranch:  entry.DefaultBranch,
+	}, true
+}
+
+// Store saves the ids of config under remoteUrl
+func (c *ConfigCache) Store(remoteUrl string, config Config) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries, err := c.read()
+	if err != nil {
+		return err
+	}
+	entries[remoteUrl] = cachedConfig{
+		AccountId:     config.AccoundId,
+		ProjectId:     config.ProjectId,
+		RepositoryId:  config.RepositoryId,
+		DefaultBranch: config.DefaultBranch,
+		CachedAt:      time.Now(),
+	}
+	return c.write(entries)
+}
+
+// Delete removes the entry of remoteUrl
+func (c *ConfigCache) Delete(remoteUrl string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries, err := c.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[remoteUrl]; !ok {
+		return nil
+	}
+	delete(entries, remoteUrl)
+	return c.write(entries)
+}
+
+// Validate resolves the config again and refreshes the cache entry of remoteUrl.
+// If the organization, project or repository no longer exists the entry is dropped,
+// transient errors (auth, network) leave it untouched.
+func (c *ConfigCache) Validate(ctx context.Context, remoteUrl, currentBranch string, authProvider AuthProvider) error {
+	config, err := GetAzdoConfig(ctx, remoteUrl, currentBranch, authProvider)
+	if err != nil {
+		if errors.As(err, &ErrOrgNotFound{}) || errors.As(err, &ErrProjectNotFound{}) ||
+			errors.As(err, &ErrRepoNotFound{}) || errors.As(err, &ErrNoDefaultBranch{}) {
+			if deleteErr := c.Delete(remoteUrl); deleteErr != nil {
+				return errors.Join(err, deleteErr)
+			}
+		}
+		return err
+	}
+	return c.Store(remoteUrl, config)
+}
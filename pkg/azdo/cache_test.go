@@ -0,0 +1,78 @@
+package azdo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testRemoteUrl = "https://dev.azure.com/MyOrg/MyProject/_git/MyRepo"
+
+func TestConfigCacheStoreAndLoad(t *testing.T) {
+	cache := NewConfigCache(filepath.Join(t.TempDir(), "azdoext", "config-cache.json"), time.Hour)
+	stored := Config{
+		AccoundId:     "account-id",
+		ProjectId:     "project-id",
+		RepositoryId:  uuid.New(),
+		DefaultBranch: "refs/heads/main",
+	}
+	if err := cache.Store(testRemoteUrl, stored); err != nil {
+		t.Fatalf("Store returned error: %v", err)
+	}
+
+	loaded, ok := cache.Load(testRemoteUrl, "feature")
+	if !ok {
+		t.Fatalf("Load(%q) found no entry", testRemoteUrl)
+	}
+	want := Config{
+		AccoundId:      "account-id",
+		OrgUrl:         "https://dev.azure.com/MyOrg",
+		OrgName:        "MyOrg",
+		ProjectName:    "MyProject",
+		ProjectId:      "project-id",
+		RepositoryName: "MyRepo",
+		RepositoryId:   stored.RepositoryId,
+		CurrentBranch:  "feature",
+		DefaultBranch:  "refs/heads/main",
+	}
+	if loaded != want {
+		t.Errorf("Load() = %+v; want %+v", loaded, want)
+	}
+
+	if err := cache.Delete(testRemoteUrl); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, ok := cache.Load(testRemoteUrl, "feature"); ok {
+		t.Errorf("Load() found an entry after Delete")
+	}
+}
+
+func TestConfigCacheExpired(t *testing.T) {
+	cache := NewConfigCache(filepath.Join(t.TempDir(), "config-cache.json"), 0)
+	if err := cache.Store(testRemoteUrl, Config{ProjectId: "project-id"}); err != nil {
+		t.Fatalf("Store returned error: %v", err)
+	}
+	if _, ok := cache.Load(testRemoteUrl, "main"); ok {
+		t.Errorf("Load() returned an expired entry")
+	}
+}
+
+func TestConfigCacheCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config-cache.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewConfigCache(path, time.Hour)
+	if _, ok := cache.Load(testRemoteUrl, "main"); ok {
+		t.Errorf("Load() returned an entry from a corrupted cache")
+	}
+	if err := cache.Store(testRemoteUrl, Config{ProjectId: "project-id"}); err != nil {
+		t.Fatalf("Store over a corrupted cache returned error: %v", err)
+	}
+	if _, ok := cache.Load(testRemoteUrl, "main"); !ok {
+		t.Errorf("Load() found no entry after overwriting a corrupted cache")
+	}
+}
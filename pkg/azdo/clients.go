@@ -0,0 +1,47 @@
+package azdo
+
+import "context"
+
+// Clients are the clients of every Azure DevOps API the TUI uses
+type Clients struct {
+	Build     BuildClientInterface
+	Git       GitClientInterface
+	Teams     TeamsClientInterface
+	Pipelines PipelinesClientInterface
+	Approvals ApprovalsClientInterface
+	Policy    PolicyClientInterface
+}
+
+// NewClients creates the clients of azdoconfig's project. Creating a client gets an authorization header and looks
+// its API up on the organization, so every returned error implements ConfigError like the ones of GetAzdoConfig
+func NewClients(ctx context.Context, azdoconfig Config, authProvider AuthProvider) (Clients, error) {
+	var (
+		clients Clients
+		err     error
+	)
+	orgurl, projectid := azdoconfig.OrgUrl, azdoconfig.ProjectId
+	if clients.Build, err = NewBuildClient(ctx, orgurl, projectid, authProvider); err != nil {
+		return Clients{}, clientError(err, azdoconfig)
+	}
+	if clients.Git, err = NewGitClient(ctx, orgurl, projectid, authProvider); err != nil {
+		return Clients{}, clientError(err, azdoconfig)
+	}
+	if clients.Teams, err = NewTeamsClient(ctx, orgurl, projectid, authProvider); err != nil {
+		return Clients{}, clientError(err, azdoconfig)
+	}
+	if clients.Pipelines, err = NewPipelinesClient(ctx, orgurl, projectid, authProvider); err != nil {
+		return Clients{}, clientError(err, azdoconfig)
+	}
+	if clients.Approvals, err = NewApprovalsClient(ctx, orgurl, projectid, authProvider); err != nil {
+		return Clients{}, clientError(err, azdoconfig)
+	}
+	if clients.Policy, err = NewPolicyClient(ctx, orgurl, projectid, authProvider); err != nil {
+		return Clients{}, clientError(err, azdoconfig)
+	}
+	return clients, nil
+}
+
+// clientError classifies the error of a client constructor, the APIs are looked up on the organization
+func clientError(err error, azdoconfig Config) error {
+	return classifyError(err, azdoconfig.OrgUrl, func(err error) error { return ErrOrgNotFound{Org: azdoconfig.OrgName, Err: err} })
+}
@@ -0,0 +1,16 @@
+package azdo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNewClientsAuthFailure(t *testing.T) {
+	expired := func(ctx context.Context) (string, error) { return "", errors.New("token expired") }
+	_, err := NewClients(context.Background(), Config{OrgUrl: "https://dev.azure.com/org", ProjectId: "project"}, expired)
+	var configErr ConfigError
+	if !errors.As(err, &configErr) || !errors.As(err, &ErrAuthFailed{}) {
+		t.Errorf("NewClients() error = %v, want an ErrAuthFailed with its hint", err)
+	}
+}
@@ -4,11 +4,12 @@ import (
 	"azdoext/pkg/utils"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/google/uuid"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
@@ -17,87 +18,146 @@ import (
 )
 
 type Config struct {
+	// OrgUrl is the organization url on Azure DevOps Services or the collection url on Azure DevOps Server
 	OrgUrl         string
 	OrgName        string
 	AccoundId      string
 	ProjectName    string
 	ProjectId      string
-	AuthHeader     string
 	RepositoryName string
 	RepositoryId   uuid.UUID
 	CurrentBranch  string
 	DefaultBranch  string
 }
 
+// GetAzdoConfig resolves everything needed to talk to Azure DevOps for the given remote.
+// Every returned error implements ConfigError.
 func GetAzdoConfig(ctx context.Context, remoteUrl string, currentBranch string, authProvider AuthProvider) (Config, error) {
+	remote, err := ParseRemoteUrl(remoteUrl)
+	if err != nil {
+		return Config{}, err
+	}
+	orgurl := remote.BaseUrl()
 	authHeader, err := authProvider(ctx)
 	if err != nil {
-		return Config{}, fmt.Errorf("authentication failed: %w", err)
+		return Config{}, ErrAuthFailed{OrgUrl: orgurl, Err: err}
 	}
-	orgurl := GetOrgUrl(remoteUrl)
-	orgname := getOrgName(remoteUrl)
 	conn := NewConnection(orgurl, authHeader)
-	projectname := getProjectName(remoteUrl)
-	reponame := getRepositoryName(remoteUrl)
+	projectname := remote.Project
+	reponame := remote.Repository
+
+	// the lookups are independent, so they run concurrently and errors are checked in order of dependency:
+	// a missing project also fails the repository lookup, but reporting the project is more useful
+	var (
+		wg                              sync.WaitGroup
+		accountid, projectid            string
+		repositoryid                    uuid.UUID
+		defaultbranch                   string
+		accountErr, projectErr, repoErr error
+	)
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		if remote.IsCloud() {
+			accountid, accountErr = getAccountId(ctx, remote.Collection, authHeader)
+			// tokens scoped to the organization, like the pipeline job token, can't read the profile,
+			// connectionData returns the organization id as instance id as well
+			if code := statusCode(accountErr); code == http.StatusUnauthorized || code == http.StatusForbidden {
+				accountid, accountErr = getCollectionId(ctx, orgurl, authHeader)
+			}
+		} else {
+			accountid, accountErr = getCollectionId(ctx, orgurl, authHeader)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		projectid, projectErr = getProjectId(ctx, conn, projectname)
+	}()
+	go func() {
+		defer wg.Done()
+		repositoryid, defaultbranch, repoErr = getRepository(ctx, conn, projectname, reponame)
+	}()
+	wg.Wait()
+
+	if accountErr != nil {
+		return Config{}, classifyError(accountErr, orgurl, func(err error) error { return ErrOrgNotFound{Org: remote.Collection, Err: err} })
+	}
+	if projectErr != nil {
+		return Config{}, classifyError(projectErr, orgurl, func(err error) error { return ErrProjectNotFound{Project: projectname, Err: err} })
+	}
+	if repoErr != nil {
+		return Config{}, classifyError(repoErr, orgurl, func(err error) error {
+			return ErrRepoNotFound{Project: projectname, Repository: reponame, Err: err}
+		})
+	}
 	return Config{
-		AccoundId:      getAccountId(orgname, authHeader),
+		AccoundId:      accountid,
 		OrgUrl:         orgurl,
-		OrgName:        orgname,
+		OrgName:        remote.Collection,
 		ProjectName:    projectname,
 		RepositoryName: reponame,
-		ProjectId:      getProjectId(conn, projectname),
-		RepositoryId:   getRepositoryId(conn, projectname, reponame),
-		AuthHeader:     authHeader,
+		ProjectId:      projectid,
+		RepositoryId:   repositoryid,
 		CurrentBranch:  currentBranch,
-		DefaultBranch:  getDefaultBranch(conn, projectname, reponame),
+		DefaultBranch:  defaultbranch,
 	}, nil
 }
 
-func getDefaultBranch(conn *azuredevops.Connection, projectname, reponame string) string {
-	client, err := git.NewClient(context.Background(), conn)
+// getRepository returns the repository id and default branch with a single request
+func getRepository(ctx context.Context, conn *azuredevops.Connection, projectname, reponame string) (uuid.UUID, string, error) {
+	client, err := git.NewClient(ctx, conn)
 	if err != nil {
-		panic(fmt.Errorf("failed to get git client: %v", err))
+		return uuid.Nil, "", fmt.Errorf("failed to get git client: %w", err)
 	}
-	repo, err := client.GetRepository(context.Background(), git.GetRepositoryArgs{
+	repo, err := client.GetRepository(ctx, git.GetRepositoryArgs{
 		Project:      utils.Ptr(projectname),
 		RepositoryId: utils.Ptr(reponame),
 	})
 	if err != nil {
-		panic(fmt.Errorf("failed to get repository: %v", err))
+		return uuid.Nil, "", fmt.Errorf("failed to get repository: %w", err)
 	}
 	if repo.DefaultBranch == nil {
-		panic("default branch not found")
+		return uuid.Nil, "", ErrNoDefaultBranch{Repository: reponame}
 	}
-	return *repo.DefaultBranch
+	return *repo.Id, *repo.DefaultBranch, nil
 }
 
-func getUserId(authHeader string) string {
-	url := "https://app.vssps.visualstudio.com/_apis/profile/profiles/me?api-version=7.1"
-	method := "GET"
-
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, nil)
-
+// getJSON makes an authenticated GET request and decodes the response body into v
+func getJSON(ctx context.Context, url, authHeader string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		panic(err)
+		return err
 	}
 	req.Header.Add("Authorization", authHeader)
 
-	res, err := client.Do(req)
+	res, err := httpClient.Do(req)
 	if err != nil {
-		panic(err)
+		return err
 	}
 	defer res.Body.Close()
+	if err := httpStatusError(res); err != nil {
+		return err
+	}
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", url, err)
+	}
+	return nil
+}
+
+func getUserId(ctx context.Context, authHeader string) (string, error) {
 	var currentUser struct {
 		Id string `json:"id"`
 	}
-	json.Unmarshal(body, &currentUser)
-	return currentUser.Id
+	err := getJSON(ctx, "https://app.vssps.visualstudio.com/_apis/profile/profiles/me?api-version=7.1", authHeader, &currentUser)
+	if err != nil {
+		return "", err
+	}
+	return currentUser.Id, nil
 }
 
 type accounts struct {
@@ -111,169 +171,64 @@ type account struct {
 	AccountName string `json:"accountName"`
 }
 
-func getAccountId(orgName, authHeader string) string {
-	userid := getUserId(authHeader)
-	if userid == "" {
-		panic("user id not found")
-	}
-	url := fmt.Sprintf("https://app.vssps.visualstudio.com/_apis/accounts?memberId=%s&api-version=7.1", userid)
-	method := "GET"
-
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, nil)
-
-	if err != nil {
-		panic(err)
-	}
-	req.Header.Add("Authorization", authHeader)
-
-	res, err := client.Do(req)
+func getAccountId(ctx context.Context, orgName, authHeader string) (string, error) {
+	userid, err := getUserId(ctx, authHeader)
 	if err != nil {
-		panic(err)
+		return "", err
 	}
-	defer res.Body.Close()
-
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		panic(err)
+	if userid == "" {
+		return "", ErrAuthFailed{OrgUrl: orgName, Err: errors.New("user id not found")}
 	}
+	url := fmt.Sprintf("https://app.vssps.visualstudio.com/_apis/accounts?memberId=%s&api-version=7.1", userid)
 	var accounts accounts
-	json.Unmarshal(body, &accounts)
+	if err := getJSON(ctx, url, authHeader, &accounts); err != nil {
+		return "", err
+	}
 	for _, account := range accounts.Value {
 		if strings.EqualFold(account.AccountName, orgName) {
-			return account.AccountId
+			return account.AccountId, nil
 		}
 	}
-	panic("account not found")
+	return "", ErrOrgNotFound{Org: orgName}
 }
 
-// isSSHUrl checks if the remote URL is in SSH format (git@ssh.dev.azure.com:...)
-func isSSHUrl(remoteUrl string) bool {
-	return strings.HasPrefix(remoteUrl, "git@ssh.dev.azure.com:")
-}
-
-func getOrgName(remoteUrl string) string {
-	remoteurl_parts := strings.Split(remoteUrl, "/")
-	if isSSHUrl(remoteUrl) {
-		// SSH format: git@ssh.dev.azure.com:v3/org/project/repo
-		// After split: [git@ssh.dev.azure.com:v3, org, project, repo]
-		if len(remoteurl_parts) < 2 {
-			panic(fmt.Errorf("invalid SSH URL format: %s", remoteUrl))
-		}
-		return remoteurl_parts[1]
-	} else {
-		// HTTPS format: https://dev.azure.com/org/project/_git/repo
-		// After split: [https:, , dev.azure.com, org, project, _git, repo]
-		if len(remoteurl_parts) < 4 {
-			panic(fmt.Errorf("invalid HTTPS URL format: %s", remoteUrl))
-		}
-		return remoteurl_parts[3]
+// getCollectionId returns the collection instance id, which plays the role of the account id on Azure DevOps Server
+func getCollectionId(ctx context.Context, orgUrl, authHeader string) (string, error) {
+	var connectionData struct {
+		InstanceId string `json:"instanceId"`
 	}
-}
-
-func GetOrgUrl(remoteUrl string) string {
-	orgname := getOrgName(remoteUrl)
-	// Always return HTTPS format for org URL
-	return fmt.Sprintf("https://dev.azure.com/%s", orgname)
-}
-
-func getProjectName(remoteUrl string) string {
-	remoteurl_parts := strings.Split(remoteUrl, "/")
-	if isSSHUrl(remoteUrl) {
-		// SSH format: git@ssh.dev.azure.com:v3/org/project/repo
-		// After split: [git@ssh.dev.azure.com:v3, org, project, repo]
-		if len(remoteurl_parts) < 3 {
-			panic(fmt.Errorf("invalid SSH URL format: %s", remoteUrl))
-		}
-		projectname, err := url.QueryUnescape(remoteurl_parts[2])
-		if err != nil {
-			panic(fmt.Errorf("failed to unescape project name: %v", err))
-		}
-		return projectname
-	} else {
-		// Long format:  https://dev.azure.com/org/project/_git/repo
-		// After split:  [https:, , dev.azure.com, org, project, _git, repo] (7 parts)
-		// Short format: https://dev.azure.com/org/_git/repo (project == repo)
-		// After split:  [https:, , dev.azure.com, org, _git, repo] (6 parts)
-		if len(remoteurl_parts) >= 7 {
-			projectname, err := url.QueryUnescape(remoteurl_parts[4])
-			if err != nil {
-				panic(fmt.Errorf("failed to unescape project name: %v", err))
-			}
-			return projectname
-		}
-		if len(remoteurl_parts) >= 6 && remoteurl_parts[4] == "_git" {
-			// Short format: project name == repo name
-			projectname, err := url.QueryUnescape(remoteurl_parts[5])
-			if err != nil {
-				panic(fmt.Errorf("failed to unescape project name: %v", err))
-			}
-			return projectname
-		}
-		panic(fmt.Errorf("invalid HTTPS URL format: %s", remoteUrl))
+	if err := getJSON(ctx, orgUrl+"/_apis/connectionData", authHeader, &connectionData); err != nil {
+		return "", err
 	}
+	return connectionData.InstanceId, nil
 }
 
-func getRepositoryName(remoteUrl string) string {
-	remoteurl_parts := strings.Split(remoteUrl, "/")
-	if isSSHUrl(remoteUrl) {
-		// SSH format: git@ssh.dev.azure.com:v3/org/project/repo
-		// After split: [git@ssh.dev.azure.com:v3, org, project, repo]
-		if len(remoteurl_parts) < 4 {
-			panic(fmt.Errorf("invalid SSH URL format: %s", remoteUrl))
-		}
-		reponame, err := url.QueryUnescape(remoteurl_parts[3])
-		if err != nil {
-			panic(fmt.Errorf("failed to unescape repository name: %v", err))
-		}
-		return reponame
-	} else {
-		// Long format:  https://dev.azure.com/org/project/_git/repo → index 6
-		// Short format: https://dev.azure.com/org/_git/repo → index 5
-		if len(remoteurl_parts) >= 7 {
-			reponame, err := url.QueryUnescape(remoteurl_parts[6])
-			if err != nil {
-				panic(fmt.Errorf("failed to unescape repository name: %v", err))
-			}
-			return reponame
-		}
-		if len(remoteurl_parts) >= 6 && remoteurl_parts[4] == "_git" {
-			reponame, err := url.QueryUnescape(remoteurl_parts[5])
-			if err != nil {
-				panic(fmt.Errorf("failed to unescape repository name: %v", err))
-			}
-			return reponame
-		}
-		panic(fmt.Errorf("invalid HTTPS URL format: %s", remoteUrl))
+// getAuthenticatedUserId returns the id of the identity the organization or collection sees behind authHeader
+func getAuthenticatedUserId(ctx context.Context, orgUrl, authHeader string) (uuid.UUID, error) {
+	var connectionData struct {
+		AuthenticatedUser struct {
+			Id uuid.UUID `json:"id"`
+		} `json:"authenticatedUser"`
 	}
-}
-
-func getProjectId(conn *azuredevops.Connection, projectname string) string {
-	client, err := core.NewClient(context.Background(), conn)
-	if err != nil {
-		panic(fmt.Errorf("failed to get core client: %v", err))
+	if err := getJSON(ctx, orgUrl+"/_apis/connectionData", authHeader, &connectionData); err != nil {
+		return uuid.Nil, err
 	}
-	project, err := client.GetProject(context.Background(), core.GetProjectArgs{
-		ProjectId: utils.Ptr(projectname),
-	})
-	if err != nil {
-		panic(fmt.Errorf("failed to get project: %v", err))
+	if connectionData.AuthenticatedUser.Id == uuid.Nil {
+		return uuid.Nil, errors.New("authenticated user not found")
 	}
-	projectid := project.Id.String()
-	return projectid
+	return connectionData.AuthenticatedUser.Id, nil
 }
 
-func getRepositoryId(conn *azuredevops.Connection, projectname string, reponame string) uuid.UUID {
-	client, err := git.NewClient(context.Background(), conn)
+func getProjectId(ctx context.Context, conn *azuredevops.Connection, projectname string) (string, error) {
+	client, err := core.NewClient(ctx, conn)
 	if err != nil {
-		panic(fmt.Errorf("failed to get git client: %v", err))
+		return "", fmt.Errorf("failed to get core client: %w", err)
 	}
-	repo, err := client.GetRepository(context.Background(), git.GetRepositoryArgs{
-		Project:      utils.Ptr(projectname),
-		RepositoryId: utils.Ptr(reponame),
+	project, err := client.GetProject(ctx, core.GetProjectArgs{
+		ProjectId: utils.Ptr(projectname),
 	})
 	if err != nil {
-		panic(fmt.Errorf("failed to get repository: %v", err))
+		return "", fmt.Errorf("failed to get project: %w", err)
 	}
-	return *repo.Id
+	return project.Id.String(), nil
 }
@@ -0,0 +1,209 @@
+package azdo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	credentialsFileName = "credentials.enc"
+	credentialsKeyName  = "credentials.key"
+)
+
+// StoredCredential is a PAT saved by 'azdoext auth login'
+type StoredCredential struct {
+	OrgUrl  string    `json:"orgUrl"`
+	PAT     string    `json:"pat"`
+	SavedAt time.Time `json:"savedAt"`
+}
+
+// CredentialStore keeps one PAT per organization in an AES-GCM encrypted file.
+// The key is a file of the same directory readable only by the user, so this is obfuscation: it keeps the PATs
+// out of plain sight, e.g. a stray cat of the file, but anything that copies the directory copies both.
+type CredentialStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+func NewCredentialStore(dir string) *CredentialStore {
+	return &CredentialStore{dir: dir}
+}
+
+// DefaultCredentialStoreDir returns azdoext under the user config directory (e.g. ~/.config/azdoext)
+func DefaultCredentialStoreDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user config directory: %w", err)
+	}
+	return filepath.Join(dir, "azdoext"), nil
+}
+
+// credentialKey normalizes orgUrl so https://dev.azure.com/Org/ and https://dev.azure.com/org share a credential
+func credentialKey(orgUrl string) string {
+	return strings.ToLower(strings.TrimRight(orgUrl, "/"))
+}
+
+func (s *CredentialStore) key(create bool) ([]byte, error) {
+	path := filepath.Join(s.dir, credentialsKeyName)
+	key, err := os.ReadFile(path)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("invalid credentials key %s", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) || !create {
+		return nil, err
+	}
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *CredentialStore) read() (map[string]StoredCredential, error) {
+	credentials := make(map[string]StoredCredential)
+	content, err := os.ReadFile(filepath.Join(s.dir, credentialsFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return credentials, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.key(false)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read credentials key: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) < gcm.NonceSize() {
+		return nil, errors.New("credentials file is corrupted")
+	}
+	nonce, ciphertext := content[:gcm.NonceSize()], content[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt credentials file: %w", err)
+	}
+	if err := json.Unmarshal(plaintext, &credentials); err != nil {
+		return nil, fmt.Errorf("credentials file is corrupted: %w", err)
+	}
+	return credentials, nil
+}
+
+func (s *CredentialStore) write(credentials map[string]StoredCredential) error {
+	plaintext, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	key, err := s.key(true)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	content := gcm.Seal(nonce, nonce, plaintext, nil)
+	path := filepath.Join(s.dir, credentialsFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Get returns the PAT stored for orgUrl, false if there is none
+func (s *CredentialStore) Get(orgUrl string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	credentials, err := s.read()
+	if err != nil {
+		return "", false, err
+	}
+	credential, ok := credentials[credentialKey(orgUrl)]
+	return credential.PAT, ok, nil
+}
+
+// Set stores pat for orgUrl, replacing any previous one
+func (s *CredentialStore) Set(orgUrl, pat string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	credentials, err := s.read()
+	if err != nil {
+		return err
+	}
+	credentials[credentialKey(orgUrl)] = StoredCredential{
+		OrgUrl:  strings.TrimRight(orgUrl, "/"),
+		PAT:     pat,
+		SavedAt: time.Now(),
+	}
+	return s.write(credentials)
+}
+
+// Delete removes the PAT of orgUrl, it returns false if there was none
+func (s *CredentialStore) Delete(orgUrl string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	credentials, err := s.read()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := credentials[credentialKey(orgUrl)]; !ok {
+		return false, nil
+	}
+	delete(credentials, credentialKey(orgUrl))
+	return true, s.write(credentials)
+}
+
+// List returns the stored credentials sorted by organization url
+func (s *CredentialStore) List() ([]StoredCredential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	credentials, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]StoredCredential, 0, len(credentials))
+	for _, credential := range credentials {
+		list = append(list, credential)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].OrgUrl < list[j].OrgUrl })
+	return list, nil
+}
+
+// ValidatePAT reports whether pat can authenticate against orgUrl
+func ValidatePAT(orgUrl, pat string) bool {
+	return validateAuthHeader(orgUrl, newPatAuthProvider(pat))
+}
@@ -0,0 +1,59 @@
+package azdo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCredentialStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "azdoext")
+	store := NewCredentialStore(dir)
+	if err := store.Set("https://dev.azure.com/MyOrg/", "secret-pat"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+
+	pat, ok, err := store.Get("https://dev.azure.com/myorg")
+	if err != nil || !ok || pat != "secret-pat" {
+		t.Errorf("Get() = %q, %v, %v; want %q, true, nil", pat, ok, err, "secret-pat")
+	}
+	if _, ok, _ := store.Get("https://dev.azure.com/other"); ok {
+		t.Errorf("Get() found a PAT for another organization")
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, credentialsFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(content, []byte("secret-pat")) {
+		t.Errorf("credentials file contains the PAT in plain text")
+	}
+
+	list, err := store.List()
+	if err != nil || len(list) != 1 || list[0].OrgUrl != "https://dev.azure.com/MyOrg" {
+		t.Errorf("List() = %+v, %v; want one credential for https://dev.azure.com/MyOrg", list, err)
+	}
+
+	deleted, err := store.Delete("https://dev.azure.com/myorg")
+	if err != nil || !deleted {
+		t.Fatalf("Delete() = %v, %v; want true, nil", deleted, err)
+	}
+	if _, ok, _ := store.Get("https://dev.azure.com/myorg"); ok {
+		t.Errorf("Get() found a PAT after Delete")
+	}
+}
+
+func TestCredentialStoreWrongKey(t *testing.T) {
+	dir := t.TempDir()
+	store := NewCredentialStore(dir)
+	if err := store.Set("https://dev.azure.com/myorg", "secret-pat"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, credentialsKeyName), bytes.Repeat([]byte{1}, 32), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Get("https://dev.azure.com/myorg"); err == nil {
+		t.Errorf("Get() with a different key returned no error")
+	}
+}
@@ -0,0 +1,152 @@
+package azdo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+)
+
+// ConfigError is implemented by every error returned by GetAzdoConfig, Hint tells the user what to do about it
+type ConfigError interface {
+	error
+	Hint() string
+}
+
+type ErrAuthFailed struct {
+	OrgUrl string
+	Err    error
+}
+
+func (e ErrAuthFailed) Error() string {
+	return fmt.Sprintf("authentication to %s failed: %v", e.OrgUrl, e.Err)
+}
+
+func (e ErrAuthFailed) Unwrap() error { return e.Err }
+
+func (e ErrAuthFailed) Hint() string {
+	return "Run 'az login' or 'azdoext auth login', or set AZDO_PERSONAL_ACCESS_TOKEN with a token that has access to this organization"
+}
+
+type ErrOrgNotFound struct {
+	Org string
+	Err error
+}
+
+func (e ErrOrgNotFound) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("organization %q not found: %v", e.Org, e.Err)
+	}
+	return fmt.Sprintf("organization %q not found", e.Org)
+}
+
+func (e ErrOrgNotFound) Unwrap() error { return e.Err }
+
+func (e ErrOrgNotFound) Hint() string {
+	return fmt.Sprintf("Make sure the signed in account is a member of %q and that the origin remote points to the right organization", e.Org)
+}
+
+type ErrProjectNotFound struct {
+	Project string
+	Err     error
+}
+
+func (e ErrProjectNotFound) Error() string {
+	return fmt.Sprintf("project %q not found: %v", e.Project, e.Err)
+}
+
+func (e ErrProjectNotFound) Unwrap() error { return e.Err }
+
+func (e ErrProjectNotFound) Hint() string {
+	return fmt.Sprintf("Check that project %q exists and that you have read access to it", e.Project)
+}
+
+type ErrRepoNotFound struct {
+	Project    string
+	Repository string
+	Err        error
+}
+
+func (e ErrRepoNotFound) Error() string {
+	return fmt.Sprintf("repository %q not found in project %q: %v", e.Repository, e.Project, e.Err)
+}
+
+func (e ErrRepoNotFound) Unwrap() error { return e.Err }
+
+func (e ErrRepoNotFound) Hint() string {
+	return "Check 'git config --get remote.origin.url', the repository may have been renamed or moved"
+}
+
+type ErrNoDefaultBranch struct {
+	Repository string
+}
+
+func (e ErrNoDefaultBranch) Error() string {
+	return fmt.Sprintf("repository %q has no default branch", e.Repository)
+}
+
+func (e ErrNoDefaultBranch) Hint() string {
+	return "Push at least one branch to the repository, the first pushed branch becomes the default branch"
+}
+
+type ErrNetwork struct {
+	Err error
+}
+
+func (e ErrNetwork) Error() string {
+	return fmt.Sprintf("network error: %v", e.Err)
+}
+
+func (e ErrNetwork) Unwrap() error { return e.Err }
+
+func (e ErrNetwork) Hint() string {
+	return "Check your network connection (and VPN, for Azure DevOps Server), then retry"
+}
+
+func (e ErrInvalidRemote) Hint() string {
+	return "Run azdoext inside a git repository whose origin remote is hosted on Azure DevOps"
+}
+
+// statusCode returns the http status code of an azure devops api error, or 0 if there is none
+func statusCode(err error) int {
+	var wrapped azuredevops.WrappedError
+	if errors.As(err, &wrapped) && wrapped.StatusCode != nil {
+		return *wrapped.StatusCode
+	}
+	var wrappedPtr *azuredevops.WrappedError
+	if errors.As(err, &wrappedPtr) && wrappedPtr.StatusCode != nil {
+		return *wrappedPtr.StatusCode
+	}
+	return 0
+}
+
+// classifyError maps err to one of the ConfigError types, using notFound for 404 responses.
+// Anything that is neither an auth nor a not found error is treated as a (retryable) network error.
+func classifyError(err error, orgUrl string, notFound func(error) error) error {
+	var configErr ConfigError
+	if errors.As(err, &configErr) {
+		return err
+	}
+	switch statusCode(err) {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAuthFailed{OrgUrl: orgUrl, Err: err}
+	case http.StatusNotFound:
+		return notFound(err)
+	}
+	return ErrNetwork{Err: err}
+}
+
+// httpStatusError converts a non 2xx response from a raw http call to an error classifyError understands
+func httpStatusError(res *http.Response) error {
+	statusCode := res.StatusCode
+	// unauthenticated requests are answered with a 203 and a sign in page instead of a 401
+	if statusCode == http.StatusNonAuthoritativeInfo {
+		statusCode = http.StatusUnauthorized
+	}
+	if statusCode >= 200 && statusCode < 300 {
+		return nil
+	}
+	message := res.Status
+	return azuredevops.WrappedError{Message: &message, StatusCode: &statusCode}
+}
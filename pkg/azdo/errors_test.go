@@ -0,0 +1,56 @@
+package azdo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+)
+
+func TestClassifyError(t *testing.T) {
+	notFound := func(err error) error { return ErrProjectNotFound{Project: "MyProject", Err: err} }
+	status := func(code int) error {
+		return fmt.Errorf("failed to get project: %w", &azuredevops.WrappedError{StatusCode: &code})
+	}
+	tests := []struct {
+		name string
+		err  error
+		is   func(error) bool
+	}{
+		{name: "unauthorized", err: status(http.StatusUnauthorized), is: func(err error) bool { return errors.As(err, &ErrAuthFailed{}) }},
+		{name: "forbidden", err: status(http.StatusForbidden), is: func(err error) bool { return errors.As(err, &ErrAuthFailed{}) }},
+		{name: "not found", err: status(http.StatusNotFound), is: func(err error) bool { return errors.As(err, &ErrProjectNotFound{}) }},
+		{name: "server error", err: status(http.StatusInternalServerError), is: func(err error) bool { return errors.As(err, &ErrNetwork{}) }},
+		{name: "no status", err: errors.New("connection reset by peer"), is: func(err error) bool { return errors.As(err, &ErrNetwork{}) }},
+		{name: "already classified", err: ErrNoDefaultBranch{Repository: "MyRepo"}, is: func(err error) bool { return errors.As(err, &ErrNoDefaultBranch{}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyError(tt.err, "https://dev.azure.com/MyOrg", notFound)
+			if !tt.is(got) {
+				t.Errorf("classifyError(%v) returned unexpected %T", tt.err, got)
+			}
+		})
+	}
+}
+
+func TestHttpStatusError(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		want       int
+	}{
+		{statusCode: http.StatusOK, want: 0},
+		{statusCode: http.StatusNonAuthoritativeInfo, want: http.StatusUnauthorized},
+		{statusCode: http.StatusNotFound, want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.statusCode), func(t *testing.T) {
+			err := httpStatusError(&http.Response{StatusCode: tt.statusCode, Status: http.StatusText(tt.statusCode)})
+			if got := statusCode(err); got != tt.want {
+				t.Errorf("statusCode(httpStatusError(%d)) = %d; want %d", tt.statusCode, got, tt.want)
+			}
+		})
+	}
+}
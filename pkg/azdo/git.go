@@ -3,27 +3,72 @@ package azdo
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"azdoext/pkg/utils"
+
+	"github.com/google/uuid"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
 )
 
 type GitClientInterface interface {
 	CreatePullRequest(context.Context, git.CreatePullRequestArgs) (git.GitPullRequest, error)
+	GetPullRequests(context.Context, git.GetPullRequestsArgs) ([]git.GitPullRequest, error)
+	// GetAuthenticatedUserId returns the id of the identity behind the credentials, e.g. to list the PRs it created
+	GetAuthenticatedUserId(context.Context) (uuid.UUID, error)
+	// GetBranches returns the names of the branches of the repository on Azure DevOps, without refs/heads/
+	GetBranches(ctx context.Context, project, repositoryId string) ([]string, error)
+	// CompletePullRequest merges the pull request right away, it fails while its blocking policies aren't satisfied
+	// or when lastMergeSourceCommit is no longer the last commit of the source branch
+	CompletePullRequest(ctx context.Context, project, repositoryId string, pullRequestId int, lastMergeSourceCommit string, completion PullRequestCompletion) (git.GitPullRequest, error)
+	// SetAutoComplete makes the pull request complete on behalf of the authenticated user once its blocking policies are satisfied
+	SetAutoComplete(ctx context.Context, project, repositoryId string, pullRequestId int, completion PullRequestCompletion) (git.GitPullRequest, error)
+	AbandonPullRequest(ctx context.Context, project, repositoryId string, pullRequestId int) (git.GitPullRequest, error)
+}
+
+// PullRequestCompletion is how a pull request is merged, whether it's completed right away or by auto-complete
+type PullRequestCompletion struct {
+	MergeStrategy       git.GitPullRequestMergeStrategy
+	DeleteSourceBranch  bool
+	TransitionWorkItems bool
+	// MergeCommitMessage replaces the message Azure DevOps generates, unless empty
+	MergeCommitMessage string
+}
+
+func (c PullRequestCompletion) completionOptions() *git.GitPullRequestCompletionOptions {
+	options := &git.GitPullRequestCompletionOptions{
+		MergeStrategy:       utils.Ptr(c.MergeStrategy),
+		DeleteSourceBranch:  utils.Ptr(c.DeleteSourceBranch),
+		TransitionWorkItems: utils.Ptr(c.TransitionWorkItems),
+	}
+	if c.MergeCommitMessage != "" {
+		options.MergeCommitMessage = utils.Ptr(c.MergeCommitMessage)
+	}
+	return options
 }
 
 type GitClient struct {
 	git.Client
+	orgurl       string
+	authProvider AuthProvider
 }
 
-func NewGitClient(ctx context.Context, orgurl, projectid, authHeader string) GitClientInterface {
-	azdoconn := NewConnection(orgurl, authHeader)
+func NewGitClient(ctx context.Context, orgurl, projectid string, authProvider AuthProvider) (GitClientInterface, error) {
+	azdoconn, err := newAuthConnection(ctx, orgurl, authProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create git client: %w", err)
+	}
 	client, err := git.NewClient(ctx, azdoconn)
 	if err != nil {
-		panic(fmt.Sprintf("failed to create git client: %v", err))
+		return nil, fmt.Errorf("failed to create git client: %w", err)
 	}
+	withAuthProvider(&client.(*git.ClientImpl).Client, authProvider)
 	return &GitClient{
-		Client: client,
-	}
+		Client:       client,
+		orgurl:       orgurl,
+		authProvider: authProvider,
+	}, nil
 }
 
 func (g *GitClient) CreatePullRequest(ctx context.Context, args git.CreatePullRequestArgs) (git.GitPullRequest, error) {
@@ -33,3 +78,86 @@ func (g *GitClient) CreatePullRequest(ctx context.Context, args git.CreatePullRe
 	}
 	return *pr, nil
 }
+
+func (g *GitClient) GetPullRequests(ctx context.Context, args git.GetPullRequestsArgs) ([]git.GitPullRequest, error) {
+	prs, err := g.Client.GetPullRequests(ctx, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull requests: %w", err)
+	}
+	if prs == nil {
+		return []git.GitPullRequest{}, nil
+	}
+	return *prs, nil
+}
+
+func (g *GitClient) GetAuthenticatedUserId(ctx context.Context) (uuid.UUID, error) {
+	authHeader, err := g.authProvider(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return getAuthenticatedUserId(ctx, g.orgurl, authHeader)
+}
+
+func (g *GitClient) GetBranches(ctx context.Context, project, repositoryId string) ([]string, error) {
+	args := git.GetRefsArgs{
+		RepositoryId: &repositoryId,
+		Project:      &project,
+		Filter:       utils.Ptr("heads/"),
+	}
+	var branches []string
+	for {
+		refs, err := g.Client.GetRefs(ctx, args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get branches: %w", err)
+		}
+		for _, ref := range refs.Value {
+			if ref.Name != nil {
+				branches = append(branches, strings.TrimPrefix(*ref.Name, "refs/heads/"))
+			}
+		}
+		if refs.ContinuationToken == "" {
+			return branches, nil
+		}
+		args.ContinuationToken = &refs.ContinuationToken
+	}
+}
+
+// CompletePullRequest needs the last commit merged from the source branch the user saw, so a push made since then
+// is rejected by Azure DevOps instead of being completed by mistake
+func (g *GitClient) CompletePullRequest(ctx context.Context, project, repositoryId string, pullRequestId int, lastMergeSourceCommit string, completion PullRequestCompletion) (git.GitPullRequest, error) {
+	return g.updatePullRequest(ctx, project, repositoryId, pullRequestId, git.GitPullRequest{
+		Status:                &git.PullRequestStatusValues.Completed,
+		LastMergeSourceCommit: &git.GitCommitRef{CommitId: &lastMergeSourceCommit},
+		CompletionOptions:     completion.completionOptions(),
+	})
+}
+
+func (g *GitClient) SetAutoComplete(ctx context.Context, project, repositoryId string, pullRequestId int, completion PullRequestCompletion) (git.GitPullRequest, error) {
+	userId, err := g.GetAuthenticatedUserId(ctx)
+	if err != nil {
+		return git.GitPullRequest{}, err
+	}
+	return g.updatePullRequest(ctx, project, repositoryId, pullRequestId, git.GitPullRequest{
+		AutoCompleteSetBy: &webapi.IdentityRef{Id: utils.Ptr(userId.String())},
+		CompletionOptions: completion.completionOptions(),
+	})
+}
+
+func (g *GitClient) AbandonPullRequest(ctx context.Context, project, repositoryId string, pullRequestId int) (git.GitPullRequest, error) {
+	return g.updatePullRequest(ctx, project, repositoryId, pullRequestId, git.GitPullRequest{
+		Status: &git.PullRequestStatusValues.Abandoned,
+	})
+}
+
+func (g *GitClient) updatePullRequest(ctx context.Context, project, repositoryId string, pullRequestId int, update git.GitPullRequest) (git.GitPullRequest, error) {
+	pr, err := g.Client.UpdatePullRequest(ctx, git.UpdatePullRequestArgs{
+		Project:                &project,
+		RepositoryId:           &repositoryId,
+		PullRequestId:          &pullRequestId,
+		GitPullRequestToUpdate: &update,
+	})
+	if err != nil {
+		return git.GitPullRequest{}, fmt.Errorf("failed to update pull request %d: %w", pullRequestId, err)
+	}
+	return *pr, nil
+}
@@ -0,0 +1,33 @@
+package azdo
+
+import (
+	"reflect"
+	"testing"
+
+	"azdoext/pkg/utils"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+func TestCompletionOptions(t *testing.T) {
+	completion := PullRequestCompletion{
+		MergeStrategy:       git.GitPullRequestMergeStrategyValues.Squash,
+		DeleteSourceBranch:  true,
+		TransitionWorkItems: false,
+		MergeCommitMessage:  "Merged PR 7: Add login",
+	}
+	want := &git.GitPullRequestCompletionOptions{
+		MergeStrategy:       &git.GitPullRequestMergeStrategyValues.Squash,
+		DeleteSourceBranch:  utils.Ptr(true),
+		TransitionWorkItems: utils.Ptr(false),
+		MergeCommitMessage:  utils.Ptr("Merged PR 7: Add login"),
+	}
+	if got := completion.completionOptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("completionOptions() = %+v, want %+v", got, want)
+	}
+
+	completion.MergeCommitMessage = ""
+	if got := completion.completionOptions(); got.MergeCommitMessage != nil {
+		t.Errorf("expected Azure DevOps to generate the merge commit message, got %q", *got.MergeCommitMessage)
+	}
+}
@@ -0,0 +1,404 @@
+package azdo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"azdoext/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/pipelines"
+	"gopkg.in/yaml.v3"
+)
+
+// PipelineParameter is a runtime parameter declared at the top of a YAML pipeline
+type PipelineParameter struct {
+	Name        string
+	DisplayName string
+	// Type is string, number or boolean, other parameter types can't be set when queueing from here
+	Type    string
+	Default string
+	// Values restricts the parameter to one of these, if set
+	Values []string
+}
+
+// PipelineVariable is a definition variable that can be set at queue time
+type PipelineVariable struct {
+	Name     string
+	Value    string
+	IsSecret bool
+}
+
+// RunOptions is what can be customized when queueing a pipeline
+type RunOptions struct {
+	// Branch is the branch the options were read from
+	Branch     string
+	Parameters []PipelineParameter
+	Variables  []PipelineVariable
+	Stages     []string
+}
+
+type RunPipelineArgs struct {
+	PipelineId int
+	// Branch is the branch to run on, with or without refs/heads/
+	Branch string
+	// Commit, if set, runs that commit of Branch instead of its head
+	Commit             string
+	TemplateParameters map[string]string
+	Variables          map[string]string
+	StagesToSkip       []string
+}
+
+// CreatePipelineArgs describes a YAML pipeline to create from a file of a repository
+type CreatePipelineArgs struct {
+	Name string
+	// Folder is where the pipeline is listed, \ for the root
+	Folder string
+	// YamlPath is the path of the YAML file from the root of the repository
+	YamlPath     string
+	RepositoryId uuid.UUID
+}
+
+type PipelinesClientInterface interface {
+	GetRunOptions(ctx context.Context, pipelineId int, branch string) (RunOptions, error)
+	RunPipeline(ctx context.Context, args RunPipelineArgs) (int, error)
+	CreatePipeline(ctx context.Context, args CreatePipelineArgs) (int, error)
+}
+
+type PipelinesClient struct {
+	pipelines pipelines.Client
+	build     build.Client
+	git       git.Client
+	projectid string
+}
+
+func NewPipelinesClient(ctx context.Context, orgurl, projectid string, authProvider AuthProvider) (PipelinesClientInterface, error) {
+	azdoconn, err := newAuthConnection(ctx, orgurl, authProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pipelines client: %w", err)
+	}
+	pipelinesClient := pipelines.NewClient(ctx, azdoconn)
+	withAuthProvider(&pipelinesClient.(*pipelines.ClientImpl).Client, authProvider)
+	buildClient, err := build.NewClient(ctx, azdoconn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pipelines client: %w", err)
+	}
+	withAuthProvider(&buildClient.(*build.ClientImpl).Client, authProvider)
+	gitClient, err := git.NewClient(ctx, azdoconn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pipelines client: %w", err)
+	}
+	withAuthProvider(&gitClient.(*git.ClientImpl).Client, authProvider)
+	return &PipelinesClient{
+		pipelines: pipelinesClient,
+		build:     buildClient,
+		git:       gitClient,
+		projectid: projectid,
+	}, nil
+}
+
+// GetRunOptions reads the settable variables of the definition, the parameters declared in its YAML file on branch
+// and the stages of the expanded pipeline. An empty branch means the default branch of the pipeline
+func (p *PipelinesClient) GetRunOptions(ctx context.Context, pipelineId int, branch string) (RunOptions, error) {
+	definition, err := p.build.GetDefinition(ctx, build.GetDefinitionArgs{
+		Project:      &p.projectid,
+		DefinitionId: &pipelineId,
+	})
+	if err != nil {
+		return RunOptions{}, fmt.Errorf("failed to get pipeline definition: %w", err)
+	}
+	if branch == "" && definition.Repository != nil && definition.Repository.DefaultBranch != nil {
+		branch = *definition.Repository.DefaultBranch
+	}
+	options := RunOptions{Branch: strings.TrimPrefix(branch, "refs/heads/"), Variables: settableVariables(definition.Variables)}
+
+	yamlFilename := yamlFilename(definition.Process)
+	if yamlFilename == "" || definition.Repository == nil || definition.Repository.Id == nil {
+		// classic pipelines have no parameters nor stages
+		return options, nil
+	}
+	content, err := p.git.GetItemContent(ctx, git.GetItemContentArgs{
+		RepositoryId: definition.Repository.Id,
+		Project:      &p.projectid,
+		Path:         &yamlFilename,
+		VersionDescriptor: &git.GitVersionDescriptor{
+			Version:     utils.Ptr(strings.TrimPrefix(branch, "refs/heads/")),
+			VersionType: &git.GitVersionTypeValues.Branch,
+		},
+	})
+	if err != nil {
+		return RunOptions{}, fmt.Errorf("failed to read %s on %s: %w", yamlFilename, branch, err)
+	}
+	defer content.Close()
+	raw, err := io.ReadAll(content)
+	if err != nil {
+		return RunOptions{}, fmt.Errorf("failed to read %s on %s: %w", yamlFilename, branch, err)
+	}
+	options.Parameters, err = ParsePipelineParameters(raw)
+	if err != nil {
+		return RunOptions{}, fmt.Errorf("invalid pipeline yaml %s: %w", yamlFilename, err)
+	}
+
+	// stages can come from templates, so they are read from the expanded yaml when the service can expand it
+	preview, err := p.pipelines.Preview(ctx, pipelines.PreviewArgs{
+		Project:    &p.projectid,
+		PipelineId: &pipelineId,
+		RunParameters: &pipelines.RunPipelineParameters{
+			PreviewRun: utils.Ptr(true),
+			Resources:  selfRepositoryResources(branch, ""),
+		},
+	})
+	stagesYaml := raw
+	if err == nil && preview.FinalYaml != nil {
+		stagesYaml = []byte(*preview.FinalYaml)
+	}
+	options.Stages, err = ParsePipelineStages(stagesYaml)
+	if err != nil {
+		return RunOptions{}, fmt.Errorf("invalid pipeline yaml %s: %w", yamlFilename, err)
+	}
+	return options, nil
+}
+
+// RunPipeline queues the pipeline, validation errors of the service (unknown parameter, missing stage...) are returned as is
+func (p *PipelinesClient) RunPipeline(ctx context.Context, args RunPipelineArgs) (int, error) {
+	runParameters := &pipelines.RunPipelineParameters{
+		Resources: selfRepositoryResources(args.Branch, args.Commit),
+	}
+	if len(args.TemplateParameters) > 0 {
+		runParameters.TemplateParameters = &args.TemplateParameters
+	}
+	if len(args.Variables) > 0 {
+		variables := make(map[string]pipelines.Variable, len(args.Variables))
+		for name, value := range args.Variables {
+			variables[name] = pipelines.Variable{Value: &value}
+		}
+		runParameters.Variables = &variables
+	}
+	if len(args.StagesToSkip) > 0 {
+		runParameters.StagesToSkip = &args.StagesToSkip
+	}
+	run, err := p.pipelines.RunPipeline(ctx, pipelines.RunPipelineArgs{
+		Project:       &p.projectid,
+		PipelineId:    &args.PipelineId,
+		RunParameters: runParameters,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to queue pipeline: %s", serviceMessage(err))
+	}
+	return *run.Id, nil
+}
+
+// createPipelineParameters is what the service expects to create a YAML pipeline, pipelines.CreatePipelineParameters
+// has no field for the YAML file nor its repository
+type createPipelineParameters struct {
+	Name          string                    `json:"name"`
+	Folder        string                    `json:"folder,omitempty"`
+	Configuration yamlPipelineConfiguration `json:"configuration"`
+}
+
+type yamlPipelineConfiguration struct {
+	Type       pipelines.ConfigurationType `json:"type"`
+	Path       string                      `json:"path"`
+	Repository yamlPipelineRepository      `json:"repository"`
+}
+
+type yamlPipelineRepository struct {
+	Id   uuid.UUID `json:"id"`
+	Type string    `json:"type"`
+}
+
+func newCreatePipelineParameters(args CreatePipelineArgs) createPipelineParameters {
+	return createPipelineParameters{
+		Name:   args.Name,
+		Folder: args.Folder,
+		Configuration: yamlPipelineConfiguration{
+			Type: pipelines.ConfigurationTypeValues.Yaml,
+			Path: args.YamlPath,
+			Repository: yamlPipelineRepository{
+				Id:   args.RepositoryId,
+				Type: "azureReposGit",
+			},
+		},
+	}
+}
+
+// CreatePipeline creates a YAML pipeline bound to a repository and returns its id
+func (p *PipelinesClient) CreatePipeline(ctx context.Context, args CreatePipelineArgs) (int, error) {
+	body, err := json.Marshal(newCreatePipelineParameters(args))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create pipeline: %w", err)
+	}
+	// same request as pipelines.Client.CreatePipeline, which can't send the configuration above
+	client := &p.pipelines.(*pipelines.ClientImpl).Client
+	locationId := uuid.MustParse("28e1305e-2afe-47bf-abaf-cbb0e6a91988")
+	routeValues := map[string]string{"project": p.projectid}
+	resp, err := client.Send(ctx, http.MethodPost, locationId, "7.1-preview.1", routeValues, nil, bytes.NewReader(body), "application/json", "application/json", nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create pipeline: %s", serviceMessage(err))
+	}
+	var pipeline pipelines.Pipeline
+	if err := client.UnmarshalBody(resp, &pipeline); err != nil {
+		return 0, fmt.Errorf("failed to read created pipeline: %w", err)
+	}
+	return *pipeline.Id, nil
+}
+
+// serviceMessage returns the message of an azure devops error, which explains what failed validation
+func serviceMessage(err error) string {
+	var wrapped *azuredevops.WrappedError
+	if errors.As(err, &wrapped) && wrapped.Message != nil {
+		return *wrapped.Message
+	}
+	var wrappedValue azuredevops.WrappedError
+	if errors.As(err, &wrappedValue) && wrappedValue.Message != nil {
+		return *wrappedValue.Message
+	}
+	return err.Error()
+}
+
+func selfRepositoryResources(branch, commit string) *pipelines.RunResourcesParameters {
+	self := pipelines.RepositoryResourceParameters{}
+	if branch != "" {
+		self.RefName = utils.Ptr(utils.FormatBranchName(branch))
+	}
+	if commit != "" {
+		self.Version = &commit
+	}
+	return &pipelines.RunResourcesParameters{
+		Repositories: &map[string]pipelines.RepositoryResourceParameters{"self": self},
+	}
+}
+
+func settableVariables(variables *map[string]build.BuildDefinitionVariable) []PipelineVariable {
+	if variables == nil {
+		return nil
+	}
+	var settable []PipelineVariable
+	for name, variable := range *variables {
+		if variable.AllowOverride == nil || !*variable.AllowOverride {
+			continue
+		}
+		v := PipelineVariable{Name: name}
+		if variable.Value != nil {
+			v.Value = *variable.Value
+		}
+		if variable.IsSecret != nil {
+			v.IsSecret = *variable.IsSecret
+		}
+		settable = append(settable, v)
+	}
+	sort.Slice(settable, func(i, j int) bool { return settable[i].Name < settable[j].Name })
+	return settable
+}
+
+// yamlFilename returns the yaml file of a YAML definition process, empty for classic definitions
+func yamlFilename(process interface{}) string {
+	p, ok := process.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	filename, _ := p["yamlFilename"].(string)
+	return filename
+}
+
+type yamlParameter struct {
+	Name        string    `yaml:"name"`
+	DisplayName string    `yaml:"displayName"`
+	Type        string    `yaml:"type"`
+	Default     yaml.Node `yaml:"default"`
+	Values      []string  `yaml:"values"`
+}
+
+// IsPipelineYaml tells whether content looks like a YAML pipeline, which declares stages, jobs or steps or extends a template.
+// Templates declaring stages, jobs or steps can't be told apart from pipelines
+func IsPipelineYaml(content []byte) bool {
+	var doc map[string]yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return false
+	}
+	for _, key := range []string{"stages", "jobs", "steps", "extends"} {
+		if _, ok := doc[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePipelineParameters returns the scalar runtime parameters of a pipeline yaml
+func ParsePipelineParameters(content []byte) ([]PipelineParameter, error) {
+	var doc struct {
+		Parameters yaml.Node `yaml:"parameters"`
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	var parameters []PipelineParameter
+	switch doc.Parameters.Kind {
+	case yaml.SequenceNode:
+		var declared []yamlParameter
+		if err := doc.Parameters.Decode(&declared); err != nil {
+			return nil, err
+		}
+		for _, p := range declared {
+			if p.Type == "" {
+				p.Type = "string"
+			}
+			if p.Type != "string" && p.Type != "number" && p.Type != "boolean" {
+				continue
+			}
+			parameter := PipelineParameter{
+				Name:        p.Name,
+				DisplayName: p.DisplayName,
+				Type:        p.Type,
+				Values:      p.Values,
+			}
+			if p.Default.Kind == yaml.ScalarNode {
+				parameter.Default = p.Default.Value
+			}
+			if parameter.Type == "boolean" && len(parameter.Values) == 0 {
+				parameter.Values = []string{"true", "false"}
+			}
+			parameters = append(parameters, parameter)
+		}
+	case yaml.MappingNode:
+		// legacy syntax, parameters: {name: default}
+		for i := 0; i+1 < len(doc.Parameters.Content); i += 2 {
+			key, value := doc.Parameters.Content[i], doc.Parameters.Content[i+1]
+			if value.Kind != yaml.ScalarNode {
+				continue
+			}
+			parameters = append(parameters, PipelineParameter{Name: key.Value, Type: "string", Default: value.Value})
+		}
+	}
+	return parameters, nil
+}
+
+// ParsePipelineStages returns the stage names of a pipeline yaml, none for single stage pipelines
+func ParsePipelineStages(content []byte) ([]string, error) {
+	var doc struct {
+		Stages []struct {
+			Stage string `yaml:"stage"`
+		} `yaml:"stages"`
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	var stages []string
+	for _, s := range doc.Stages {
+		// template references have no stage name
+		if s.Stage != "" {
+			stages = append(stages, s.Stage)
+		}
+	}
+	return stages, nil
+}
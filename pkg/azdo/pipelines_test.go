@@ -0,0 +1,126 @@
+package azdo
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"azdoext/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/build"
+)
+
+func TestParsePipelineParameters(t *testing.T) {
+	content := []byte(`
+parameters:
+- name: environment
+  displayName: Environment
+  type: string
+  default: dev
+  values: [dev, prod]
+- name: dryRun
+  type: boolean
+  default: true
+- name: replicas
+  type: number
+- name: noType
+  default: x
+- name: steps
+  type: stepList
+  default: []
+trigger: none
+`)
+	got, err := ParsePipelineParameters(content)
+	if err != nil {
+		t.Fatalf("ParsePipelineParameters returned error: %v", err)
+	}
+	want := []PipelineParameter{
+		{Name: "environment", DisplayName: "Environment", Type: "string", Default: "dev", Values: []string{"dev", "prod"}},
+		{Name: "dryRun", Type: "boolean", Default: "true", Values: []string{"true", "false"}},
+		{Name: "replicas", Type: "number"},
+		{Name: "noType", Type: "string", Default: "x"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePipelineParameters() = %+v; want %+v", got, want)
+	}
+}
+
+func TestParsePipelineParametersLegacy(t *testing.T) {
+	got, err := ParsePipelineParameters([]byte("parameters:\n  image: ubuntu-latest\n"))
+	if err != nil {
+		t.Fatalf("ParsePipelineParameters returned error: %v", err)
+	}
+	want := []PipelineParameter{{Name: "image", Type: "string", Default: "ubuntu-latest"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePipelineParameters() = %+v; want %+v", got, want)
+	}
+}
+
+func TestParsePipelineStages(t *testing.T) {
+	content := []byte(`
+stages:
+- stage: Build
+  jobs: []
+- template: deploy.yml
+- stage: Deploy
+`)
+	got, err := ParsePipelineStages(content)
+	if err != nil {
+		t.Fatalf("ParsePipelineStages returned error: %v", err)
+	}
+	if want := []string{"Build", "Deploy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePipelineStages() = %v; want %v", got, want)
+	}
+	if got, _ := ParsePipelineStages([]byte("steps:\n- script: echo\n")); len(got) != 0 {
+		t.Errorf("ParsePipelineStages() of a single stage pipeline = %v; want none", got)
+	}
+}
+
+func TestSettableVariables(t *testing.T) {
+	variables := map[string]build.BuildDefinitionVariable{
+		"fixed":    {Value: utils.Ptr("1")},
+		"settable": {Value: utils.Ptr("2"), AllowOverride: utils.Ptr(true)},
+		"secret":   {AllowOverride: utils.Ptr(true), IsSecret: utils.Ptr(true)},
+	}
+	got := settableVariables(&variables)
+	want := []PipelineVariable{
+		{Name: "secret", IsSecret: true},
+		{Name: "settable", Value: "2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("settableVariables() = %+v; want %+v", got, want)
+	}
+}
+
+func TestCreatePipelineParameters(t *testing.T) {
+	repositoryId := uuid.MustParse("6c1f3e8a-2b4d-4f5e-9a7b-0c1d2e3f4a5b")
+	body, err := json.Marshal(newCreatePipelineParameters(CreatePipelineArgs{
+		Name:         "azdoext",
+		Folder:       `\tools`,
+		YamlPath:     "azure-pipelines.yml",
+		RepositoryId: repositoryId,
+	}))
+	if err != nil {
+		t.Fatalf("failed to marshal parameters: %v", err)
+	}
+	want := `{"name":"azdoext","folder":"\\tools","configuration":{"type":"yaml","path":"azure-pipelines.yml",` +
+		`"repository":{"id":"6c1f3e8a-2b4d-4f5e-9a7b-0c1d2e3f4a5b","type":"azureReposGit"}}}`
+	if string(body) != want {
+		t.Errorf("unexpected body\n got: %s\nwant: %s", body, want)
+	}
+}
+
+func TestIsPipelineYaml(t *testing.T) {
+	for content, want := range map[string]bool{
+		"trigger: [main]\npool:\n  vmImage: ubuntu-latest\nsteps:\n- script: go test ./...\n": true,
+		"stages:\n- stage: build\n":                       true,
+		"extends:\n  template: templates/go.yml\n":        true,
+		"version: 2\nupdates:\n- package-ecosystem: go\n": false,
+		"not: [valid": false,
+	} {
+		if got := IsPipelineYaml([]byte(content)); got != want {
+			t.Errorf("IsPipelineYaml(%q) = %v, want %v", content, got, want)
+		}
+	}
+}
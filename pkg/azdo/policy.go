@@ -0,0 +1,117 @@
+package azdo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/policy"
+)
+
+// PolicyEvaluation is a branch policy evaluated on a pull request, e.g. a build validation or the minimum number of reviewers
+type PolicyEvaluation struct {
+	Name       string
+	Status     string
+	IsBlocking bool
+	// BuildId is the run a build validation queued, zero for other policies or before the run is queued
+	BuildId int
+}
+
+type PolicyClientInterface interface {
+	GetPolicyEvaluations(ctx context.Context, pullRequestId int) ([]PolicyEvaluation, error)
+}
+
+type PolicyClient struct {
+	client    policy.Client
+	projectid string
+}
+
+func NewPolicyClient(ctx context.Context, orgurl, projectid string, authProvider AuthProvider) (PolicyClientInterface, error) {
+	azdoconn, err := newAuthConnection(ctx, orgurl, authProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create policy client: %w", err)
+	}
+	client, err := policy.NewClient(ctx, azdoconn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create policy client: %w", err)
+	}
+	withAuthProvider(&client.(*policy.ClientImpl).Client, authProvider)
+	return &PolicyClient{
+		client:    client,
+		projectid: projectid,
+	}, nil
+}
+
+// GetPolicyEvaluations returns the policies that apply to the pull request
+func (p *PolicyClient) GetPolicyEvaluations(ctx context.Context, pullRequestId int) ([]PolicyEvaluation, error) {
+	artifactId := fmt.Sprintf("vstfs:///CodeReview/CodeReviewId/%s/%d", p.projectid, pullRequestId)
+	records, err := p.client.GetPolicyEvaluations(ctx, policy.GetPolicyEvaluationsArgs{
+		Project:    &p.projectid,
+		ArtifactId: &artifactId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy evaluations: %w", err)
+	}
+	evaluations := []PolicyEvaluation{}
+	if records == nil {
+		return evaluations, nil
+	}
+	for _, record := range *records {
+		evaluations = append(evaluations, convertPolicyEvaluation(record))
+	}
+	return evaluations, nil
+}
+
+// convertPolicyEvaluation names the evaluation after its policy type, or after the name given in its settings,
+// e.g. the display name of a build validation, whose context holds the run it queued
+func convertPolicyEvaluation(record policy.PolicyEvaluationRecord) PolicyEvaluation {
+	var evaluation PolicyEvaluation
+	if record.Status != nil {
+		evaluation.Status = string(*record.Status)
+	}
+	if evaluationContext, ok := record.Context.(map[string]interface{}); ok {
+		if buildId, ok := evaluationContext["buildId"].(float64); ok {
+			evaluation.BuildId = int(buildId)
+		}
+	}
+	configuration := record.Configuration
+	if configuration == nil {
+		return evaluation
+	}
+	if configuration.IsBlocking != nil {
+		evaluation.IsBlocking = *configuration.IsBlocking
+	}
+	if configuration.Type != nil && configuration.Type.DisplayName != nil {
+		evaluation.Name = *configuration.Type.DisplayName
+	}
+	if settings, ok := configuration.Settings.(map[string]interface{}); ok {
+		if displayName, ok := settings["displayName"].(string); ok && displayName != "" {
+			evaluation.Name = displayName
+		}
+	}
+	return evaluation
+}
+
+// PolicyState sums up the evaluations of a pull request: rejected if a blocking policy failed, running while any
+// is queued or running, approved once every blocking policy approved and empty if no policy applies
+func PolicyState(evaluations []PolicyEvaluation) string {
+	approved, running := false, false
+	for _, evaluation := range evaluations {
+		switch policy.PolicyEvaluationStatus(evaluation.Status) {
+		case policy.PolicyEvaluationStatusValues.Rejected, policy.PolicyEvaluationStatusValues.Broken:
+			if evaluation.IsBlocking {
+				return string(policy.PolicyEvaluationStatusValues.Rejected)
+			}
+		case policy.PolicyEvaluationStatusValues.Queued, policy.PolicyEvaluationStatusValues.Running:
+			running = running || evaluation.IsBlocking
+		case policy.PolicyEvaluationStatusValues.Approved:
+			approved = true
+		}
+	}
+	switch {
+	case running:
+		return string(policy.PolicyEvaluationStatusValues.Running)
+	case approved:
+		return string(policy.PolicyEvaluationStatusValues.Approved)
+	}
+	return ""
+}
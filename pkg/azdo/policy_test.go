@@ -0,0 +1,55 @@
+package azdo
+
+import (
+	"reflect"
+	"testing"
+
+	"azdoext/pkg/utils"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/policy"
+)
+
+func TestConvertPolicyEvaluation(t *testing.T) {
+	record := policy.PolicyEvaluationRecord{
+		Status:  &policy.PolicyEvaluationStatusValues.Running,
+		Context: map[string]interface{}{"buildId": float64(345), "isExpired": false},
+		Configuration: &policy.PolicyConfiguration{
+			IsBlocking: utils.Ptr(true),
+			Type:       &policy.PolicyTypeRef{DisplayName: utils.Ptr("Build")},
+			Settings:   map[string]interface{}{"displayName": "ci", "buildDefinitionId": float64(12)},
+		},
+	}
+	want := PolicyEvaluation{Name: "ci", Status: "running", IsBlocking: true, BuildId: 345}
+	if got := convertPolicyEvaluation(record); !reflect.DeepEqual(got, want) {
+		t.Errorf("convertPolicyEvaluation() = %+v, want %+v", got, want)
+	}
+
+	record.Context = nil
+	record.Configuration.Settings = map[string]interface{}{"minimumApproverCount": float64(2)}
+	if got := convertPolicyEvaluation(record); got.Name != "Build" || got.BuildId != 0 {
+		t.Errorf("expected the policy type name without a display name and no build, got %+v", got)
+	}
+}
+
+func TestPolicyState(t *testing.T) {
+	evaluation := func(status string, blocking bool) PolicyEvaluation {
+		return PolicyEvaluation{Status: status, IsBlocking: blocking}
+	}
+	for name, tc := range map[string]struct {
+		evaluations []PolicyEvaluation
+		want        string
+	}{
+		"no policy":               {nil, ""},
+		"all approved":            {[]PolicyEvaluation{evaluation("approved", true), evaluation("approved", false)}, "approved"},
+		"blocking running":        {[]PolicyEvaluation{evaluation("approved", true), evaluation("queued", true)}, "running"},
+		"optional running":        {[]PolicyEvaluation{evaluation("approved", true), evaluation("running", false)}, "approved"},
+		"blocking rejected":       {[]PolicyEvaluation{evaluation("running", true), evaluation("rejected", true)}, "rejected"},
+		"broken is rejected":      {[]PolicyEvaluation{evaluation("broken", true)}, "rejected"},
+		"optional rejected":       {[]PolicyEvaluation{evaluation("approved", true), evaluation("rejected", false)}, "approved"},
+		"not applicable is empty": {[]PolicyEvaluation{evaluation("notApplicable", true)}, ""},
+	} {
+		if got := PolicyState(tc.evaluations); got != tc.want {
+			t.Errorf("%s: PolicyState() = %q, want %q", name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package azdo
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Remote is a parsed Azure DevOps git remote.
+// Collection holds the organization name on Azure DevOps Services and the collection name on Azure DevOps Server.
+type Remote struct {
+	Scheme         string
+	Host           string
+	CollectionPath string
+	Collection     string
+	Project        string
+	Repository     string
+}
+
+type ErrInvalidRemote struct {
+	RemoteUrl string
+	Reason    string
+}
+
+func (e ErrInvalidRemote) Error() string {
+	return fmt.Sprintf("not a valid Azure DevOps remote %q: %s", e.RemoteUrl, e.Reason)
+}
+
+const (
+	cloudHost        = "dev.azure.com"
+	cloudSSHHost     = "ssh.dev.azure.com"
+	legacyHostSuffix = ".visualstudio.com"
+	legacySSHHost    = "vs-ssh.visualstudio.com"
+)
+
+// ParseRemoteUrl parses the following remote formats:
+//   - https://dev.azure.com/org/project/_git/repo (and the short https://dev.azure.com/org/_git/repo)
+//   - git@ssh.dev.azure.com:v3/org/project/repo
+//   - https://org.visualstudio.com[/DefaultCollection]/project/_git/repo
+//   - org@vs-ssh.visualstudio.com:v3/org/project/repo
+//   - https://server[/virtualdir]/collection/project/_git/repo (Azure DevOps Server)
+//   - ssh://server:22[/virtualdir]/collection/project/_git/repo (Azure DevOps Server)
+func ParseRemoteUrl(remoteUrl string) (Remote, error) {
+	remoteUrl = strings.TrimSpace(remoteUrl)
+	if remoteUrl == "" {
+		return Remote{}, ErrInvalidRemote{RemoteUrl: remoteUrl, Reason: "remote url is empty"}
+	}
+	if !strings.Contains(remoteUrl, "://") {
+		// scp-like syntax, e.g. git@ssh.dev.azure.com:v3/org/project/repo
+		userAndHost, path, ok := strings.Cut(remoteUrl, ":")
+		if !ok {
+			return Remote{}, ErrInvalidRemote{RemoteUrl: remoteUrl, Reason: "unknown remote format"}
+		}
+		_, host, _ := strings.Cut(userAndHost, "@")
+		if host == "" {
+			host = userAndHost
+		}
+		return parseSSHPath(remoteUrl, host, path)
+	}
+
+	u, err := url.Parse(remoteUrl)
+	if err != nil {
+		return Remote{}, ErrInvalidRemote{RemoteUrl: remoteUrl, Reason: err.Error()}
+	}
+	switch u.Scheme {
+	case "ssh":
+		if strings.HasPrefix(strings.TrimPrefix(u.Path, "/"), "v3/") {
+			return parseSSHPath(remoteUrl, u.Hostname(), strings.TrimPrefix(u.Path, "/"))
+		}
+		// Azure DevOps Server ssh remotes share the https layout, but the web endpoints are served over https
+		return parseGitPath(remoteUrl, "https", u.Hostname(), u.EscapedPath())
+	case "http", "https":
+		return parseGitPath(remoteUrl, u.Scheme, u.Host, u.EscapedPath())
+	default:
+		return Remote{}, ErrInvalidRemote{RemoteUrl: remoteUrl, Reason: fmt.Sprintf("unsupported scheme %q", u.Scheme)}
+	}
+}
+
+// parseSSHPath parses the v3/org/project/repo path used by Azure DevOps Services ssh remotes
+func parseSSHPath(remoteUrl, host, path string) (Remote, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "v3" {
+		return Remote{}, ErrInvalidRemote{RemoteUrl: remoteUrl, Reason: "expected ssh path in the form v3/org/project/repo"}
+	}
+	unescaped, err := unescapeAll(parts[1:])
+	if err != nil {
+		return Remote{}, ErrInvalidRemote{RemoteUrl: remoteUrl, Reason: err.Error()}
+	}
+	org, project, repo := unescaped[0], unescaped[1], unescaped[2]
+	switch {
+	case host == cloudSSHHost:
+		return Remote{Scheme: "https", Host: cloudHost, CollectionPath: parts[1], Collection: org, Project: project, Repository: repo}, nil
+	case host == legacySSHHost:
+		return Remote{Scheme: "https", Host: org + legacyHostSuffix, Collection: org, Project: project, Repository: repo}, nil
+	default:
+		return Remote{}, ErrInvalidRemote{RemoteUrl: remoteUrl, Reason: fmt.Sprintf("unknown ssh host %q", host)}
+	}
+}
+
+// parseGitPath parses a path containing a _git segment, such as /org/project/_git/repo
+func parseGitPath(remoteUrl, scheme, host, escapedPath string) (Remote, error) {
+	segments := strings.Split(strings.Trim(escapedPath, "/"), "/")
+	gitIndex := -1
+	for i, segment := range segments {
+		if segment == "_git" {
+			gitIndex = i
+			break
+		}
+	}
+	if gitIndex == -1 || gitIndex+1 >= len(segments) {
+		return Remote{}, ErrInvalidRemote{RemoteUrl: remoteUrl, Reason: "expected a path containing _git/<repository>"}
+	}
+	// anything after the repository (e.g. _optimized, _full) is ignored
+	unescaped, err := unescapeAll(segments[:gitIndex+2])
+	if err != nil {
+		return Remote{}, ErrInvalidRemote{RemoteUrl: remoteUrl, Reason: err.Error()}
+	}
+	repo := unescaped[gitIndex+1]
+	before := unescaped[:gitIndex]
+	escapedBefore := segments[:gitIndex]
+
+	remote := Remote{Scheme: scheme, Host: host, Repository: repo}
+	hostname := strings.ToLower(strings.Split(host, ":")[0])
+	if hostname == cloudHost {
+		// https://dev.azure.com/org/project/_git/repo or https://dev.azure.com/org/_git/repo
+		if len(before) < 1 || len(before) > 2 {
+			return Remote{}, ErrInvalidRemote{RemoteUrl: remoteUrl, Reason: "expected path in the form org/project/_git/repo"}
+		}
+		remote.Host = cloudHost
+		remote.Collection = before[0]
+		remote.CollectionPath = escapedBefore[0]
+		remote.Project = repo
+		if len(before) == 2 {
+			remote.Project = before[1]
+		}
+		return remote, nil
+	}
+	if strings.HasSuffix(hostname, legacyHostSuffix) {
+		// https://org.visualstudio.com[/DefaultCollection][/project]/_git/repo
+		if len(before) > 0 && strings.EqualFold(before[0], "DefaultCollection") {
+			before = before[1:]
+		}
+		if len(before) > 1 {
+			return Remote{}, ErrInvalidRemote{RemoteUrl: remoteUrl, Reason: "expected path in the form project/_git/repo"}
+		}
+		remote.Host = hostname
+		remote.Collection = strings.TrimSuffix(hostname, legacyHostSuffix)
+		remote.Project = repo
+		if len(before) == 1 {
+			remote.Project = before[0]
+		}
+		return remote, nil
+	}
+	// Azure DevOps Server: [/virtualdir]/collection[/project]/_git/repo.
+	// With a single segment before _git we can't tell a collection from a project, so it is treated as the collection.
+	switch len(before) {
+	case 0:
+		return Remote{}, ErrInvalidRemote{RemoteUrl: remoteUrl, Reason: "missing collection in path"}
+	case 1:
+		remote.Collection = before[0]
+		remote.CollectionPath = escapedBefore[0]
+		remote.Project = repo
+	default:
+		remote.Project = before[len(before)-1]
+		remote.Collection = before[len(before)-2]
+		remote.CollectionPath = strings.Join(escapedBefore[:len(escapedBefore)-1], "/")
+	}
+	return remote, nil
+}
+
+func unescapeAll(parts []string) ([]string, error) {
+	unescaped := make([]string, len(parts))
+	for i, part := range parts {
+		u, err := url.PathUnescape(part)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unescape %q: %w", part, err)
+		}
+		unescaped[i] = u
+	}
+	return unescaped, nil
+}
+
+// BaseUrl returns the organization (or collection) url used for REST calls, e.g. https://dev.azure.com/org
+func (r Remote) BaseUrl() string {
+	base := fmt.Sprintf("%s://%s", r.Scheme, r.Host)
+	if r.CollectionPath != "" {
+		base += "/" + r.CollectionPath
+	}
+	return base
+}
+
+// IsCloud reports whether the remote points to Azure DevOps Services (dev.azure.com or *.visualstudio.com)
+func (r Remote) IsCloud() bool {
+	return r.Host == cloudHost || strings.HasSuffix(r.Host, legacyHostSuffix)
+}
+
+// GetOrgUrl returns the organization (or collection) url for the given remote url
+func GetOrgUrl(remoteUrl string) (string, error) {
+	remote, err := ParseRemoteUrl(remoteUrl)
+	if err != nil {
+		return "", err
+	}
+	return remote.BaseUrl(), nil
+}
@@ -0,0 +1,138 @@
+package azdo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetOrgUrl(t *testing.T) {
+	tests := []struct {
+		name      string
+		remoteUrl string
+		want      string
+	}{
+		{
+			name:      "HTTPS URL",
+			remoteUrl: "https://dev.azure.com/MyOrg/MyProject/_git/MyRepo",
+			want:      "https://dev.azure.com/MyOrg",
+		},
+		{
+			name:      "SSH URL",
+			remoteUrl: "git@ssh.dev.azure.com:v3/MyOrg/MyProject/MyRepo",
+			want:      "https://dev.azure.com/MyOrg",
+		},
+		{
+			name:      "visualstudio.com URL",
+			remoteUrl: "https://MyOrg.visualstudio.com/MyProject/_git/MyRepo",
+			want:      "https://myorg.visualstudio.com",
+		},
+		{
+			name:      "Azure DevOps Server URL",
+			remoteUrl: "https://tfs.corp/tfs/DefaultCollection/MyProject/_git/MyRepo",
+			want:      "https://tfs.corp/tfs/DefaultCollection",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetOrgUrl(tt.remoteUrl)
+			if err != nil {
+				t.Fatalf("GetOrgUrl(%q) returned error: %v", tt.remoteUrl, err)
+			}
+			if got != tt.want {
+				t.Errorf("GetOrgUrl(%q) = %q; want %q", tt.remoteUrl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRemoteUrl(t *testing.T) {
+	tests := []struct {
+		name      string
+		remoteUrl string
+		want      Remote
+	}{
+		{
+			name:      "HTTPS URL",
+			remoteUrl: "https://dev.azure.com/MyOrg/MyProject/_git/MyRepo",
+			want:      Remote{Scheme: "https", Host: "dev.azure.com", CollectionPath: "MyOrg", Collection: "MyOrg", Project: "MyProject", Repository: "MyRepo"},
+		},
+		{
+			name:      "HTTPS URL with user",
+			remoteUrl: "https://MyOrg@dev.azure.com/MyOrg/My%20Project/_git/My%20Repo",
+			want:      Remote{Scheme: "https", Host: "dev.azure.com", CollectionPath: "MyOrg", Collection: "MyOrg", Project: "My Project", Repository: "My Repo"},
+		},
+		{
+			name:      "HTTPS short URL (project == repo)",
+			remoteUrl: "https://dev.azure.com/MyOrg/_git/MyRepo",
+			want:      Remote{Scheme: "https", Host: "dev.azure.com", CollectionPath: "MyOrg", Collection: "MyOrg", Project: "MyRepo", Repository: "MyRepo"},
+		},
+		{
+			name:      "SSH URL",
+			remoteUrl: "git@ssh.dev.azure.com:v3/MyOrg/MyProject/MyRepo",
+			want:      Remote{Scheme: "https", Host: "dev.azure.com", CollectionPath: "MyOrg", Collection: "MyOrg", Project: "MyProject", Repository: "MyRepo"},
+		},
+		{
+			name:      "SSH URL with scheme",
+			remoteUrl: "ssh://git@ssh.dev.azure.com/v3/MyOrg/MyProject/MyRepo",
+			want:      Remote{Scheme: "https", Host: "dev.azure.com", CollectionPath: "MyOrg", Collection: "MyOrg", Project: "MyProject", Repository: "MyRepo"},
+		},
+		{
+			name:      "visualstudio.com URL",
+			remoteUrl: "https://myorg.visualstudio.com/MyProject/_git/MyRepo",
+			want:      Remote{Scheme: "https", Host: "myorg.visualstudio.com", Collection: "myorg", Project: "MyProject", Repository: "MyRepo"},
+		},
+		{
+			name:      "visualstudio.com URL with DefaultCollection",
+			remoteUrl: "https://myorg.visualstudio.com/DefaultCollection/MyProject/_git/MyRepo",
+			want:      Remote{Scheme: "https", Host: "myorg.visualstudio.com", Collection: "myorg", Project: "MyProject", Repository: "MyRepo"},
+		},
+		{
+			name:      "visualstudio.com SSH URL",
+			remoteUrl: "myorg@vs-ssh.visualstudio.com:v3/myorg/MyProject/MyRepo",
+			want:      Remote{Scheme: "https", Host: "myorg.visualstudio.com", Collection: "myorg", Project: "MyProject", Repository: "MyRepo"},
+		},
+		{
+			name:      "Azure DevOps Server URL",
+			remoteUrl: "https://tfs.corp/tfs/DefaultCollection/MyProject/_git/MyRepo",
+			want:      Remote{Scheme: "https", Host: "tfs.corp", CollectionPath: "tfs/DefaultCollection", Collection: "DefaultCollection", Project: "MyProject", Repository: "MyRepo"},
+		},
+		{
+			name:      "Azure DevOps Server URL with port",
+			remoteUrl: "http://tfs.corp:8080/tfs/DefaultCollection/MyProject/_git/MyRepo",
+			want:      Remote{Scheme: "http", Host: "tfs.corp:8080", CollectionPath: "tfs/DefaultCollection", Collection: "DefaultCollection", Project: "MyProject", Repository: "MyRepo"},
+		},
+		{
+			name:      "Azure DevOps Server SSH URL",
+			remoteUrl: "ssh://tfs.corp:22/tfs/DefaultCollection/MyProject/_git/MyRepo",
+			want:      Remote{Scheme: "https", Host: "tfs.corp", CollectionPath: "tfs/DefaultCollection", Collection: "DefaultCollection", Project: "MyProject", Repository: "MyRepo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRemoteUrl(tt.remoteUrl)
+			if err != nil {
+				t.Fatalf("ParseRemoteUrl(%q) returned error: %v", tt.remoteUrl, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRemoteUrl(%q) = %+v; want %+v", tt.remoteUrl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRemoteUrlInvalid(t *testing.T) {
+	for _, remoteUrl := range []string{
+		"",
+		"https://github.com/rdalbuquerque/azdoext.git",
+		"git@github.com:rdalbuquerque/azdoext.git",
+		"https://dev.azure.com/MyOrg/MyProject",
+		"git@ssh.dev.azure.com:v3/MyOrg/MyProject",
+	} {
+		t.Run(remoteUrl, func(t *testing.T) {
+			_, err := ParseRemoteUrl(remoteUrl)
+			if !errors.As(err, &ErrInvalidRemote{}) {
+				t.Errorf("ParseRemoteUrl(%q) error = %v; want ErrInvalidRemote", remoteUrl, err)
+			}
+		})
+	}
+}
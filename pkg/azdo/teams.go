@@ -0,0 +1,152 @@
+package azdo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"azdoext/pkg/utils"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+)
+
+const (
+	// teamMembersPageSize is how many members of a team are requested at once
+	teamMembersPageSize = 100
+	// teamMemberFetches is how many teams have their members fetched at once
+	teamMemberFetches = 4
+)
+
+// Identity is a user or a team that can review a pull request
+type Identity struct {
+	Id          string
+	DisplayName string
+	UniqueName  string
+	IsTeam      bool
+}
+
+type TeamsClientInterface interface {
+	// GetIdentities returns the teams of the project and their members, teams first
+	GetIdentities(ctx context.Context) ([]Identity, error)
+}
+
+type TeamsClient struct {
+	client    core.Client
+	projectid string
+}
+
+func NewTeamsClient(ctx context.Context, orgurl, projectid string, authProvider AuthProvider) (TeamsClientInterface, error) {
+	azdoconn, err := newAuthConnection(ctx, orgurl, authProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create teams client: %w", err)
+	}
+	client, err := core.NewClient(ctx, azdoconn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create teams client: %w", err)
+	}
+	withAuthProvider(&client.(*core.ClientImpl).Client, authProvider)
+	return &TeamsClient{
+		client:    client,
+		projectid: projectid,
+	}, nil
+}
+
+func (t *TeamsClient) GetIdentities(ctx context.Context) ([]Identity, error) {
+	teams, err := t.client.GetTeams(ctx, core.GetTeamsArgs{
+		ProjectId: &t.projectid,
+		Top:       utils.Ptr(1000),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get teams: %w", err)
+	}
+	if teams == nil {
+		return []Identity{}, nil
+	}
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		members []Identity
+		errs    []error
+		slots   = make(chan struct{}, teamMemberFetches)
+	)
+	identities := make([]Identity, 0, len(*teams))
+	for _, team := range *teams {
+		if team.Id == nil {
+			continue
+		}
+		identities = append(identities, Identity{Id: team.Id.String(), DisplayName: utils.Deref(team.Name), IsTeam: true})
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(teamId string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			teamMembers, err := t.getTeamMembers(ctx, teamId)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			members = append(members, teamMembers...)
+		}(team.Id.String())
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to get team members: %w", errs[0])
+	}
+	return append(identities, uniqueIdentities(members)...), nil
+}
+
+// getTeamMembers pages through the members of a team, the service returns a single page of them otherwise
+func (t *TeamsClient) getTeamMembers(ctx context.Context, teamId string) ([]Identity, error) {
+	var members []Identity
+	for skip := 0; ; skip += teamMembersPageSize {
+		page, err := t.client.GetTeamMembersWithExtendedProperties(ctx, core.GetTeamMembersWithExtendedPropertiesArgs{
+			ProjectId: &t.projectid,
+			TeamId:    &teamId,
+			Top:       utils.Ptr(teamMembersPageSize),
+			Skip:      utils.Ptr(skip),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range utils.Deref(page) {
+			if member.Identity == nil || member.Identity.Id == nil {
+				continue
+			}
+			members = append(members, Identity{
+				Id:          *member.Identity.Id,
+				DisplayName: utils.Deref(member.Identity.DisplayName),
+				UniqueName:  utils.Deref(member.Identity.UniqueName),
+				IsTeam:      utils.Deref(member.Identity.IsContainer),
+			})
+		}
+		if len(utils.Deref(page)) < teamMembersPageSize {
+			return members, nil
+		}
+	}
+}
+
+// uniqueIdentities drops the members of several teams listed more than once and sorts them by name
+func uniqueIdentities(identities []Identity) []Identity {
+	slices.SortFunc(identities, func(a, b Identity) int {
+		if c := strings.Compare(strings.ToLower(a.DisplayName), strings.ToLower(b.DisplayName)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	return slices.CompactFunc(identities, func(a, b Identity) bool { return a.Id == b.Id })
+}
+
+// FindIdentity looks an identity up by display name or unique name, ignoring case
+func FindIdentity(identities []Identity, name string) (Identity, bool) {
+	name = strings.TrimSpace(name)
+	for _, identity := range identities {
+		if strings.EqualFold(identity.DisplayName, name) || (identity.UniqueName != "" && strings.EqualFold(identity.UniqueName, name)) {
+			return identity, true
+		}
+	}
+	return Identity{}, false
+}
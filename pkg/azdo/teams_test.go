@@ -0,0 +1,102 @@
+package azdo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"azdoext/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+)
+
+func TestUniqueIdentities(t *testing.T) {
+	identities := uniqueIdentities([]Identity{
+		{Id: "2", DisplayName: "sam"},
+		{Id: "1", DisplayName: "Alex"},
+		{Id: "2", DisplayName: "sam"},
+	})
+	want := []Identity{{Id: "1", DisplayName: "Alex"}, {Id: "2", DisplayName: "sam"}}
+	if !reflect.DeepEqual(identities, want) {
+		t.Errorf("uniqueIdentities() = %+v, want %+v", identities, want)
+	}
+}
+
+func TestFindIdentity(t *testing.T) {
+	identities := []Identity{
+		{Id: "1", DisplayName: "Release Team", IsTeam: true},
+		{Id: "2", DisplayName: "Sam", UniqueName: "sam@example.com"},
+	}
+	for name, want := range map[string]string{
+		"release team":    "1",
+		" Sam ":           "2",
+		"SAM@example.com": "2",
+		"unknown":         "",
+	} {
+		identity, ok := FindIdentity(identities, name)
+		if identity.Id != want || ok != (want != "") {
+			t.Errorf("FindIdentity(%q) = %+v, %v, want id %q", name, identity, ok, want)
+		}
+	}
+}
+
+// teamsCoreClient serves teams of the given sizes one page of members at a time
+type teamsCoreClient struct {
+	core.Client
+	sizes          map[uuid.UUID]int
+	mu             sync.Mutex
+	inFlight, peak int
+}
+
+func (c *teamsCoreClient) GetTeams(ctx context.Context, args core.GetTeamsArgs) (*[]core.WebApiTeam, error) {
+	teams := []core.WebApiTeam{}
+	for id := range c.sizes {
+		teams = append(teams, core.WebApiTeam{Id: utils.Ptr(id), Name: utils.Ptr(id.String())})
+	}
+	return &teams, nil
+}
+
+func (c *teamsCoreClient) GetTeamMembersWithExtendedProperties(ctx context.Context, args core.GetTeamMembersWithExtendedPropertiesArgs) (*[]webapi.TeamMember, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	c.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	defer func() {
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+	}()
+	size := c.sizes[uuid.MustParse(*args.TeamId)]
+	members := []webapi.TeamMember{}
+	for i := *args.Skip; i < min(size, *args.Skip+*args.Top); i++ {
+		id := fmt.Sprintf("%s-%d", *args.TeamId, i)
+		members = append(members, webapi.TeamMember{Identity: &webapi.IdentityRef{Id: &id, DisplayName: &id}})
+	}
+	return &members, nil
+}
+
+func TestGetIdentitiesPagesMembers(t *testing.T) {
+	sizes := map[uuid.UUID]int{uuid.New(): 250, uuid.New(): teamMembersPageSize}
+	for range 10 {
+		sizes[uuid.New()] = 1
+	}
+	client := &teamsCoreClient{sizes: sizes}
+	teams := &TeamsClient{client: client, projectid: "project"}
+
+	identities, err := teams.GetIdentities(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := len(sizes) + 250 + teamMembersPageSize + 10; len(identities) != want {
+		t.Errorf("got %d identities, want %d teams and all of their members", len(identities), want)
+	}
+	if client.peak > teamMemberFetches {
+		t.Errorf("members of %d teams were fetched at once, want at most %d", client.peak, teamMemberFetches)
+	}
+}
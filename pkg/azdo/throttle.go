@@ -0,0 +1,74 @@
+package azdo
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+)
+
+// throttle remembers the last Retry-After sent by Azure DevOps, which it sends with 429 responses and, when a client
+// gets close to its rate limit, with successful ones too
+var throttle = &throttleState{}
+
+type throttleState struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+func (t *throttleState) record(resp *http.Response, now time.Time) {
+	retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := now.Add(retryAfter); until.After(t.until) {
+		t.until = until
+	}
+}
+
+func (t *throttleState) remaining(now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return max(t.until.Sub(now), 0)
+}
+
+// RetryAfter returns how long Azure DevOps asked to wait before the next request, 0 if it didn't
+func RetryAfter() time.Duration {
+	return throttle.remaining(time.Now())
+}
+
+// IsThrottled tells whether err is a 429 Too Many Requests response
+func IsThrottled(err error) bool {
+	var wrapped *azuredevops.WrappedError
+	if errors.As(err, &wrapped) && wrapped.StatusCode != nil {
+		return *wrapped.StatusCode == http.StatusTooManyRequests
+	}
+	var wrappedValue azuredevops.WrappedError
+	if errors.As(err, &wrappedValue) && wrappedValue.StatusCode != nil {
+		return *wrappedValue.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header, either in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil || !date.After(now) {
+		return 0, false
+	}
+	return date.Sub(now), true
+}
@@ -0,0 +1,145 @@
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"azdoext/pkg/utils"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/build"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"30", 30 * time.Second, true},
+		{"0", 0, false},
+		{now.Add(time.Minute).Format(http.TimeFormat), time.Minute, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %s, %v, want %s, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestThrottleKeepsLongestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := &throttleState{}
+	state.record(&http.Response{Header: http.Header{"Retry-After": []string{"60"}}}, now)
+	state.record(&http.Response{Header: http.Header{"Retry-After": []string{"10"}}}, now)
+	state.record(&http.Response{Header: http.Header{}}, now)
+	if got := state.remaining(now.Add(20 * time.Second)); got != 40*time.Second {
+		t.Errorf("expected 40s left, got %s", got)
+	}
+	if got := state.remaining(now.Add(2 * time.Minute)); got != 0 {
+		t.Errorf("expected no wait once Retry-After passed, got %s", got)
+	}
+}
+
+func TestIsThrottled(t *testing.T) {
+	if !IsThrottled(azuredevops.WrappedError{StatusCode: utils.Ptr(http.StatusTooManyRequests)}) {
+		t.Error("expected a 429 to be throttled")
+	}
+	if IsThrottled(&azuredevops.WrappedError{StatusCode: utils.Ptr(http.StatusNotFound)}) {
+		t.Error("expected a 404 not to be throttled")
+	}
+}
+
+type fakeBuildsClient struct {
+	BuildClientInterface
+	builds         []build.Build
+	args           build.GetBuildsArgs
+	calls          int
+	definitions    []build.BuildDefinitionReference
+	definitionArgs build.GetDefinitionsArgs
+}
+
+func (f *fakeBuildsClient) GetDefinitions(ctx context.Context, args build.GetDefinitionsArgs) ([]build.BuildDefinitionReference, error) {
+	f.calls++
+	f.definitionArgs = args
+	if len(f.definitions) == 0 {
+		return nil, ErrNoBuildsFound{}
+	}
+	return f.definitions, nil
+}
+
+func (f *fakeBuildsClient) GetBuilds(ctx context.Context, args build.GetBuildsArgs) ([]build.Build, error) {
+	f.calls++
+	f.args = args
+	if len(f.builds) == 0 {
+		return nil, ErrNoBuildsFound{}
+	}
+	return f.builds, nil
+}
+
+func TestLatestBuilds(t *testing.T) {
+	newBuild := func(id, definitionId int) build.Build {
+		return build.Build{Id: utils.Ptr(id), Definition: &build.DefinitionReference{Id: utils.Ptr(definitionId)}}
+	}
+	client := &fakeBuildsClient{builds: []build.Build{newBuild(30, 1), newBuild(29, 2), newBuild(28, 1), newBuild(27, 1)}}
+	latest, err := LatestBuilds(context.Background(), client, []int{1, 2, 3}, "main", 2)
+	if err != nil {
+		t.Fatalf("LatestBuilds returned error: %v", err)
+	}
+	if client.calls != 1 {
+		t.Errorf("expected a single request, got %d", client.calls)
+	}
+	if *client.args.MaxBuildsPerDefinition != 2 || *client.args.BranchName != "refs/heads/main" || len(*client.args.Definitions) != 3 {
+		t.Errorf("unexpected GetBuilds args %+v", client.args)
+	}
+	if len(latest[1]) != 2 || *latest[1][0].Id != 30 || *latest[1][1].Id != 28 {
+		t.Errorf("expected the 2 latest builds of definition 1, got %v", latest[1])
+	}
+	if len(latest[2]) != 1 || len(latest[3]) != 0 {
+		t.Errorf("unexpected builds %v", latest)
+	}
+
+	latest, err = LatestBuilds(context.Background(), &fakeBuildsClient{}, []int{1}, "", 1)
+	if err != nil || len(latest) != 0 {
+		t.Errorf("expected no builds and no error, got %v, %v", latest, err)
+	}
+}
+
+func TestResolveDefinitions(t *testing.T) {
+	newDefinition := func(id int, folder, name string) build.BuildDefinitionReference {
+		return build.BuildDefinitionReference{Id: utils.Ptr(id), Path: utils.Ptr(folder), Name: utils.Ptr(name)}
+	}
+	client := &fakeBuildsClient{definitions: []build.BuildDefinitionReference{
+		newDefinition(1, `\`, "app-ci"),
+		newDefinition(2, `\infra`, "deploy-app"),
+		newDefinition(3, `\infra`, "deploy-db"),
+	}}
+	resolved, err := ResolveDefinitions(context.Background(), client, []string{"1", "infra/Deploy-App", `\missing\pipeline`})
+	if err != nil {
+		t.Fatalf("ResolveDefinitions returned error: %v", err)
+	}
+	if client.calls != 1 || client.definitionArgs.DefinitionIds != nil {
+		t.Errorf("expected a single project wide request, got %d calls with %+v", client.calls, client.definitionArgs)
+	}
+	var ids []int
+	for _, definition := range resolved {
+		ids = append(ids, *definition.Id)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("expected definitions 1 and 2, got %v", ids)
+	}
+
+	client = &fakeBuildsClient{definitions: client.definitions[2:]}
+	resolved, err = ResolveDefinitions(context.Background(), client, []string{"3"})
+	if err != nil || len(resolved) != 1 || client.definitionArgs.DefinitionIds == nil || !reflect.DeepEqual(*client.definitionArgs.DefinitionIds, []int{3}) {
+		t.Errorf("expected definitions to be fetched by id, got %v with %+v, %v", resolved, client.definitionArgs, err)
+	}
+}
@@ -1,6 +1,7 @@
 package azdosignalr
 
 import (
+	"azdoext/pkg/azdo"
 	"azdoext/pkg/logger"
 	"azdoext/pkg/teamsg"
 	"azdoext/pkg/utils"
@@ -10,6 +11,7 @@ import (
 	"math/rand"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -45,24 +47,31 @@ type SignalRResponse struct {
 }
 
 type SignalRClient struct {
-	Conn         *websocket.Conn
-	IsConnected  bool
-	logger       *logger.Logger
-	Organization string
-	AccountID    string
-	ProjectID    string
-	AuthHeader   string
+	Conn        *websocket.Conn
+	IsConnected bool
+	logger      *logger.Logger
+	// OrgUrl is the organization url (https://dev.azure.com/org) or the collection url on Azure DevOps Server
+	OrgUrl    string
+	AccountID string
+	ProjectID string
+	// authProvider is called on every Connect so a new connection never uses an expired token
+	authProvider azdo.AuthProvider
 }
 
 type negotiateResponse struct {
 	ConnectionToken string `json:"ConnectionToken"`
 }
 
-func GetConnectionParameters(organization string, accountID string, projectID string, authHeader string) (string, http.Header, error) {
-	connectionToken, err := fetchConnectionToken(authHeader, organization, projectID)
+func GetConnectionParameters(orgUrl string, accountID string, projectID string, authHeader string) (string, http.Header, error) {
+	baseUrl, err := url.Parse(orgUrl)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid organization url %q: %w", orgUrl, err)
+	}
+	connectionToken, err := fetchConnectionToken(authHeader, baseUrl, projectID)
 	if err != nil {
 		return "", nil, err
 	}
+	logger.RegisterSecret(connectionToken)
 
 	contextToken := accountID
 	queryParams := url.Values{}
@@ -70,10 +79,15 @@ func GetConnectionParameters(organization string, accountID string, projectID st
 	queryParams.Add("contextToken", contextToken)
 	queryParams.Add("connectionToken", connectionToken)
 
+	scheme := "wss"
+	if baseUrl.Scheme == "http" {
+		scheme = "ws"
+	}
+	// the connect endpoint lives under /_signalr/ at the host root followed by the organization (or collection) path
 	signalrURL := url.URL{
-		Scheme:   "wss",
-		Host:     "dev.azure.com",
-		Path:     fmt.Sprintf("_signalr/%s/_apis/%s/signalr/connect", organization, projectID),
+		Scheme:   scheme,
+		Host:     baseUrl.Host,
+		Path:     joinPath("_signalr", baseUrl.Path, "_apis", projectID, "signalr/connect"),
 		RawQuery: queryParams.Encode(),
 	}
 
@@ -96,27 +110,37 @@ func sendHandshake(c *websocket.Conn) error {
 }
 
 // NewSignalRConn initializes and returns a new websocket connection with Azure Devops SignalR endpoint
-func NewSignalR(organization, accountID, projectID, authHeader string) *SignalRClient {
-	logger := logger.NewLogger("azdosignalr.log")
+func NewSignalR(orgUrl, accountID, projectID string, authProvider azdo.AuthProvider) *SignalRClient {
+	logger := logger.NewLogger("azdosignalr")
 
 	return &SignalRClient{
-		Organization: organization,
+		OrgUrl:       orgUrl,
 		AccountID:    accountID,
 		ProjectID:    projectID,
-		AuthHeader:   authHeader,
+		authProvider: authProvider,
 		logger:       logger,
 	}
 }
 
+func joinPath(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part = strings.Trim(part, "/"); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "/")
+}
+
 // fetchConnectionToken fetches the connection token
-func fetchConnectionToken(authHeader, organization, projectID string) (string, error) {
+func fetchConnectionToken(authHeader string, baseUrl *url.URL, projectID string) (string, error) {
 	queryParams := url.Values{}
 	queryParams.Add("transport", "webSockets")
 
 	negotiateURL := url.URL{
-		Scheme:   "https",
-		Host:     "dev.azure.com",
-		Path:     fmt.Sprintf("%s/_apis/%s/signalr/negotiate", organization, projectID),
+		Scheme:   baseUrl.Scheme,
+		Host:     baseUrl.Host,
+		Path:     joinPath(baseUrl.Path, "_apis", projectID, "signalr/negotiate"),
 		RawQuery: queryParams.Encode(),
 	}
 
@@ -147,8 +171,12 @@ func fetchConnectionToken(authHeader, organization, projectID string) (string, e
 }
 
 func (s *SignalRClient) Connect() error {
-	s.logger.LogToFile("INFO", "connecting to signalr")
-	signalrURL, header, err := GetConnectionParameters(s.Organization, s.AccountID, s.ProjectID, s.AuthHeader)
+	s.logger.Info("connecting to signalr")
+	authHeader, err := s.authProvider(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get authorization header for SignalR: %w", err)
+	}
+	signalrURL, header, err := GetConnectionParameters(s.OrgUrl, s.AccountID, s.ProjectID, authHeader)
 	if err != nil {
 		return fmt.Errorf("failed to get connection parameters for SignalR: %w", err)
 	}
@@ -177,7 +205,7 @@ func (s *SignalRClient) ReadMessageWithRetry(attempts int, initialDelay time.Dur
 		if i >= (attempts - 1) {
 			break
 		}
-		s.logger.LogToFile("ERROR", fmt.Sprintf("error reading message, retrying in %.2f: %v", delay.Seconds(), err))
+		s.logger.Error("error reading message, retrying", "delay", delay, "error", err)
 		time.Sleep(delay)
 		delay = delay*2 + time.Duration(rand.Int63n(int64(delay/2)))
 	}
@@ -188,32 +216,32 @@ func (s *SignalRClient) ReadMessageWithRetry(attempts int, initialDelay time.Dur
 func (s *SignalRClient) StartReceivingLoop(ctx context.Context, logChan chan<- teamsg.LogMsg, connClosedChan chan<- bool, connClosedErrChan chan<- error) {
 	defer func() {
 		if err := s.Conn.Close(); err != nil {
-			s.logger.LogToFile("ERROR", fmt.Sprintf("error closing connection: %v", err))
+			s.logger.Error("error closing connection", "error", err)
 			connClosedErrChan <- err
 		} else {
 			s.IsConnected = false
-			s.logger.LogToFile("INFO", "sending connection closed signal")
+			s.logger.Info("sending connection closed signal")
 			connClosedChan <- true
-			s.logger.LogToFile("INFO", "connection closed")
+			s.logger.Info("connection closed")
 		}
 	}()
 receiveMessages:
 	for {
 		select {
 		case <-ctx.Done():
-			s.logger.LogToFile("INFO", "receiving loop canceled")
+			s.logger.Info("receiving loop canceled")
 			break receiveMessages
 		default:
 			message, err := s.ReadMessageWithRetry(5, 1*time.Second)
 			if err != nil {
-				s.logger.LogToFile("ERROR", fmt.Sprintf("error reading message: %v", err))
+				s.logger.Error("error reading message", "error", err)
 				break receiveMessages
 			}
 
 			var response SignalRResponse
 			err = json.Unmarshal(message, &response)
 			if err != nil {
-				s.logger.LogToFile("WARN", fmt.Sprintf("error unmarshalling message %s: %v", message, err))
+				s.logger.Warn("error unmarshalling message", "message", message, "error", err)
 				continue
 			}
 
@@ -225,7 +253,7 @@ receiveMessages:
 							BuildResult: detail.Build.Result,
 						}
 						if detail.Build.Status == "completed" {
-							s.logger.LogToFile("INFO", "build completed")
+							s.logger.Info("build completed")
 							break receiveMessages
 						} else {
 							continue
@@ -233,7 +261,7 @@ receiveMessages:
 					}
 					uuidStepRecordID, err := uuid.Parse(detail.StepRecordID)
 					if err != nil {
-						s.logger.LogToFile("ERROR", fmt.Sprintf("error parsing step record ID: %v", err))
+						s.logger.Error("error parsing step record ID", "error", err)
 						continue
 					}
 					for _, line := range detail.Lines {
@@ -256,7 +284,7 @@ func (s *SignalRClient) SendWatchBuildMessage(runID int) error {
 
 // SendMessage sends a message to the SignalR connection
 func (s *SignalRClient) sendMessage(hubName, methodName string, args []interface{}) error {
-	s.logger.LogToFile("INFO", fmt.Sprintf("sending message to hub %s, method %s, args %v", hubName, methodName, args))
+	s.logger.Info("sending message", "hub", hubName, "method", methodName, "args", args)
 	message := map[string]interface{}{
 		"H": hubName,
 		"M": methodName,
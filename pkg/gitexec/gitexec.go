@@ -7,7 +7,6 @@ package gitexec
 
 import (
 	"azdoext/pkg/logger"
-	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -31,15 +30,12 @@ func Config() (config GitConfig, err error) {
 	if err != nil {
 		return GitConfig{}, fmt.Errorf("error running 'git config --get remote.origin.url': %v", err)
 	}
-	if !strings.Contains(string(origin), "dev.azure.com") {
-		return GitConfig{}, errors.New("not a valid Azure DevOps git repository, 'git config --get remote.origin.url' does not contain 'dev.azure.com'")
-	}
 
 	cmd = exec.Command("git", "branch", "--show-current")
 
 	currentBranch, err := cmd.CombinedOutput()
 	if err != nil {
-		panic(err)
+		return GitConfig{}, fmt.Errorf("error running 'git branch --show-current': %v", err)
 	}
 
 	return GitConfig{
@@ -74,11 +70,11 @@ func parseStatus(status string) []GitFile {
 }
 
 func AddGlob(glob string) {
-	logger := logger.NewLogger("gitexec.log")
-	logger.LogToFile("debug", "Adding files with glob: "+glob)
+	logger := logger.NewLogger("gitexec")
+	logger.Debug("adding files", "glob", glob)
 	cmd := exec.Command("git", "add", glob)
 	out, err := cmd.CombinedOutput()
-	logger.LogToFile("debug", string(out))
+	logger.Debug(string(out))
 	if err != nil {
 		panic(err)
 	}
@@ -101,27 +97,29 @@ func Unstage(file string) {
 }
 
 func Commit(message string) {
-	logger := logger.NewLogger("gitexec.log")
+	logger := logger.NewLogger("gitexec")
 	cmd := exec.Command("git", "commit", "-m", message)
 	out, err := cmd.CombinedOutput()
-	logger.LogToFile("debug", string(out))
+	logger.Debug(string(out))
 	if err != nil {
 		panic(err)
 	}
 
 }
 
-func Push(remote string, branch string, authHeader string) {
+// Push pushes branch to remote, authenticating with authHeader
+func Push(remote string, branch string, authHeader string) error {
 	authHeader = fmt.Sprintf("http.extraheader=AUTHORIZATION: %s", authHeader)
-	logger := logger.NewLogger("gitexec.log")
-	logger.LogToFile("debug", "authHeader: "+authHeader)
+	logger := logger.NewLogger("gitexec")
+	logger.Debug("pushing", "branch", branch, "remote", remote)
 	// Use -c option to set temporary config for this command
 	cmd := exec.Command("git", "-c", authHeader, "push", remote, branch)
 
-	_, err := cmd.CombinedOutput()
+	out, err := cmd.CombinedOutput()
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("git push failed: %w: %s", err, strings.TrimSpace(string(out)))
 	}
+	return nil
 }
 
 func Pull(remote string, branch string) {
@@ -132,3 +130,74 @@ func Pull(remote string, branch string) {
 	}
 
 }
+
+// HeadCommit returns the full SHA of the checked out commit
+func HeadCommit() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error running 'git rev-parse HEAD': %v", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// TopLevel returns the absolute path of the root of the worktree
+func TopLevel() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error running 'git rev-parse --show-toplevel': %v", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ListFiles returns the tracked and untracked, not ignored, files of the worktree matching any of the patterns,
+// relative to its root
+func ListFiles(patterns ...string) ([]string, error) {
+	root, err := TopLevel()
+	if err != nil {
+		return nil, err
+	}
+	args := append([]string{"ls-files", "--cached", "--others", "--exclude-standard", "--"}, patterns...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = root
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error running 'git ls-files': %v", err)
+	}
+	var files []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+type CommitMessage struct {
+	Subject string
+	Body    string
+}
+
+// CommitMessages returns the messages of the commits reachable from head but not from base, oldest first
+func CommitMessages(base, head string) ([]CommitMessage, error) {
+	cmd := exec.Command("git", "log", "--reverse", "--format=%s%x1f%b%x1e", base+".."+head)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error running 'git log %s..%s': %v: %s", base, head, err, strings.TrimSpace(string(out)))
+	}
+	return parseCommitMessages(string(out)), nil
+}
+
+func parseCommitMessages(log string) []CommitMessage {
+	var commits []CommitMessage
+	for _, record := range strings.Split(log, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		subject, body, _ := strings.Cut(record, "\x1f")
+		commits = append(commits, CommitMessage{Subject: strings.TrimSpace(subject), Body: strings.TrimSpace(body)})
+	}
+	return commits
+}
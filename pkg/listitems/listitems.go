@@ -18,6 +18,8 @@ var (
 	itemStyle         = lipgloss.NewStyle().PaddingLeft(2)
 	selectedItemStyle = lipgloss.NewStyle().PaddingLeft(0).Foreground(lipgloss.Color("170"))
 	stagedFileStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00"))
+	runContextStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#6c6c6c"))
+	folderStyle       = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#6c6c6c"))
 )
 
 type PipelineItem struct {
@@ -27,9 +29,27 @@ type PipelineItem struct {
 	Status string
 	Result string
 	Symbol *string
+	// Branch, Commit and QueueTime describe the run shown, they are empty if the pipeline has no runs
+	Branch    string
+	Commit    string
+	QueueTime time.Time
+	// Folder is the definition folder, e.g. \team\ci, \ for the root
+	Folder   string
+	Favorite bool
+	// External pipelines belong to another repository of the project, Repository is its name once the pipeline ran
+	External   bool
+	Repository string
 }
 
-func (i PipelineItem) FilterValue() string { return "" }
+func (i PipelineItem) FilterValue() string { return i.Name }
+
+// FolderItem is the header of the pipelines of a folder, or of the favorite pipelines, in the pipeline list
+type FolderItem struct {
+	Title string
+	Count int
+}
+
+func (i FolderItem) FilterValue() string { return "" }
 
 type ItemDelegate struct{}
 
@@ -37,19 +57,36 @@ func (d ItemDelegate) Height() int                             { return 1 }
 func (d ItemDelegate) Spacing() int                            { return 0 }
 func (d ItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	if folder, ok := listItem.(FolderItem); ok {
+		header := fmt.Sprintf("%s (%d)", folder.Title, folder.Count)
+		fmt.Fprint(w, folderStyle.MaxWidth(m.Width()).Render(header))
+		return
+	}
 	i, ok := listItem.(PipelineItem)
 	if !ok {
 		return
 	}
 
 	var str string
+	runContext := pipelineRunContext(i)
 	titleStyle := lipgloss.NewStyle().MaxWidth(40)
-	title := titleStyle.Render(i.Name)
+	if runContext != "" {
+		// keep room for the run context, the name is cut first
+		titleStyle = titleStyle.MaxWidth(max(m.Width()-lipgloss.Width(runContext)-6, 16))
+	}
+	name := i.Name
+	if i.Favorite {
+		name = "★ " + name
+	}
+	title := titleStyle.Render(name)
 	if i.Symbol != nil {
 		str = fmt.Sprintf("%s %s", *i.Symbol, title)
 	} else {
 		str = title
 	}
+	if runContext != "" {
+		str += " " + runContextStyle.Render(runContext)
+	}
 
 	fn := itemStyle.Render
 	if index == m.Index() {
@@ -61,6 +98,201 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	fmt.Fprint(w, fn(str))
 }
 
+// RunItem is a run of a pipeline in its run history
+type RunItem struct {
+	Id           int
+	Branch       string
+	Reason       string
+	RequestedFor string
+	Duration     time.Duration
+	Status       string
+	Result       string
+	Symbol       *string
+}
+
+func (i RunItem) FilterValue() string { return "" }
+
+type RunItemDelegate struct{}
+
+func (d RunItemDelegate) Height() int                             { return 1 }
+func (d RunItemDelegate) Spacing() int                            { return 0 }
+func (d RunItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d RunItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(RunItem)
+	if !ok {
+		return
+	}
+
+	duration := "-"
+	if i.Duration > 0 {
+		duration = i.Duration.Round(time.Second).String()
+	}
+	str := fmt.Sprintf("%s %-8d %-24s %-16s %-20s %9s", *i.Symbol, i.Id, truncate(i.Branch, 24), truncate(i.Reason, 16), truncate(i.RequestedFor, 20), duration)
+
+	fn := itemStyle.Render
+	if index == m.Index() {
+		fn = func(s ...string) string {
+			return selectedItemStyle.Render("> " + strings.Join(s, " "))
+		}
+	}
+
+	fmt.Fprint(w, lipgloss.NewStyle().MaxWidth(m.Width()).Render(fn(str)))
+}
+
+func truncate(s string, width int) string {
+	if len([]rune(s)) <= width {
+		return s
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
+
+// pipelineRunContext describes the run of a pipeline item as branch, short SHA and age, e.g. "main 1a2b3c4 5m"
+func pipelineRunContext(i PipelineItem) string {
+	var parts []string
+	branch := strings.TrimPrefix(i.Branch, "refs/heads/")
+	if i.Repository != "" {
+		branch = i.Repository + ":" + branch
+	}
+	if branch != "" {
+		parts = append(parts, branch)
+	}
+	if i.Commit != "" {
+		parts = append(parts, i.Commit[:min(len(i.Commit), 7)])
+	}
+	if !i.QueueTime.IsZero() {
+		parts = append(parts, FormatAge(time.Since(i.QueueTime)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// FormatAge formats how long ago something happened with its largest unit, e.g. 5m or 2d
+func FormatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(age.Hours()/24))
+}
+
+// PullRequestReviewer is a reviewer of a pull request and its vote, from -10 rejected to 10 approved
+type PullRequestReviewer struct {
+	Name       string
+	Vote       int
+	IsRequired bool
+}
+
+// PullRequestItem is a pull request of the repository in the pull request list
+type PullRequestItem struct {
+	Id           int
+	Title        string
+	Description  string
+	Author       string
+	SourceBranch string
+	TargetBranch string
+	Status       string
+	IsDraft      bool
+	MergeStatus  string
+	// LastMergeSourceCommit is the commit of the source branch the pull request was last merged with, completing
+	// the pull request fails if the branch moved on since
+	LastMergeSourceCommit string
+	// AutoCompleteSetBy is who set the pull request to complete once its policies are satisfied, empty if nobody did
+	AutoCompleteSetBy string
+	// PolicyState sums up the policy evaluations, see azdo.PolicyState
+	PolicyState  string
+	Reviewers    []PullRequestReviewer
+	CreationDate time.Time
+	Url          string
+	Symbol       *string
+}
+
+func (i PullRequestItem) FilterValue() string { return i.Title }
+
+type PullRequestItemDelegate struct{}
+
+func (d PullRequestItemDelegate) Height() int                             { return 1 }
+func (d PullRequestItemDelegate) Spacing() int                            { return 0 }
+func (d PullRequestItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d PullRequestItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(PullRequestItem)
+	if !ok {
+		return
+	}
+
+	title := fmt.Sprintf("#%d %s", i.Id, i.Title)
+	if i.IsDraft {
+		title = fmt.Sprintf("#%d [draft] %s", i.Id, i.Title)
+	}
+	context := pullRequestContext(i)
+	str := fmt.Sprintf("%s %s %s", *i.Symbol, truncate(title, max(m.Width()-lipgloss.Width(context)-6, 16)), runContextStyle.Render(context))
+
+	fn := itemStyle.Render
+	if index == m.Index() {
+		fn = func(s ...string) string {
+			return selectedItemStyle.Render("> " + strings.Join(s, " "))
+		}
+	}
+
+	fmt.Fprint(w, lipgloss.NewStyle().MaxWidth(m.Width()).Render(fn(str)))
+}
+
+// pullRequestContext describes a pull request as author, branches, votes and merge status,
+// e.g. "Sam feature → main ✓1 ✗1 conflicts"
+func pullRequestContext(i PullRequestItem) string {
+	parts := []string{i.Author, fmt.Sprintf("%s → %s", strings.TrimPrefix(i.SourceBranch, "refs/heads/"), strings.TrimPrefix(i.TargetBranch, "refs/heads/"))}
+	if votes := VoteSummary(i.Reviewers); votes != "" {
+		parts = append(parts, votes)
+	}
+	if i.MergeStatus == "conflicts" {
+		parts = append(parts, i.MergeStatus)
+	}
+	return strings.Join(parts, " ")
+}
+
+// VoteSummary counts the votes of the reviewers that voted, e.g. "✓2 ~1 ✗1" for two approvals,
+// one waiting for the author and one rejection
+func VoteSummary(reviewers []PullRequestReviewer) string {
+	var approved, waiting, rejected int
+	for _, reviewer := range reviewers {
+		switch {
+		case reviewer.Vote >= 5:
+			approved++
+		case reviewer.Vote == -5:
+			waiting++
+		case reviewer.Vote <= -10:
+			rejected++
+		}
+	}
+	var parts []string
+	for _, count := range []struct {
+		symbol string
+		count  int
+	}{{"✓", approved}, {"~", waiting}, {"✗", rejected}} {
+		if count.count > 0 {
+			parts = append(parts, fmt.Sprintf("%s%d", count.symbol, count.count))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// VoteName describes a vote the way Azure DevOps does
+func VoteName(vote int) string {
+	switch {
+	case vote >= 10:
+		return "approved"
+	case vote >= 5:
+		return "approved with suggestions"
+	case vote <= -10:
+		return "rejected"
+	case vote < 0:
+		return "waiting for author"
+	}
+	return "no vote"
+}
+
 type StagedFileItem struct {
 	RawStatus string
 	Name      string
@@ -125,14 +357,15 @@ func (d ChoiceItemDelegate) Render(w io.Writer, m list.Model, index int, listIte
 }
 
 type PipelineRecordItem struct {
-	Id        uuid.UUID
-	Name      string
-	Order     int
-	StartTime time.Time
-	Type      string
-	State     build.TimelineRecordState
-	Result    build.TaskResult
-	Symbol    *string
+	Id         uuid.UUID
+	Name       string
+	Identifier string
+	Order      int
+	StartTime  time.Time
+	Type       string
+	State      build.TimelineRecordState
+	Result     build.TaskResult
+	Symbol     *string
 }
 
 func (p PipelineRecordItem) FilterValue() string { return "" }
@@ -157,6 +390,10 @@ func (p PipelineRecordItemDelegate) Render(w io.Writer, m list.Model, index int,
 	case "Task":
 		spacing = "    "
 	}
+	// approvals and checks sit under their stage, at the level of jobs
+	if strings.HasPrefix(i.Type, "Checkpoint.") {
+		spacing = "  "
+	}
 
 	name := i.Name
 	symbol := *i.Symbol
@@ -170,6 +407,48 @@ func (p PipelineRecordItemDelegate) Render(w io.Writer, m list.Model, index int,
 	fmt.Fprint(w, fn(spacing, symbol, name))
 }
 
+// PolicyItem is a branch policy evaluated on a pull request in the pull request status
+type PolicyItem struct {
+	Name       string
+	Status     string
+	IsBlocking bool
+	// BuildId is the run of a build validation, zero for other policies
+	BuildId int
+	Symbol  *string
+}
+
+func (i PolicyItem) FilterValue() string { return i.Name }
+
+type PolicyItemDelegate struct{}
+
+func (d PolicyItemDelegate) Height() int                             { return 1 }
+func (d PolicyItemDelegate) Spacing() int                            { return 0 }
+func (d PolicyItemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d PolicyItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(PolicyItem)
+	if !ok {
+		return
+	}
+
+	context := i.Status
+	if !i.IsBlocking {
+		context += " (optional)"
+	}
+	if i.BuildId > 0 {
+		context += fmt.Sprintf(" run %d", i.BuildId)
+	}
+	str := fmt.Sprintf("%s %s %s", *i.Symbol, truncate(i.Name, max(m.Width()-lipgloss.Width(context)-6, 16)), runContextStyle.Render(context))
+
+	fn := itemStyle.Render
+	if index == m.Index() {
+		fn = func(s ...string) string {
+			return selectedItemStyle.Render("> " + strings.Join(s, " "))
+		}
+	}
+
+	fmt.Fprint(w, lipgloss.NewStyle().MaxWidth(m.Width()).Render(fn(str)))
+}
+
 type HelpKeys struct {
 	AdditionalShortHelpKeys func() []key.Binding
 }
@@ -1,40 +1,221 @@
 package logger
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
-	"time"
+	"runtime"
+	"strings"
+	"sync"
 )
 
+const (
+	logFileName = "azdoext.log"
+	// maxLogSize is the size at which the log file is rotated
+	maxLogSize = 10 * 1024 * 1024
+	// maxLogBackups is how many rotated files (azdoext.log.1, azdoext.log.2...) are kept
+	maxLogBackups = 3
+)
+
+// Logger writes to the shared azdoext log file, tagging every record with its component
 type Logger struct {
-	filename string
+	slog *slog.Logger
+}
+
+var (
+	baseOnce sync.Once
+	base     *slog.Logger
+)
+
+// NewLogger returns the logger of component, e.g. "main" or "pipelinelist"
+func NewLogger(component string) *Logger {
+	return &Logger{
+		slog: baseLogger().With("component", component),
+	}
 }
 
-func NewLogger(filename string) *Logger {
-	// if OS is Windows, concatenate LOCALAPPDATA environment variable to filename
-	if os.Getenv("OS") == "Windows_NT" {
-		filename = os.Getenv("LOCALAPPDATA") + "\\azdoext\\" + filename
+func (l *Logger) Debug(msg string, args ...any) { l.slog.Debug(msg, args...) }
+func (l *Logger) Info(msg string, args ...any)  { l.slog.Info(msg, args...) }
+func (l *Logger) Warn(msg string, args ...any)  { l.slog.Warn(msg, args...) }
+func (l *Logger) Error(msg string, args ...any) { l.slog.Error(msg, args...) }
+
+// Path returns the log file location: $XDG_STATE_HOME/azdoext (~/.local/state/azdoext) on Linux and macOS,
+// %LOCALAPPDATA%\azdoext on Windows
+func Path() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "azdoext", logFileName), nil
 	}
-	dir := filepath.Dir(filename)
-	err := os.MkdirAll(dir, os.ModePerm)
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, "azdoext", logFileName), nil
+		}
+	}
+	home, err := os.UserHomeDir()
 	if err != nil {
-		panic(err)
+		return "", fmt.Errorf("failed to find home directory: %w", err)
 	}
-	return &Logger{
-		filename: filename,
+	return filepath.Join(home, ".local", "state", "azdoext", logFileName), nil
+}
+
+// parseLevel converts DEBUG_AZDOEXT to a level, false means logging is disabled.
+// Any value other than a level name (e.g. "1" or "true") enables debug logging.
+func parseLevel(value string) (slog.Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "0", "false", "off":
+		return 0, false
+	case "info":
+		return slog.LevelInfo, true
+	case "warn", "warning":
+		return slog.LevelWarn, true
+	case "error":
+		return slog.LevelError, true
+	}
+	return slog.LevelDebug, true
+}
+
+func baseLogger() *slog.Logger {
+	baseOnce.Do(func() {
+		base = slog.New(slog.DiscardHandler)
+		level, enabled := parseLevel(os.Getenv("DEBUG_AZDOEXT"))
+		if !enabled {
+			return
+		}
+		path, err := Path()
+		if err != nil {
+			return
+		}
+		base = newBase(newRotatingWriter(path, maxLogSize, maxLogBackups), level, os.Getenv("AZDOEXT_LOG_FORMAT"))
+	})
+	return base
+}
+
+// newBase builds the root logger, format is "json" or "logfmt" (the default)
+func newBase(w io.Writer, level slog.Level, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if strings.EqualFold(format, "json") {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(redactHandler{handler})
+}
+
+// redactHandler runs every message and attribute through Redact before it is written
+type redactHandler struct {
+	slog.Handler
+}
+
+func (h redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return redactHandler{h.Handler.WithAttrs(redacted)}
+}
+
+func (h redactHandler) WithGroup(name string) slog.Handler {
+	return redactHandler{h.Handler.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		attrs := value.Group()
+		redacted := make([]any, len(attrs))
+		for i, attr := range attrs {
+			redacted[i] = redactAttr(attr)
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindString, slog.KindAny:
+		return slog.String(a.Key, Redact(value.String()))
+	}
+	return a
+}
+
+// rotatingWriter appends to path and rotates it once it grows past maxSize
+type rotatingWriter struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func newRotatingWriter(path string, maxSize int64, maxBackups int) *rotatingWriter {
+	return &rotatingWriter{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
 	}
 }
 
-func (l *Logger) LogToFile(identifier, msg string) {
-	if os.Getenv("DEBUG_AZDOEXT") == "" {
-		return
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
 	}
-	f, err := os.OpenFile(l.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
 	if err != nil {
-		panic(err)
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// rotate shifts azdoext.log.N to azdoext.log.N+1, dropping the oldest, and starts a new file
+func (w *rotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	os.Remove(fmt.Sprintf("%s.%d", w.path, w.maxBackups))
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1))
+	}
+	if w.maxBackups > 0 {
+		if err := os.Rename(w.path, w.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(w.path); err != nil {
+		return err
 	}
-	defer f.Close()
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	f.WriteString(fmt.Sprintf("%s | %s | %s\n", timestamp, identifier, msg))
+	return w.open()
 }
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	// authSchemePattern matches the credentials of an Authorization header value, e.g. "Basic OnBhdDEyMzQ1Njc4OQ==" or
+	// "Bearer eyJ0...". Credentials are at least 16 base64 or JWT characters, so that text like
+	// "basic auth failed" is left alone
+	authSchemePattern = regexp.MustCompile(`(?i)\b(basic|bearer)(\s+)([A-Za-z0-9\-._~+/]{16,}=*)`)
+	// tokenParamPattern matches tokens passed as query parameters, like the SignalR connectionToken
+	tokenParamPattern = regexp.MustCompile(`(?i)\b((?:connection|access)_?token=)[^&\s"']+`)
+
+	secretsMu sync.RWMutex
+	secrets   []string
+)
+
+// minSecretLength keeps short strings from masking unrelated text
+const minSecretLength = 4
+
+// RegisterSecret makes every logger mask secret, for values that don't follow a recognizable pattern like a raw PAT
+func RegisterSecret(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, s := range secrets {
+		if s == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+}
+
+// Redact masks authorization credentials, token query parameters and registered secrets in msg
+func Redact(msg string) string {
+	secretsMu.RLock()
+	for _, secret := range secrets {
+		msg = strings.ReplaceAll(msg, secret, redacted)
+	}
+	secretsMu.RUnlock()
+	msg = authSchemePattern.ReplaceAllStringFunc(msg, redactCredentials)
+	return tokenParamPattern.ReplaceAllString(msg, "${1}"+redacted)
+}
+
+// redactCredentials masks the credentials of an authSchemePattern match when they look like a token: base64 and JWTs
+// have digits or symbols, a long word after "basic" or "bearer" is just text
+func redactCredentials(match string) string {
+	parts := authSchemePattern.FindStringSubmatch(match)
+	if !strings.ContainsAny(parts[3], "0123456789+/=.") {
+		return match
+	}
+	return parts[1] + parts[2] + redacted
+}
@@ -57,8 +57,8 @@ func (p *GitPage) AddSection(section sections.Section) {
 	p.sections[secid] = section
 }
 
-func NewGitPage(ctx context.Context, gitclient azdo.GitClientInterface, azdoconfig azdo.Config) PageInterface {
-	logger := logger.NewLogger("gitpage.log")
+func NewGitPage(ctx context.Context, gitclient azdo.GitClientInterface, teamsclient azdo.TeamsClientInterface, azdoconfig azdo.Config, authProvider azdo.AuthProvider) PageInterface {
+	logger := logger.NewLogger("gitpage")
 	hk := helpKeys{}
 	helpstring := bubbleshelp.New().View(hk)
 	gitPage := &GitPage{
@@ -68,11 +68,11 @@ func NewGitPage(ctx context.Context, gitclient azdo.GitClientInterface, azdoconf
 	gitPage.shortHelp = helpstring
 	commitsec := sections.NewCommitSection(sections.Commit)
 	gitPage.AddSection(commitsec)
-	worktreesec := sections.NewWorktreeSection(sections.Worktree, azdoconfig.CurrentBranch, azdoconfig)
+	worktreesec := sections.NewWorktreeSection(sections.Worktree, azdoconfig.CurrentBranch, azdoconfig, authProvider)
 	gitPage.AddSection(worktreesec)
 	commitActionChoiceSec := sections.NewChoice(sections.PrOrPipelineChoice)
 	gitPage.AddSection(commitActionChoiceSec)
-	openprsec := sections.NewPRSection(sections.OpenPR, gitclient, azdoconfig)
+	openprsec := sections.NewPRSection(ctx, sections.OpenPR, gitclient, teamsclient, azdoconfig)
 	gitPage.AddSection(openprsec)
 	gitPage.sections[sections.Commit].Focus()
 	gitPage.sections[sections.Worktree].Blur()
@@ -13,6 +13,7 @@ const (
 	Help         PageName = "help"
 	PipelineRun  PageName = "pipelineRun"
 	PipelineList PageName = "pipelineList"
+	PullRequests PageName = "pullRequests"
 )
 
 type Stack []PageInterface
@@ -70,6 +71,10 @@ func (h helpKeys) ShortHelp() []key.Binding {
 			key.WithKeys("ctrl+b"),
 			key.WithHelp("ctrl+b", "previous page"),
 		),
+		key.NewBinding(
+			key.WithKeys("ctrl+o"),
+			key.WithHelp("ctrl+o", "pull requests"),
+		),
 		key.NewBinding(
 			key.WithKeys(""),
 			key.WithHelp("↑/k ↓/j navigate and", "↵ select on all lists"),
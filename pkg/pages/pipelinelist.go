@@ -8,6 +8,7 @@ import (
 	"azdoext/pkg/styles"
 	"azdoext/pkg/teamsg"
 	"context"
+	"fmt"
 
 	bubbleshelp "charm.land/bubbles/v2/help"
 	"charm.land/bubbles/v2/list"
@@ -17,6 +18,9 @@ import (
 
 type PipelineListPage struct {
 	selectedPipeline listitems.PipelineItem
+	buildclient      azdo.BuildClientInterface
+	pipelinesclient  azdo.PipelinesClientInterface
+	azdoconfig       azdo.Config
 	logger           *logger.Logger
 	current          bool
 	name             PageName
@@ -62,16 +66,19 @@ func (p *PipelineListPage) AddSection(section sections.Section) {
 	}
 }
 
-func NewPipelineListPage(ctx context.Context, buildclient azdo.BuildClientInterface, azdoconfig azdo.Config) PageInterface {
+func NewPipelineListPage(ctx context.Context, buildclient azdo.BuildClientInterface, pipelinesclient azdo.PipelinesClientInterface, azdoconfig azdo.Config) PageInterface {
 	hk := helpKeys{}
 	helpstring := bubbleshelp.New().View(hk)
-	logger := logger.NewLogger("pipelinelistpage.log")
+	logger := logger.NewLogger("pipelinelistpage")
 
 	pipelistpage := &PipelineListPage{
-		logger:    logger,
-		ctx:       ctx,
-		name:      PipelineList,
-		shorthelp: helpstring,
+		logger:          logger,
+		ctx:             ctx,
+		name:            PipelineList,
+		shorthelp:       helpstring,
+		buildclient:     buildclient,
+		pipelinesclient: pipelinesclient,
+		azdoconfig:      azdoconfig,
 	}
 
 	pipelistsec := sections.NewPipelineList(ctx, sections.PipelineList, buildclient, azdoconfig)
@@ -105,10 +112,23 @@ func (p *PipelineListPage) Update(msg tea.Msg) (PageInterface, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
 		if p.current {
+			// while a section reads text, tab and esc belong to it
+			if capturer, ok := p.focusedSection().(inputCapturer); ok && capturer.CapturingInput() {
+				sections, cmds := p.updateSections(msg)
+				p.sections = sections
+				return p, tea.Batch(cmds...)
+			}
 			switch msg.String() {
 			case "tab":
 				p.switchSection()
 				return p, nil
+			case "esc":
+				for _, secid := range []sections.SectionName{sections.RunOptions, sections.RunHistory, sections.CreatePipeline} {
+					if sec, ok := p.sections[secid]; ok && sec.IsFocused() {
+						p.closeSection(secid)
+						return p, nil
+					}
+				}
 			}
 			sections, cmds := p.updateSections(msg)
 			p.sections = sections
@@ -116,13 +136,58 @@ func (p *PipelineListPage) Update(msg tea.Msg) (PageInterface, tea.Cmd) {
 		}
 		return p, nil
 	case teamsg.SubmitChoiceMsg:
-		p.logger.LogToFile("debug", "received choice")
+		p.logger.Debug("received choice")
 		if choiceSec, ok := p.sections[sections.PipelineActionChoice]; !ok || !choiceSec.IsFocused() {
 			return p, nil
 		}
-		p.logger.LogToFile("debug", "choice is focused, hiding choice section")
+		p.logger.Debug("choice is focused, hiding choice section")
 		p.sections[sections.PipelineActionChoice].Hide()
 		p.sections[sections.PipelineList].Focus()
+		switch listitems.OptionName(msg) {
+		case sections.Options.RunWithOptions:
+			p.closeSection(sections.RunHistory)
+			return p, p.openSection(sections.RunOptions, teamsg.RunOptionsMsg(p.selectedPipeline))
+		case sections.Options.RunHistory:
+			p.closeSection(sections.RunOptions)
+			return p, p.openSection(sections.RunHistory, teamsg.RunHistoryMsg(p.selectedPipeline))
+		}
+	case teamsg.PipelinesUnavailableMsg:
+		emptyState := p.noPipelinesState(msg.PipelineYamls)
+		if msg.Err != nil {
+			emptyState = teamsg.EmptyStateMsg{
+				Title:   "Couldn't load pipelines",
+				Err:     msg.Err,
+				Actions: []listitems.OptionName{sections.Options.Retry},
+			}
+		}
+		return p, p.showEmptyState(emptyState)
+	case teamsg.EmptyStateActionMsg:
+		if emptyState, ok := p.sections[sections.PipelinesEmptyState]; !ok || !emptyState.IsFocused() {
+			return p, nil
+		}
+		switch listitems.OptionName(msg) {
+		case sections.Options.Retry:
+			p.closeSection(sections.PipelinesEmptyState)
+			return p, refreshPipelines
+		case sections.Options.CreatePipeline:
+			return p, p.openSection(sections.CreatePipeline, teamsg.CreatePipelineMsg{})
+		}
+		return p, nil
+	case teamsg.CreatePipelineMsg:
+		p.closeSection(sections.RunOptions)
+		p.closeSection(sections.RunHistory)
+		return p, p.openSection(sections.CreatePipeline, msg)
+	case teamsg.PipelineCreatedMsg:
+		if msg.Err == nil {
+			// land on the list, which shows the new pipeline right away
+			p.logger.Info("pipeline created", "pipelineId", msg.PipelineId)
+			p.closeSection(sections.CreatePipeline)
+			p.closeSection(sections.PipelinesEmptyState)
+			cmds = append(cmds, refreshPipelines)
+		}
+	case teamsg.PipelineRunIdMsg:
+		p.closeSection(sections.RunOptions)
+		p.closeSection(sections.RunHistory)
 	case teamsg.PipelineSelectedMsg:
 		p.selectedPipeline = listitems.PipelineItem(msg)
 		if _, ok := p.sections[sections.PipelineActionChoice]; ok {
@@ -135,6 +200,8 @@ func (p *PipelineListPage) Update(msg tea.Msg) (PageInterface, tea.Cmd) {
 		options := []list.Item{
 			listitems.ChoiceItem{Option: sections.Options.GoToTasks},
 			listitems.ChoiceItem{Option: sections.Options.RunPipeline},
+			listitems.ChoiceItem{Option: sections.Options.RunWithOptions},
+			listitems.ChoiceItem{Option: sections.Options.RunHistory},
 		}
 		sec, cmd := p.sections[sections.PipelineActionChoice].Update(teamsg.OptionsMsg(options))
 		cmds = append(cmds, cmd)
@@ -146,6 +213,80 @@ func (p *PipelineListPage) Update(msg tea.Msg) (PageInterface, tea.Cmd) {
 	return p, tea.Batch(cmds...)
 }
 
+// openSection shows the run options form or run history next to the list and loads the selected pipeline with msg
+func (p *PipelineListPage) openSection(secid sections.SectionName, msg tea.Msg) tea.Cmd {
+	if !p.hasSection(secid) {
+		switch secid {
+		case sections.RunOptions:
+			p.AddSection(sections.NewRunOptions(p.ctx, secid, p.pipelinesclient, p.azdoconfig))
+		case sections.RunHistory:
+			p.AddSection(sections.NewRunHistory(p.ctx, secid, p.buildclient))
+		case sections.PipelinesEmptyState:
+			p.AddSection(sections.NewEmptyState(secid))
+		case sections.CreatePipeline:
+			p.AddSection(sections.NewCreatePipeline(p.ctx, secid, p.pipelinesclient, p.azdoconfig))
+		}
+	}
+	p.mainSection().Blur()
+	p.sections[secid].Focus()
+	sec, cmd := p.sections[secid].Update(msg)
+	p.sections[secid] = sec
+	return cmd
+}
+
+// showEmptyState takes the place of the pipeline list and whatever is open next to it
+func (p *PipelineListPage) showEmptyState(msg teamsg.EmptyStateMsg) tea.Cmd {
+	for _, secid := range p.orderedSections {
+		if secid != sections.PipelinesEmptyState {
+			p.sections[secid].Hide()
+		}
+	}
+	return p.openSection(sections.PipelinesEmptyState, msg)
+}
+
+// noPipelinesState offers to create a pipeline when the repository has a pipeline YAML file but no pipeline
+func (p *PipelineListPage) noPipelinesState(files []string) teamsg.EmptyStateMsg {
+	msg := teamsg.EmptyStateMsg{
+		Title:   "No pipelines",
+		Message: fmt.Sprintf("%s has no pipelines yet.", p.azdoconfig.RepositoryName),
+		Actions: []listitems.OptionName{sections.Options.Retry},
+	}
+	if len(files) > 0 {
+		msg.Message += fmt.Sprintf(" Create one from %s?", files[0])
+		msg.Actions = append([]listitems.OptionName{sections.Options.CreatePipeline}, msg.Actions...)
+	}
+	return msg
+}
+
+func refreshPipelines() tea.Msg {
+	return teamsg.RefreshPipelinesMsg{}
+}
+
+func (p *PipelineListPage) closeSection(secid sections.SectionName) {
+	if !p.hasSection(secid) || p.sections[secid].IsHidden() {
+		return
+	}
+	p.sections[secid].Hide()
+	p.mainSection().Focus()
+}
+
+// mainSection is the pipeline list, or the empty state taking its place
+func (p *PipelineListPage) mainSection() sections.Section {
+	if emptyState, ok := p.sections[sections.PipelinesEmptyState]; ok && !emptyState.IsHidden() {
+		return emptyState
+	}
+	return p.sections[sections.PipelineList]
+}
+
+func (p *PipelineListPage) focusedSection() sections.Section {
+	for _, secid := range p.orderedSections {
+		if p.sections[secid].IsFocused() {
+			return p.sections[secid]
+		}
+	}
+	return nil
+}
+
 func (p *PipelineListPage) View() string {
 	var view string
 	for _, section := range p.orderedSections {
@@ -57,8 +57,8 @@ func (p *PipelineRunPage) AddSection(section sections.Section) {
 	p.sections[secid] = section
 }
 
-func NewPipelineRunPage(ctx context.Context, buildclient azdo.BuildClientInterface, azdoconfig azdo.Config) PageInterface {
-	logger := logger.NewLogger("pipelinerun.log")
+func NewPipelineRunPage(ctx context.Context, buildclient azdo.BuildClientInterface, approvalsclient azdo.ApprovalsClientInterface, azdoconfig azdo.Config, authProvider azdo.AuthProvider) PageInterface {
+	logger := logger.NewLogger("pipelinerun")
 	hk := helpKeys{}
 	helpstring := bubbleshelp.New().View(hk)
 
@@ -71,9 +71,9 @@ func NewPipelineRunPage(ctx context.Context, buildclient azdo.BuildClientInterfa
 		shorthelp: helpstring,
 		logger:    logger,
 	}
-	pipetaskssec := sections.NewPipelineTasks(ctxWithCancel, sections.PipelineTasks, buildclient)
+	pipetaskssec := sections.NewPipelineTasks(ctxWithCancel, sections.PipelineTasks, buildclient, approvalsclient)
 	pipelineRunPage.AddSection(pipetaskssec)
-	logvpsec := sections.NewLogViewport(ctxWithCancel, sections.LogViewport, azdoconfig)
+	logvpsec := sections.NewLogViewport(ctxWithCancel, sections.LogViewport, buildclient, azdoconfig, authProvider)
 	pipelineRunPage.AddSection(logvpsec)
 	pipelineRunPage.sections[sections.LogViewport].Blur()
 	pipelineRunPage.sections[sections.PipelineTasks].Focus()
@@ -112,6 +112,12 @@ func (p *PipelineRunPage) Update(msg tea.Msg) (PageInterface, tea.Cmd) {
 	var cmds []tea.Cmd
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
+		// while a section reads text, keys like tab or f belong to it
+		if capturer, ok := p.sections[sections.PipelineTasks].(inputCapturer); ok && capturer.CapturingInput() {
+			sec, cmd := p.sections[sections.PipelineTasks].Update(msg)
+			p.sections[sections.PipelineTasks] = sec
+			return p, cmd
+		}
 		switch msg.String() {
 		case "alt+m":
 			if p.sectionMaximized == nil {
@@ -136,6 +142,10 @@ func (p *PipelineRunPage) Update(msg tea.Msg) (PageInterface, tea.Cmd) {
 	return p, tea.Batch(cmds...)
 }
 
+type inputCapturer interface {
+	CapturingInput() bool
+}
+
 func toggleMaximize() tea.Cmd {
 	return func() tea.Msg {
 		return teamsg.ToggleMaximizeMsg{}
@@ -0,0 +1,214 @@
+package pages
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/logger"
+	"azdoext/pkg/sections"
+	"azdoext/pkg/styles"
+	"azdoext/pkg/teamsg"
+	"context"
+
+	bubbleshelp "charm.land/bubbles/v2/help"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// PullRequestsPage lists the pull requests of the repository and shows the selected one next to the list,
+// its details above the status of its policies or the form that completes it
+type PullRequestsPage struct {
+	logger          *logger.Logger
+	current         bool
+	ctx             context.Context
+	gitclient       azdo.GitClientInterface
+	policyclient    azdo.PolicyClientInterface
+	azdoconfig      azdo.Config
+	sections        map[sections.SectionName]sections.Section
+	orderedSections []sections.SectionName
+	shorthelp       string
+}
+
+func (p *PullRequestsPage) IsCurrentPage() bool {
+	return p.current
+}
+
+func (p *PullRequestsPage) SetAsCurrentPage() {
+	p.current = true
+}
+
+func (p *PullRequestsPage) UnsetCurrentPage() {
+	p.current = false
+}
+
+func (p *PullRequestsPage) hasSection(section sections.SectionName) bool {
+	_, ok := p.sections[section]
+	return ok
+}
+
+func (p *PullRequestsPage) AddSection(section sections.Section) {
+	secid := section.GetSectionIdentifier()
+	if p.hasSection(secid) {
+		return
+	}
+	if p.sections == nil {
+		p.sections = make(map[sections.SectionName]sections.Section)
+	}
+	for _, sec := range p.orderedSections {
+		p.sections[sec].Blur()
+	}
+	section.SetDimensions(0, sectionHeight(secid, styles.Height))
+	section.Show()
+	section.Focus()
+	p.orderedSections = append(p.orderedSections, secid)
+	p.sections[secid] = section
+}
+
+func NewPullRequestsPage(ctx context.Context, gitclient azdo.GitClientInterface, policyclient azdo.PolicyClientInterface, azdoconfig azdo.Config) PageInterface {
+	hk := helpKeys{}
+	helpstring := bubbleshelp.New().View(hk)
+	logger := logger.NewLogger("pullrequestspage")
+
+	prpage := &PullRequestsPage{
+		logger:       logger,
+		ctx:          ctx,
+		gitclient:    gitclient,
+		policyclient: policyclient,
+		azdoconfig:   azdoconfig,
+		shorthelp:    helpstring,
+	}
+	prpage.AddSection(sections.NewPullRequestList(ctx, sections.PullRequestList, gitclient, policyclient, azdoconfig))
+	return prpage
+}
+
+func (p *PullRequestsPage) GetPageName() PageName {
+	return PullRequests
+}
+
+// sectionHeight splits the height of the page between the details and the status of the selected pull request
+func sectionHeight(section sections.SectionName, height int) int {
+	switch section {
+	case sections.PullRequestDetail:
+		return max(height-sections.PRStatusHeight-1, 0)
+	case sections.PullRequestStatus, sections.PullRequestCompletion:
+		return sections.PRStatusHeight
+	}
+	return height
+}
+
+func (p *PullRequestsPage) SetDimensions(width, height int) {
+	for s := range p.sections {
+		p.sections[s].SetDimensions(width, sectionHeight(s, height))
+	}
+}
+
+func (p *PullRequestsPage) updateSections(msg tea.Msg) []tea.Cmd {
+	var cmds []tea.Cmd
+	for _, section := range p.orderedSections {
+		sec, cmd := p.sections[section].Update(msg)
+		p.sections[section] = sec
+		cmds = append(cmds, cmd)
+	}
+	return cmds
+}
+
+func (p *PullRequestsPage) Update(msg tea.Msg) (PageInterface, tea.Cmd) {
+	var cmds []tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		if !p.current {
+			return p, nil
+		}
+		// while the list is searched, tab and esc belong to it
+		if capturer, ok := p.sections[sections.PullRequestList].(inputCapturer); ok && capturer.CapturingInput() {
+			return p, tea.Batch(p.updateSections(msg)...)
+		}
+		// the completion form takes every key but esc, which goes back to the status
+		if completion, ok := p.sections[sections.PullRequestCompletion]; ok && completion.IsFocused() {
+			if msg.String() == "esc" {
+				completion.Hide()
+				p.sections[sections.PullRequestStatus].Focus()
+				return p, nil
+			}
+			return p, tea.Batch(p.updateSections(msg)...)
+		}
+		switch msg.String() {
+		case "tab":
+			p.switchSection()
+			return p, nil
+		case "esc":
+			if !p.sections[sections.PullRequestList].IsFocused() && p.hasSection(sections.PullRequestDetail) {
+				p.sections[sections.PullRequestDetail].Hide()
+				p.sections[sections.PullRequestStatus].Hide()
+				p.sections[sections.PullRequestCompletion].Hide()
+				p.sections[sections.PullRequestList].Focus()
+				return p, nil
+			}
+		case "c":
+			// the form replaces the status of the pull request shown
+			if !p.sections[sections.PullRequestList].IsFocused() && p.hasSection(sections.PullRequestDetail) {
+				p.sections[sections.PullRequestDetail].Blur()
+				p.sections[sections.PullRequestStatus].Hide()
+				p.sections[sections.PullRequestCompletion].Focus()
+				return p, nil
+			}
+		}
+	case teamsg.GitPRCreatedMsg:
+		// the pull request just opened is shown right away, its policies start being evaluated
+		return p, func() tea.Msg { return teamsg.PullRequestSelectedMsg(msg) }
+	case teamsg.PullRequestSelectedMsg:
+		if !p.hasSection(sections.PullRequestDetail) {
+			p.AddSection(sections.NewPullRequestDetail(sections.PullRequestDetail))
+			p.AddSection(sections.NewPullRequestStatus(p.ctx, sections.PullRequestStatus, p.policyclient))
+			p.AddSection(sections.NewPullRequestCompletion(p.ctx, sections.PullRequestCompletion, p.gitclient, p.azdoconfig))
+		}
+		p.sections[sections.PullRequestList].Blur()
+		p.sections[sections.PullRequestDetail].Blur()
+		p.sections[sections.PullRequestDetail].Show()
+		p.sections[sections.PullRequestCompletion].Hide()
+		p.sections[sections.PullRequestStatus].Focus()
+	case teamsg.PullRequestUpdatedMsg:
+		if msg.Err == nil {
+			// the pull request is shown as Azure DevOps left it and the list catches up
+			cmds = append(cmds,
+				func() tea.Msg { return teamsg.PullRequestSelectedMsg(msg.PullRequest) },
+				func() tea.Msg { return teamsg.OpenPullRequestsMsg{} },
+			)
+		}
+	}
+	cmds = append(cmds, p.updateSections(msg)...)
+	return p, tea.Batch(cmds...)
+}
+
+func (p *PullRequestsPage) View() string {
+	view := p.sections[sections.PullRequestList].View()
+	var selected []string
+	for _, section := range []sections.SectionName{sections.PullRequestDetail, sections.PullRequestStatus, sections.PullRequestCompletion} {
+		if p.hasSection(section) && !p.sections[section].IsHidden() {
+			selected = append(selected, p.sections[section].View())
+		}
+	}
+	if len(selected) > 0 {
+		view = attachView(view, lipgloss.JoinVertical(lipgloss.Left, selected...))
+	}
+	return lipgloss.JoinVertical(lipgloss.Top, view, p.shorthelp)
+}
+
+func (p *PullRequestsPage) switchSection() {
+	shownSections := []sections.SectionName{}
+	for _, section := range p.orderedSections {
+		if !p.sections[section].IsHidden() {
+			shownSections = append(shownSections, section)
+		}
+	}
+	for i, sec := range shownSections {
+		section := p.sections[sec]
+		if section.IsFocused() {
+			section.Blur()
+			nextKey := shownSections[0] // default to the first key
+			if i+1 < len(shownSections) {
+				nextKey = shownSections[i+1] // if there's a next key, use it
+			}
+			p.sections[nextKey].Focus()
+			return
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"azdoext/pkg/logger"
 	"azdoext/pkg/styles"
 	"azdoext/pkg/teamsg"
-	"fmt"
 
 	"charm.land/bubbles/v2/list"
 	tea "charm.land/bubbletea/v2"
@@ -21,7 +20,7 @@ type Choice struct {
 }
 
 func NewChoice(secid SectionName) Section {
-	logger := logger.NewLogger("choice.log")
+	logger := logger.NewLogger("choice")
 
 	choice := list.New([]list.Item{}, listitems.ChoiceItemDelegate{}, 0, 0)
 	choice.Title = "PR or pipelines:"
@@ -59,7 +58,7 @@ func (c *Choice) Update(msg tea.Msg) (Section, tea.Cmd) {
 			case "q":
 				return c, nil
 			case "enter":
-				c.logger.LogToFile("debug", fmt.Sprintf("submiting choice [%s]", c.choice.SelectedItem().(listitems.ChoiceItem).Option))
+				c.logger.Debug("submiting choice", "choice", c.choice.SelectedItem().(listitems.ChoiceItem).Option)
 				return c, func() tea.Msg { return teamsg.SubmitChoiceMsg(c.choice.SelectedItem().(listitems.ChoiceItem).Option) }
 			}
 		case teamsg.OptionsMsg:
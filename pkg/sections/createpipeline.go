@@ -0,0 +1,340 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/gitexec"
+	"azdoext/pkg/logger"
+	"azdoext/pkg/styles"
+	"azdoext/pkg/teamsg"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/google/uuid"
+)
+
+// the fields of the create pipeline form, in order
+const (
+	yamlFileField = iota
+	nameField
+	folderField
+	createPipelineFields
+)
+
+type CreatePipelineSection struct {
+	logger            *logger.Logger
+	hidden            bool
+	focused           bool
+	ctx               context.Context
+	client            azdo.PipelinesClientInterface
+	sectionIdentifier SectionName
+	repositoryName    string
+	repositoryId      uuid.UUID
+	files             []string
+	selected          int
+	nameInput         textinput.Model
+	folderInput       textinput.Model
+	// nameEdited stops the name from following the selected file once typed
+	nameEdited   bool
+	cursor       int
+	loading      bool
+	creating     bool
+	errorMessage string
+	help         string
+}
+
+func NewCreatePipeline(ctx context.Context, secid SectionName, client azdo.PipelinesClientInterface, azdoconfig azdo.Config) Section {
+	logger := logger.NewLogger("createpipeline")
+	newInput := func() textinput.Model {
+		input := textinput.New()
+		input.Prompt = ""
+		input.SetWidth(styles.DefaultSectionWidth)
+		return input
+	}
+	help := styles.ShortHelpStyle.Render("↑/↓ move • ←/→ change file • ctrl+s create • esc cancel")
+	return &CreatePipelineSection{
+		logger:            logger,
+		ctx:               ctx,
+		client:            client,
+		sectionIdentifier: secid,
+		repositoryName:    azdoconfig.RepositoryName,
+		repositoryId:      azdoconfig.RepositoryId,
+		nameInput:         newInput(),
+		folderInput:       newInput(),
+		help:              help,
+	}
+}
+
+func (c *CreatePipelineSection) GetSectionIdentifier() SectionName {
+	return c.sectionIdentifier
+}
+
+func (c *CreatePipelineSection) IsHidden() bool {
+	return c.hidden
+}
+
+func (c *CreatePipelineSection) IsFocused() bool {
+	return c.focused
+}
+
+func (c *CreatePipelineSection) Hide() {
+	c.hidden = true
+	c.focused = false
+}
+
+func (c *CreatePipelineSection) Show() {
+	c.hidden = false
+}
+
+func (c *CreatePipelineSection) Focus() {
+	c.Show()
+	c.focused = true
+	c.focusCursor()
+}
+
+func (c *CreatePipelineSection) Blur() {
+	c.focused = false
+	c.nameInput.Blur()
+	c.folderInput.Blur()
+}
+
+func (c *CreatePipelineSection) SetDimensions(width, height int) {
+	c.nameInput.SetWidth(styles.DefaultSectionWidth)
+	c.folderInput.SetWidth(styles.DefaultSectionWidth)
+}
+
+func (c *CreatePipelineSection) focusCursor() {
+	c.nameInput.Blur()
+	c.folderInput.Blur()
+	switch c.cursor {
+	case nameField:
+		c.nameInput.Focus()
+	case folderField:
+		c.folderInput.Focus()
+	}
+}
+
+// FindPipelineYamls returns the YAML pipelines of the worktree relative to its root, azure-pipelines.yml files first
+func FindPipelineYamls() ([]string, error) {
+	root, err := gitexec.TopLevel()
+	if err != nil {
+		return nil, err
+	}
+	files, err := gitexec.ListFiles("*.yml", "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	var pipelineYamls []string
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(root, file))
+		if err != nil {
+			// e.g. deleted but not staged yet
+			continue
+		}
+		if azdo.IsPipelineYaml(content) {
+			pipelineYamls = append(pipelineYamls, file)
+		}
+	}
+	slices.SortStableFunc(pipelineYamls, func(a, b string) int {
+		switch aDefault, bDefault := isDefaultPipelineYaml(a), isDefaultPipelineYaml(b); {
+		case aDefault && !bDefault:
+			return -1
+		case bDefault && !aDefault:
+			return 1
+		}
+		return 0
+	})
+	return pipelineYamls, nil
+}
+
+func isDefaultPipelineYaml(file string) bool {
+	return strings.TrimSuffix(path.Base(file), path.Ext(file)) == "azure-pipelines"
+}
+
+// defaultPipelineName names the pipeline after the repository, like Azure DevOps does, and after the file
+// unless it's an azure-pipelines.yml
+func defaultPipelineName(repositoryName, file string) string {
+	if file == "" || isDefaultPipelineYaml(file) {
+		return repositoryName
+	}
+	return repositoryName + "-" + strings.TrimSuffix(path.Base(file), path.Ext(file))
+}
+
+// pipelineFolder turns e.g. team/ci into \team\ci, which is how Azure DevOps writes folders
+func pipelineFolder(folder string) string {
+	folder = strings.ReplaceAll(strings.TrimSpace(folder), "/", `\`)
+	return `\` + strings.Trim(folder, `\`)
+}
+
+func (c *CreatePipelineSection) selectedFile() string {
+	if c.selected < len(c.files) {
+		return c.files[c.selected]
+	}
+	return ""
+}
+
+func (c *CreatePipelineSection) selectFile(delta int) {
+	if len(c.files) == 0 {
+		return
+	}
+	c.selected = (c.selected + delta + len(c.files)) % len(c.files)
+	if !c.nameEdited {
+		c.nameInput.SetValue(defaultPipelineName(c.repositoryName, c.selectedFile()))
+		c.nameInput.CursorEnd()
+	}
+}
+
+func (c *CreatePipelineSection) findFiles() tea.Cmd {
+	return func() tea.Msg {
+		files, err := FindPipelineYamls()
+		return teamsg.PipelineYamlFilesMsg{Files: files, Err: err}
+	}
+}
+
+func (c *CreatePipelineSection) createArgs() (azdo.CreatePipelineArgs, error) {
+	args := azdo.CreatePipelineArgs{
+		Name:         strings.TrimSpace(c.nameInput.Value()),
+		Folder:       pipelineFolder(c.folderInput.Value()),
+		YamlPath:     c.selectedFile(),
+		RepositoryId: c.repositoryId,
+	}
+	if args.YamlPath == "" {
+		return args, errors.New("pick a YAML file")
+	}
+	if args.Name == "" {
+		return args, errors.New("the pipeline needs a name")
+	}
+	return args, nil
+}
+
+func (c *CreatePipelineSection) create() tea.Cmd {
+	args, err := c.createArgs()
+	if err != nil {
+		c.errorMessage = err.Error()
+		return nil
+	}
+	c.creating = true
+	c.errorMessage = ""
+	return func() tea.Msg {
+		pipelineId, err := c.client.CreatePipeline(c.ctx, args)
+		return teamsg.PipelineCreatedMsg{PipelineId: pipelineId, Err: err}
+	}
+}
+
+func (c *CreatePipelineSection) Update(msg tea.Msg) (Section, tea.Cmd) {
+	switch msg := msg.(type) {
+	case teamsg.CreatePipelineMsg:
+		c.files, c.selected, c.cursor = nil, 0, yamlFileField
+		c.nameEdited, c.loading, c.creating = false, true, false
+		c.errorMessage = ""
+		c.nameInput.SetValue("")
+		c.folderInput.SetValue(`\`)
+		c.focusCursor()
+		return c, c.findFiles()
+	case teamsg.PipelineYamlFilesMsg:
+		c.loading = false
+		if msg.Err != nil {
+			c.logger.Error("error looking for pipeline yaml files", "error", msg.Err)
+			c.errorMessage = msg.Err.Error()
+			return c, nil
+		}
+		c.files = msg.Files
+		if len(c.files) == 0 {
+			c.errorMessage = "No pipeline YAML file found in the worktree"
+		}
+		c.selectFile(0)
+		return c, nil
+	case teamsg.PipelineCreatedMsg:
+		c.creating = false
+		if msg.Err != nil {
+			c.logger.Error("error creating pipeline", "error", msg.Err)
+			c.errorMessage = msg.Err.Error()
+		}
+		return c, nil
+	case tea.KeyPressMsg:
+		if !c.focused {
+			return c, nil
+		}
+		switch msg.String() {
+		case "up", "shift+tab":
+			c.cursor = (c.cursor - 1 + createPipelineFields) % createPipelineFields
+			c.focusCursor()
+			return c, nil
+		case "down", "enter":
+			c.cursor = (c.cursor + 1) % createPipelineFields
+			c.focusCursor()
+			return c, nil
+		case "ctrl+s":
+			if c.loading || c.creating {
+				return c, nil
+			}
+			return c, c.create()
+		}
+		var cmd tea.Cmd
+		switch c.cursor {
+		case yamlFileField:
+			switch msg.String() {
+			case "left":
+				c.selectFile(-1)
+			case "right", "space":
+				c.selectFile(1)
+			}
+		case nameField:
+			before := c.nameInput.Value()
+			c.nameInput, cmd = c.nameInput.Update(msg)
+			c.nameEdited = c.nameEdited || c.nameInput.Value() != before
+		case folderField:
+			c.folderInput, cmd = c.folderInput.Update(msg)
+		}
+		return c, cmd
+	}
+	return c, nil
+}
+
+func (c *CreatePipelineSection) View() string {
+	title := styles.TitleStyle.Render("New pipeline")
+	labelStyle := lipgloss.NewStyle().Bold(true)
+	label := func(field int, text string) string {
+		if c.focused && c.cursor == field {
+			return labelStyle.Foreground(styles.Yellow).Render(text)
+		}
+		return labelStyle.Render(text)
+	}
+	file := "none"
+	if len(c.files) > 0 {
+		file = fmt.Sprintf("‹ %s › (%d/%d)", c.selectedFile(), c.selected+1, len(c.files))
+	}
+	lines := []string{
+		title,
+		"",
+		label(yamlFileField, "YAML file"), file,
+		label(nameField, "Name"), c.nameInput.View(),
+		label(folderField, "Folder"), c.folderInput.View(),
+		"",
+		lipgloss.NewStyle().Foreground(styles.Grey).Width(styles.DefaultSectionWidth).Render("The file has to be pushed for Azure DevOps to find it"),
+	}
+	switch {
+	case c.loading:
+		lines = append(lines, "", "Looking for pipeline YAML files...")
+	case c.creating:
+		lines = append(lines, "", "Creating pipeline...")
+	}
+	if c.errorMessage != "" {
+		errorStyle := lipgloss.NewStyle().Foreground(styles.Red).Width(styles.DefaultSectionWidth + 20)
+		lines = append(lines, "", errorStyle.Render(c.errorMessage))
+	}
+	lines = append(lines, "", c.help)
+	secView := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	if c.focused {
+		return styles.ActiveStyle.Render(secView)
+	}
+	return styles.InactiveStyle.Render(secView)
+}
@@ -0,0 +1,51 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/teamsg"
+	"context"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/google/uuid"
+)
+
+func TestPipelineFolder(t *testing.T) {
+	for folder, want := range map[string]string{
+		"":            `\`,
+		`\`:           `\`,
+		"team/ci":     `\team\ci`,
+		` \team\ci\ `: `\team\ci`,
+	} {
+		if got := pipelineFolder(folder); got != want {
+			t.Errorf("pipelineFolder(%q) = %q, want %q", folder, got, want)
+		}
+	}
+}
+
+func TestCreatePipelineArgs(t *testing.T) {
+	repositoryId := uuid.New()
+	section := NewCreatePipeline(context.Background(), CreatePipeline, nil, azdo.Config{RepositoryName: "azdoext", RepositoryId: repositoryId}).(*CreatePipelineSection)
+	section.Focus()
+	section.Update(teamsg.CreatePipelineMsg{})
+	section.Update(teamsg.PipelineYamlFilesMsg{Files: []string{"azure-pipelines.yml", "ci/release.yml"}})
+	if got := section.nameInput.Value(); got != "azdoext" {
+		t.Errorf("expected the repository name, got %q", got)
+	}
+	section.Update(tea.KeyPressMsg{Code: tea.KeyRight})
+	if got := section.nameInput.Value(); got != "azdoext-release" {
+		t.Errorf("expected the name to follow the file, got %q", got)
+	}
+	section.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	section.Update(tea.KeyPressMsg{Code: 'x', Text: "x"})
+	section.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+	section.Update(tea.KeyPressMsg{Code: tea.KeyLeft})
+	args, err := section.createArgs()
+	if err != nil {
+		t.Fatalf("createArgs returned error: %v", err)
+	}
+	want := azdo.CreatePipelineArgs{Name: "azdoext-releasex", Folder: `\`, YamlPath: "azure-pipelines.yml", RepositoryId: repositoryId}
+	if args != want {
+		t.Errorf("createArgs() = %+v, want %+v", args, want)
+	}
+}
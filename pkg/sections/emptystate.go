@@ -0,0 +1,129 @@
+package sections
+
+import (
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/logger"
+	"azdoext/pkg/styles"
+	"azdoext/pkg/teamsg"
+
+	"charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// EmptyState takes the place of a section that has nothing to show, because there is nothing yet or fetching it failed,
+// and offers actions to get out of it. Its content is set with EmptyStateMsg
+type EmptyState struct {
+	logger            *logger.Logger
+	hidden            bool
+	focused           bool
+	sectionIdentifier SectionName
+	title             string
+	message           string
+	isError           bool
+	actions           list.Model
+	width             int
+}
+
+func NewEmptyState(secid SectionName) Section {
+	logger := logger.NewLogger("emptystate")
+	actions := list.New([]list.Item{}, listitems.ChoiceItemDelegate{}, 0, 0)
+	actions.SetShowTitle(false)
+	actions.SetShowStatusBar(false)
+	actions.SetShowHelp(false)
+	actions.SetShowPagination(false)
+	actions.SetFilteringEnabled(false)
+	actions.DisableQuitKeybindings()
+	return &EmptyState{
+		logger:            logger,
+		sectionIdentifier: secid,
+		actions:           actions,
+		width:             styles.DefaultSectionWidth,
+	}
+}
+
+func (e *EmptyState) GetSectionIdentifier() SectionName {
+	return e.sectionIdentifier
+}
+
+func (e *EmptyState) IsHidden() bool {
+	return e.hidden
+}
+
+func (e *EmptyState) IsFocused() bool {
+	return e.focused
+}
+
+func (e *EmptyState) Hide() {
+	e.hidden = true
+	e.focused = false
+}
+
+func (e *EmptyState) Show() {
+	e.hidden = false
+}
+
+func (e *EmptyState) Focus() {
+	e.Show()
+	e.focused = true
+}
+
+func (e *EmptyState) Blur() {
+	e.focused = false
+}
+
+func (e *EmptyState) SetDimensions(width, height int) {
+	if width == 0 {
+		width = styles.DefaultSectionWidth
+	}
+	e.width = width
+	e.actions.SetWidth(width)
+	// title, message and the blank lines around it
+	e.actions.SetHeight(max(height-5, 1))
+}
+
+func (e *EmptyState) Update(msg tea.Msg) (Section, tea.Cmd) {
+	switch msg := msg.(type) {
+	case teamsg.EmptyStateMsg:
+		e.title = msg.Title
+		e.message, e.isError = msg.Message, msg.Err != nil
+		if msg.Err != nil {
+			e.message = msg.Err.Error()
+		}
+		items := make([]list.Item, 0, len(msg.Actions))
+		for _, action := range msg.Actions {
+			items = append(items, listitems.ChoiceItem{Option: action})
+		}
+		e.actions.Select(0)
+		return e, e.actions.SetItems(items)
+	case tea.KeyPressMsg:
+		if !e.focused {
+			return e, nil
+		}
+		if msg.String() == "enter" {
+			action, ok := e.actions.SelectedItem().(listitems.ChoiceItem)
+			if !ok {
+				return e, nil
+			}
+			e.logger.Debug("submitting action", "action", action.Option)
+			return e, func() tea.Msg { return teamsg.EmptyStateActionMsg(action.Option) }
+		}
+		actions, cmd := e.actions.Update(msg)
+		e.actions = actions
+		return e, cmd
+	}
+	return e, nil
+}
+
+func (e *EmptyState) View() string {
+	title := styles.TitleStyle.Render(e.title)
+	messageStyle := lipgloss.NewStyle().Width(e.width).Padding(1, 0)
+	if e.isError {
+		messageStyle = messageStyle.Foreground(styles.Red)
+	}
+	secView := lipgloss.JoinVertical(lipgloss.Left, title, messageStyle.Render(e.message), e.actions.View())
+	if e.focused {
+		return styles.ActiveStyle.Render(secView)
+	}
+	return styles.InactiveStyle.Render(secView)
+}
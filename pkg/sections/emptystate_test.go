@@ -0,0 +1,48 @@
+package sections
+
+import (
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/teamsg"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestEmptyStateSubmitsSelectedAction(t *testing.T) {
+	section := NewEmptyState(PipelinesEmptyState)
+	section.SetDimensions(0, 20)
+	section.Focus()
+	section, _ = section.Update(teamsg.EmptyStateMsg{
+		Title:   "No pipelines",
+		Message: "repo has no pipelines yet.",
+		Actions: []listitems.OptionName{Options.CreatePipeline, Options.Retry},
+	})
+	section, _ = section.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	_, cmd := section.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected enter to submit an action")
+	}
+	if got := cmd(); got != teamsg.EmptyStateActionMsg(Options.Retry) {
+		t.Errorf("expected retry to be submitted, got %v", got)
+	}
+}
+
+func TestEmptyStateShowsError(t *testing.T) {
+	section := NewEmptyState(PipelinesEmptyState)
+	section.SetDimensions(0, 20)
+	section, _ = section.Update(teamsg.EmptyStateMsg{
+		Title:   "Couldn't load pipelines",
+		Message: "ignored",
+		Err:     errors.New("failed to get build definitions: unauthorized"),
+		Actions: []listitems.OptionName{Options.Retry},
+	})
+	view := section.View()
+	if !strings.Contains(view, "unauthorized") || strings.Contains(view, "ignored") {
+		t.Errorf("expected the error instead of the message, got %q", view)
+	}
+	if _, cmd := section.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); cmd != nil {
+		t.Error("expected keys to be ignored while blurred")
+	}
+}
@@ -35,6 +35,7 @@ type LogViewportSection struct {
 	azdoConfig        azdo.Config
 	buildclient       azdo.BuildClientInterface
 	buildStatus       string
+	approvals         map[uuid.UUID]azdo.Approval
 
 	// this map stores task logs with log id and log content
 	buildLogs utils.Logs
@@ -56,17 +57,16 @@ type LogViewportSection struct {
 	signalrClient *azdosignalr.SignalRClient
 }
 
-func NewLogViewport(ctx context.Context, secid SectionName, azdoconfig azdo.Config) Section {
-	logger := logger.NewLogger("logviewport.log")
+func NewLogViewport(ctx context.Context, secid SectionName, buildclient azdo.BuildClientInterface, azdoconfig azdo.Config, authProvider azdo.AuthProvider) Section {
+	logger := logger.NewLogger("logviewport")
 	vp := viewsearch.New()
 	vp.SetShowHelp(false)
 
-	signalrClient := azdosignalr.NewSignalR(azdoconfig.OrgName, azdoconfig.AccoundId, azdoconfig.ProjectId, azdoconfig.AuthHeader)
+	signalrClient := azdosignalr.NewSignalR(azdoconfig.OrgUrl, azdoconfig.AccoundId, azdoconfig.ProjectId, authProvider)
 
 	connClosedChan := make(chan bool)
 	connClosedErrChan := make(chan error)
 
-	buildclient := azdo.NewBuildClient(ctx, azdoconfig.OrgUrl, azdoconfig.ProjectId, azdoconfig.AuthHeader)
 	return &LogViewportSection{
 		logger:            logger,
 		logviewport:       &vp,
@@ -158,6 +158,16 @@ func (p *LogViewportSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 		p.cancelReceiveLogs = cancel
 		p.startMonitoringLogs(ctx, msg.RunId, p.connClosedChan, p.connClosedErrChan)
 		return p, waitForLogs(p.logsChan)
+	case teamsg.RunActionMsg:
+		// retried jobs of a completed run only show up while streaming, so start streaming again if it was loaded complete
+		if !msg.Retried || msg.Err != nil || msg.RunId != p.currentRunId || p.cancelReceiveLogs != nil {
+			return p, nil
+		}
+		ctx, cancel := context.WithCancel(p.ctx)
+		p.readLogsCtx = ctx
+		p.cancelReceiveLogs = cancel
+		p.startMonitoringLogs(ctx, msg.RunId, p.connClosedChan, p.connClosedErrChan)
+		return p, waitForLogs(p.logsChan)
 	case teamsg.LogMsg:
 		currentLog, ok := p.buildLogs[msg.StepRecordId]
 		if !ok {
@@ -176,10 +186,22 @@ func (p *LogViewportSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 			p.logviewport.GotoBottom()
 		}
 		return p, waitForLogs(p.logsChan)
+	case teamsg.PipelineRunStateMsg:
+		p.approvals = msg.Approvals
+		if approval, ok := p.approvals[p.currentStep]; ok {
+			p.logviewport.SetContent(formatApproval(approval))
+		}
+		return p, nil
 	case teamsg.RecordSelectedMsg:
+		p.currentStep = msg.RecordId
+		// approvals have no logs, show who must approve instead
+		if approval, ok := p.approvals[msg.RecordId]; ok {
+			p.logviewport.SetContent(formatApproval(approval))
+			p.logviewport.Viewport.GotoTop()
+			return p, nil
+		}
 		p.logviewport.SetContent(p.buildLogs[msg.RecordId])
 		p.logviewport.GotoBottom()
-		p.currentStep = msg.RecordId
 		return p, nil
 	case tea.KeyPressMsg:
 		if msg.String() == "alt+w" {
@@ -200,7 +222,7 @@ func (p *LogViewportSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 }
 
 func (p *LogViewportSection) startMonitoringLogs(ctx context.Context, runId int, connClosedChan chan bool, connClosedErrChan chan error) {
-	p.logger.LogToFile("INFO", "connecting to signalr")
+	p.logger.Info("connecting to signalr")
 	err := p.signalrClient.Connect()
 	if err != nil {
 		panic(fmt.Sprintf("error connecting to signalr: %v", err))
@@ -221,9 +243,9 @@ func (p *LogViewportSection) cancelReceiveLogsIfExists() {
 		p.cancelReceiveLogs = nil
 		select {
 		case <-p.connClosedChan:
-			p.logger.LogToFile("INFO", "connection closed")
+			p.logger.Info("connection closed")
 		case err := <-p.connClosedErrChan:
-			p.logger.LogToFile("ERROR", fmt.Sprintf("error closing connection: %v", err))
+			p.logger.Error("error closing connection", "error", err)
 			panic(fmt.Sprintf("error closing connection: %v", err))
 		}
 	}
@@ -254,6 +276,28 @@ func (p *LogViewportSection) handleCompletedRun(runId int) {
 	}
 }
 
+func formatApproval(approval azdo.Approval) string {
+	lines := []string{"Approval " + approval.Status}
+	if approval.Instructions != "" {
+		lines = append(lines, "", approval.Instructions)
+	}
+	if approval.MinRequiredApprovers > 0 && approval.MinRequiredApprovers < len(approval.Steps) {
+		lines = append(lines, "", fmt.Sprintf("%d of %d approvers required", approval.MinRequiredApprovers, len(approval.Steps)))
+	}
+	lines = append(lines, "", "Approvers:")
+	for _, step := range approval.Steps {
+		line := fmt.Sprintf("  %-12s %s", step.Status, step.Approver)
+		if step.Comment != "" {
+			line += fmt.Sprintf(" - %q", step.Comment)
+		}
+		lines = append(lines, line)
+	}
+	if approval.IsPending() {
+		lines = append(lines, "", "Press a to approve or x to reject from the task list")
+	}
+	return strings.Join(lines, "\n")
+}
+
 func getLogId(item build.TimelineRecord) *int {
 	if item.Log == nil {
 		return nil
@@ -3,15 +3,23 @@ package sections
 import "azdoext/pkg/listitems"
 
 type OptionsStruct struct {
-	OpenPR        listitems.OptionName
-	GoToPipelines listitems.OptionName
-	RunPipeline   listitems.OptionName
-	GoToTasks     listitems.OptionName
+	OpenPR         listitems.OptionName
+	GoToPipelines  listitems.OptionName
+	RunPipeline    listitems.OptionName
+	GoToTasks      listitems.OptionName
+	RunWithOptions listitems.OptionName
+	RunHistory     listitems.OptionName
+	Retry          listitems.OptionName
+	CreatePipeline listitems.OptionName
 }
 
 var Options = OptionsStruct{
-	OpenPR:        "Open PR",
-	GoToPipelines: "Go to pipelines",
-	RunPipeline:   "Run pipeline",
-	GoToTasks:     "Go to tasks",
+	OpenPR:         "Open PR",
+	GoToPipelines:  "Go to pipelines",
+	RunPipeline:    "Run pipeline",
+	GoToTasks:      "Go to tasks",
+	RunWithOptions: "Run with options",
+	RunHistory:     "Run history",
+	Retry:          "Retry",
+	CreatePipeline: "Create pipeline from YAML file",
 }
@@ -2,15 +2,19 @@ package sections
 
 import (
 	"azdoext/pkg/azdo"
+	"azdoext/pkg/gitexec"
 	"azdoext/pkg/listitems"
 	"azdoext/pkg/logger"
+	"azdoext/pkg/settings"
 	"azdoext/pkg/styles"
 	"azdoext/pkg/teamsg"
 	"azdoext/pkg/utils"
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 
 	"charm.land/bubbles/v2/list"
@@ -21,6 +25,38 @@ import (
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/build"
 )
 
+// runScope limits which run of each pipeline is shown
+type runScope int
+
+const (
+	scopeAllBranches runScope = iota
+	scopeCurrentBranch
+	scopeHeadCommit
+)
+
+// scopedRunsLookup is how many runs of the current branch are searched for the run of the checked out commit
+const scopedRunsLookup = 10
+
+// pipelineSort orders the pipelines of each folder
+type pipelineSort int
+
+const (
+	sortByName pipelineSort = iota
+	sortByLastRun
+	sortByStatus
+	pipelineSorts
+)
+
+var pipelineSortNames = []string{"name", "last run", "status"}
+
+// statusOrder ranks statuses when sorting by status, those missing (e.g. noRuns) come last
+var statusOrder = []string{"inProgress", "notStarted", "failed", "partiallySucceeded", "canceled", "succeeded"}
+
+const (
+	activePollInterval = 5 * time.Second
+	idlePollInterval   = 30 * time.Second
+)
+
 type PipelineListSection struct {
 	project                 string
 	repositoryId            uuid.UUID
@@ -35,10 +71,22 @@ type PipelineListSection struct {
 	spinnerView             *string
 	buildclient             azdo.BuildClientInterface
 	sectionIdentifier       SectionName
+	// errorMessage is shown under the list until the next key press, e.g. when queueing a run fails
+	errorMessage string
+	scope        runScope
+	// fetchLoop identifies the running fetchBuilds loop
+	fetchLoop int
+	// pipelines are the pipelines as last fetched, the list shows them arranged by arrangePipelines
+	pipelines []listitems.PipelineItem
+	favorites map[int]bool
+	sortBy    pipelineSort
+	// extraPipelines are the ids or paths of the pipelines of other repositories to list too
+	extraPipelines []string
+	browseProject  bool
 }
 
 func NewPipelineList(ctx context.Context, secid SectionName, buildclient azdo.BuildClientInterface, azdoconfig azdo.Config) Section {
-	logger := logger.NewLogger("pipelinelist.log")
+	logger := logger.NewLogger("pipelinelist")
 	pipelinelist := list.New([]list.Item{}, listitems.ItemDelegate{}, 40, 0)
 	pipelinelist.Title = "Pipelines"
 
@@ -48,6 +96,19 @@ func NewPipelineList(ctx context.Context, secid SectionName, buildclient azdo.Bu
 	spner.Spinner = spinner.Dot
 	spner.Style = styles.SpinnerStyle
 
+	favorites := make(map[int]bool)
+	favoriteIds, err := settings.LoadFavorites(azdoconfig.RepositoryId.String())
+	if err != nil {
+		logger.Error("error loading favorite pipelines", "error", err)
+	}
+	for _, id := range favoriteIds {
+		favorites[id] = true
+	}
+	s, err := settings.Load()
+	if err != nil {
+		logger.Error("error loading settings, extra pipelines are not listed", "error", err)
+	}
+
 	return &PipelineListSection{
 		logger:            logger,
 		pipelinelist:      pipelinelist,
@@ -59,6 +120,8 @@ func NewPipelineList(ctx context.Context, secid SectionName, buildclient azdo.Bu
 		repositoryId:      azdoconfig.RepositoryId,
 		currentBranch:     azdoconfig.CurrentBranch,
 		sectionIdentifier: secid,
+		favorites:         favorites,
+		extraPipelines:    s.ExtraPipelines[azdoconfig.RepositoryName],
 	}
 }
 
@@ -96,6 +159,10 @@ func (p *PipelineListSection) Blur() {
 func (p *PipelineListSection) View() string {
 	title := styles.TitleStyle.Render(p.pipelinelist.Title)
 	secView := lipgloss.JoinVertical(lipgloss.Top, title, p.pipelinelist.View())
+	if p.errorMessage != "" {
+		errorView := lipgloss.NewStyle().Foreground(styles.Red).Width(p.pipelinelist.Width()).Render(p.errorMessage)
+		secView = lipgloss.JoinVertical(lipgloss.Top, secView, errorView)
+	}
 	if p.focused {
 		return styles.ActiveStyle.Render(secView)
 	}
@@ -106,14 +173,24 @@ func (p *PipelineListSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 	var cmds []tea.Cmd
 	switch msg := msg.(type) {
 	case teamsg.SubmitChoiceMsg:
-		selectedPipeline := p.pipelinelist.SelectedItem().(listitems.PipelineItem)
+		selectedPipeline, ok := p.pipelinelist.SelectedItem().(listitems.PipelineItem)
+		if !ok {
+			return p, nil
+		}
 
 		var runId int
-		if listitems.OptionName(msg) == Options.GoToTasks {
+		switch listitems.OptionName(msg) {
+		case Options.GoToTasks:
 			runId = selectedPipeline.RunId
-		} else if listitems.OptionName(msg) == Options.RunPipeline {
-			runId = p.runPipeline(p.ctx, selectedPipeline, p.project, p.currentBranch)
+		case Options.RunPipeline:
+			var err error
+			runId, err = p.runPipeline(p.ctx, selectedPipeline, p.project, p.currentBranch)
+			if err != nil {
+				return p, func() tea.Msg { return teamsg.RunPipelineErrorMsg(err.Error()) }
+			}
 			selectedPipeline.Status = "notStarted"
+		default:
+			return p, nil
 		}
 		return p, func() tea.Msg {
 			return teamsg.PipelineRunIdMsg{RunId: runId, PipelineName: selectedPipeline.Name, Status: selectedPipeline.Status}
@@ -122,7 +199,35 @@ func (p *PipelineListSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 		if !p.focused {
 			return p, nil
 		}
+		p.errorMessage = ""
+		if p.CapturingInput() {
+			break
+		}
 		switch msg.String() {
+		case "s":
+			p.scope = (p.scope + 1) % 3
+			p.pipelinelist.Title = p.title()
+			p.fetchLoop++
+			return p, p.fetchBuilds(p.ctx, 0)
+		case "p":
+			p.browseProject = !p.browseProject
+			p.pipelinelist.Title = p.title()
+			p.fetchLoop++
+			return p, p.fetchBuilds(p.ctx, 0)
+		case "o":
+			p.sortBy = (p.sortBy + 1) % pipelineSorts
+			p.pipelinelist.Title = p.title()
+			return p, p.refreshList()
+		case "*":
+			// "f" pages the list forward
+			selectedPipeline, ok := p.pipelinelist.SelectedItem().(listitems.PipelineItem)
+			if !ok {
+				return p, nil
+			}
+			p.toggleFavorite(selectedPipeline.Id)
+			return p, p.refreshList()
+		case "n":
+			return p, func() tea.Msg { return teamsg.CreatePipelineMsg{} }
 		case "enter":
 			selectedPipeline, ok := p.pipelinelist.SelectedItem().(listitems.PipelineItem)
 			if ok {
@@ -136,9 +241,25 @@ func (p *PipelineListSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 		}
 		p.pipelineFetchingEnabled = true
 		return p, tea.Batch(p.fetchBuilds(p.ctx, 0), p.spinner.Tick)
+	case teamsg.RunPipelineErrorMsg:
+		p.errorMessage = string(msg)
+		return p, nil
+	case teamsg.RefreshPipelinesMsg:
+		// the running loop, if any, ends on its next message
+		p.pipelineFetchingEnabled = true
+		p.fetchLoop++
+		return p, p.fetchBuilds(p.ctx, 0)
 	case teamsg.BuildsFetchedMsg:
-		p.pipelinelist.SetItems(msg)
-		return p, p.fetchBuilds(p.ctx, 5*time.Second)
+		if msg.Loop != p.fetchLoop {
+			return p, nil
+		}
+		p.pipelines = p.pipelines[:0]
+		for _, item := range msg.Items {
+			if pipeline, ok := item.(listitems.PipelineItem); ok {
+				p.pipelines = append(p.pipelines, pipeline)
+			}
+		}
+		return p, tea.Batch(p.refreshList(), p.fetchBuilds(p.ctx, max(nextPollInterval(msg.Items), azdo.RetryAfter())))
 	case spinner.TickMsg:
 		spinner, cmd := p.spinner.Update(msg)
 		p.spinner = spinner
@@ -146,28 +267,108 @@ func (p *PipelineListSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 	if p.focused {
+		previous := p.pipelinelist.Index()
 		pipelines, cmd := p.pipelinelist.Update(msg)
 		cmds = append(cmds, cmd)
 		p.pipelinelist = pipelines
+		p.skipFolderHeader(previous)
 	}
 	return p, tea.Batch(cmds...)
 }
 
-func (p *PipelineListSection) runPipeline(ctx context.Context, pipeline listitems.PipelineItem, project, sourceBranch string) int {
+// CapturingInput tells whether a search is being typed, so the page sends it every key
+func (p *PipelineListSection) CapturingInput() bool {
+	return p.pipelinelist.FilterState() == list.Filtering
+}
+
+// refreshList arranges the pipelines again, keeping the selected one selected
+func (p *PipelineListSection) refreshList() tea.Cmd {
+	selected, hasSelection := p.pipelinelist.SelectedItem().(listitems.PipelineItem)
+	items := arrangePipelines(p.pipelines, p.favorites, p.sortBy)
+	cmd := p.pipelinelist.SetItems(items)
+	if p.pipelinelist.FilterState() != list.Unfiltered {
+		// the filtered items are refreshed by cmd
+		return cmd
+	}
+	for i, item := range items {
+		if pipeline, ok := item.(listitems.PipelineItem); ok && hasSelection && pipeline.Id == selected.Id {
+			p.pipelinelist.Select(i)
+			return cmd
+		}
+	}
+	p.skipFolderHeader(-1)
+	return cmd
+}
+
+// skipFolderHeader moves the cursor off a folder header, keeping on in the direction it moved from previous
+func (p *PipelineListSection) skipFolderHeader(previous int) {
+	items := p.pipelinelist.VisibleItems()
+	index := p.pipelinelist.Index()
+	if index >= len(items) {
+		return
+	}
+	if _, ok := items[index].(listitems.FolderItem); !ok {
+		return
+	}
+	step := 1
+	if index < previous {
+		step = -1
+	}
+	for _, direction := range []int{step, -step} {
+		for i := index + direction; i >= 0 && i < len(items); i += direction {
+			if _, ok := items[i].(listitems.FolderItem); !ok {
+				p.pipelinelist.Select(i)
+				return
+			}
+		}
+	}
+}
+
+func (p *PipelineListSection) toggleFavorite(pipelineId int) {
+	if p.favorites[pipelineId] {
+		delete(p.favorites, pipelineId)
+	} else {
+		p.favorites[pipelineId] = true
+	}
+	favorites := make([]int, 0, len(p.favorites))
+	for id := range p.favorites {
+		favorites = append(favorites, id)
+	}
+	slices.Sort(favorites)
+	if err := settings.SaveFavorites(p.repositoryId.String(), favorites); err != nil {
+		p.logger.Error("error saving favorite pipelines", "error", err)
+		p.errorMessage = fmt.Sprintf("favorites couldn't be saved: %v", err)
+	}
+}
+
+// currentItems are the pipelines as last fetched
+func (p *PipelineListSection) currentItems() []list.Item {
+	items := make([]list.Item, 0, len(p.pipelines))
+	for _, pipeline := range p.pipelines {
+		items = append(items, pipeline)
+	}
+	return items
+}
+
+func (p *PipelineListSection) runPipeline(ctx context.Context, pipeline listitems.PipelineItem, project, sourceBranch string) (int, error) {
+	queued := &build.Build{
+		Definition: &build.DefinitionReference{
+			Id: &pipeline.Id,
+		},
+	}
+	// without a source branch pipelines of other repositories run on their default branch
+	if !pipeline.External {
+		queued.SourceBranch = utils.Ptr(sourceBranch)
+	}
 	runId, err := p.buildclient.QueueBuild(ctx, build.QueueBuildArgs{
 		Project: &project,
-		Build: &build.Build{
-			SourceBranch: utils.Ptr(sourceBranch),
-			Definition: &build.DefinitionReference{
-				Id: &pipeline.Id,
-			},
-		},
+		Build:   queued,
 	})
 	if err != nil {
-		p.logger.LogToFile("error", fmt.Sprintf("error while running pipeline: %s", err))
-		return 0
+		p.logger.Error("error while running pipeline", "error", err)
+		return 0, err
 	}
-	return runId
+	return runId, nil
 }
 
 func (p *PipelineListSection) SetDimensions(width, height int) {
@@ -176,49 +377,262 @@ func (p *PipelineListSection) SetDimensions(width, height int) {
 }
 
 func (p *PipelineListSection) fetchBuilds(ctx context.Context, wait time.Duration) tea.Cmd {
+	loop := p.fetchLoop
+	current := p.currentItems()
+	browse := p.browseProject
+	scope, currentBranch := p.scope, p.currentBranch
 	return func() tea.Msg {
 		err := utils.SleepWithContext(ctx, wait)
 		if err != nil {
-			return teamsg.BuildsFetchedMsg(p.pipelinelist.Items())
+			return teamsg.BuildsFetchedMsg{Items: current, Loop: loop}
 		}
-		definitions, err := p.buildclient.GetDefinitions(ctx, build.GetDefinitionsArgs{
-			RepositoryId:   utils.Ptr(p.repositoryId.String()),
-			RepositoryType: utils.Ptr("TfsGit"),
-		})
+		definitions, others, err := p.getDefinitions(ctx, browse)
 		if err != nil {
-			if errors.Is(err, azdo.ErrNoBuildsFound{}) {
-				// TODO: handle no builds found on error page
-				os.Exit(0)
-			}
-			panic(err)
+			return p.fetchFailed(err, current, loop)
+		}
+		if ctx.Err() != nil {
+			return teamsg.BuildsFetchedMsg{Items: current, Loop: loop}
+		}
+		if len(definitions) == 0 && len(others) == 0 {
+			return p.noPipelines()
+		}
+		branch, commit := p.scopeFilter(scope, currentBranch)
+		perDefinition := 1
+		if commit != "" {
+			// builds can't be filtered by commit, so look for it among the latest runs of the branch
+			perDefinition = scopedRunsLookup
+		}
+		latestBuilds, err := azdo.LatestBuilds(ctx, p.buildclient, definitionIds(definitions), branch, perDefinition)
+		if err != nil {
+			return p.fetchFailed(err, current, loop)
 		}
 		pipelineList := []list.Item{}
 		for _, definition := range definitions {
-			status, result, runId := p.getBuildStatusAndResult(*definition.Id)
-			pipelineList = append(pipelineList, listitems.PipelineItem{Name: *definition.Name, Status: status, Result: result, Symbol: p.getSymbol(status, result), RunId: runId, Id: *definition.Id})
+			item := latestRun(latestBuilds[*definition.Id], commit)
+			pipelineList = append(pipelineList, p.pipelineItem(definition, item))
+		}
+		if len(others) == 0 {
+			return teamsg.BuildsFetchedMsg{Items: pipelineList, Loop: loop}
 		}
-		return teamsg.BuildsFetchedMsg(pipelineList)
+		// pipelines of other repositories don't run on the current branch, their latest run is shown whatever the scope
+		otherBuilds, err := azdo.LatestBuilds(ctx, p.buildclient, definitionIds(others), "", 1)
+		if err != nil {
+			return p.fetchFailed(err, current, loop)
+		}
+		for _, definition := range others {
+			builds := otherBuilds[*definition.Id]
+			item := latestRun(builds, "")
+			item.External = true
+			if len(builds) > 0 && builds[0].Repository != nil && builds[0].Repository.Name != nil {
+				item.Repository = *builds[0].Repository.Name
+			}
+			pipelineList = append(pipelineList, p.pipelineItem(definition, item))
+		}
+		return teamsg.BuildsFetchedMsg{Items: pipelineList, Loop: loop}
 	}
 }
 
-func (p *PipelineListSection) getBuildStatusAndResult(pipelineId int) (string, string, int) {
-	builds, err := p.buildclient.GetBuilds(p.ctx, build.GetBuildsArgs{
-		Definitions: &[]int{pipelineId},
-		Top:         utils.Ptr(1),
-		QueryOrder:  &build.BuildQueryOrderValues.QueueTimeDescending,
+// getDefinitions returns the pipelines of the repository and the other pipelines listed: all the others of the
+// project while browsing it, the extra pipelines of the settings otherwise
+func (p *PipelineListSection) getDefinitions(ctx context.Context, browse bool) ([]build.BuildDefinitionReference, []build.BuildDefinitionReference, error) {
+	definitions, err := p.buildclient.GetDefinitions(ctx, build.GetDefinitionsArgs{
+		RepositoryId:   utils.Ptr(p.repositoryId.String()),
+		RepositoryType: utils.Ptr("TfsGit"),
 	})
+	if errors.Is(err, azdo.ErrNoBuildsFound{}) {
+		err = nil
+	}
 	if err != nil {
-		if errors.Is(err, context.Canceled) {
-			return "", "", 0
-		}
+		return nil, nil, err
+	}
+	var others []build.BuildDefinitionReference
+	switch {
+	case browse:
+		others, err = p.buildclient.GetDefinitions(ctx, build.GetDefinitionsArgs{})
 		if errors.Is(err, azdo.ErrNoBuildsFound{}) {
-			return "noRuns", "", 0
+			err = nil
+		}
+	case len(p.extraPipelines) > 0:
+		others, err = azdo.ResolveDefinitions(ctx, p.buildclient, p.extraPipelines)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := definitionIds(definitions)
+	others = slices.DeleteFunc(others, func(definition build.BuildDefinitionReference) bool {
+		return slices.Contains(ids, *definition.Id)
+	})
+	return definitions, others, nil
+}
+
+func definitionIds(definitions []build.BuildDefinitionReference) []int {
+	ids := make([]int, 0, len(definitions))
+	for _, definition := range definitions {
+		ids = append(ids, *definition.Id)
+	}
+	return ids
+}
+
+// pipelineItem completes the item of the latest run of a definition with the definition itself
+func (p *PipelineListSection) pipelineItem(definition build.BuildDefinitionReference, item listitems.PipelineItem) listitems.PipelineItem {
+	item.Name, item.Id = *definition.Name, *definition.Id
+	if definition.Path != nil {
+		item.Folder = *definition.Path
+	}
+	item.Symbol = p.getSymbol(item.Status, item.Result)
+	return item
+}
+
+// fetchFailed keeps the current statuses when the refresh was canceled or throttled, the next refresh waits for
+// Retry-After. Other errors end the refresh loop
+func (p *PipelineListSection) fetchFailed(err error, current []list.Item, loop int) tea.Msg {
+	if errors.Is(err, context.Canceled) || azdo.IsThrottled(err) {
+		p.logger.Warn("skipping pipeline status refresh", "error", err)
+		return teamsg.BuildsFetchedMsg{Items: current, Loop: loop}
+	}
+	p.logger.Error("error fetching pipelines", "error", err)
+	return teamsg.PipelinesUnavailableMsg{Err: err}
+}
+
+// noPipelines ends the refresh loop of a repository without pipelines, with the YAML files one can be created from.
+// It runs in the fetchBuilds cmd so that listing the worktree doesn't block the UI
+func (p *PipelineListSection) noPipelines() tea.Msg {
+	files, err := FindPipelineYamls()
+	if err != nil {
+		p.logger.Error("error looking for pipeline yaml files", "error", err)
+	}
+	return teamsg.PipelinesUnavailableMsg{PipelineYamls: files}
+}
+
+// nextPollInterval polls often while a run is queued or running and backs off once everything settled
+func nextPollInterval(items []list.Item) time.Duration {
+	for _, item := range items {
+		pipeline, ok := item.(listitems.PipelineItem)
+		if ok && (pipeline.Status == "inProgress" || pipeline.Status == "notStarted") {
+			return activePollInterval
+		}
+	}
+	return idlePollInterval
+}
+
+// scopeFilter returns the branch and commit the runs of scope on currentBranch must match, empty for any.
+// It runs in the fetchBuilds cmd, so it's given the scope instead of reading it from the section
+func (p *PipelineListSection) scopeFilter(scope runScope, currentBranch string) (string, string) {
+	switch scope {
+	case scopeCurrentBranch:
+		return currentBranch, ""
+	case scopeHeadCommit:
+		commit, err := gitexec.HeadCommit()
+		if err != nil {
+			p.logger.Error("error reading head commit, scoping to the current branch", "error", err)
 		}
-		panic(err)
+		return currentBranch, commit
+	}
+	return "", ""
+}
+
+func (p *PipelineListSection) title() string {
+	title := "Pipelines"
+	if p.browseProject {
+		title = "Project pipelines"
+	}
+	switch p.scope {
+	case scopeCurrentBranch:
+		title += " on " + p.currentBranch
+	case scopeHeadCommit:
+		title += " on HEAD of " + p.currentBranch
+	}
+	if p.sortBy != sortByName {
+		title += " by " + pipelineSortNames[p.sortBy]
+	}
+	return title
+}
+
+// arrangePipelines sorts the pipelines and groups them under a header per folder, favorites first.
+// Without favorites and with a single folder there is nothing to group and the list is flat
+func arrangePipelines(pipelines []listitems.PipelineItem, favorites map[int]bool, sortBy pipelineSort) []list.Item {
+	sorted := slices.Clone(pipelines)
+	slices.SortStableFunc(sorted, func(a, b listitems.PipelineItem) int {
+		switch sortBy {
+		case sortByLastRun:
+			// pipelines that never ran have a zero queue time and come last
+			if c := b.QueueTime.Compare(a.QueueTime); c != 0 {
+				return c
+			}
+		case sortByStatus:
+			if c := statusRank(a) - statusRank(b); c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	var favoriteItems []list.Item
+	folders := make(map[string][]list.Item)
+	for _, pipeline := range sorted {
+		pipeline.Favorite = favorites[pipeline.Id]
+		if pipeline.Favorite {
+			favoriteItems = append(favoriteItems, pipeline)
+			continue
+		}
+		folder := pipeline.Folder
+		if folder == "" {
+			folder = `\`
+		}
+		folders[folder] = append(folders[folder], pipeline)
+	}
+	if len(favoriteItems) == 0 && len(folders) <= 1 {
+		for _, items := range folders {
+			return items
+		}
+		return []list.Item{}
+	}
+
+	arranged := []list.Item{}
+	if len(favoriteItems) > 0 {
+		arranged = append(arranged, listitems.FolderItem{Title: "★ Favorites", Count: len(favoriteItems)})
+		arranged = append(arranged, favoriteItems...)
+	}
+	paths := slices.Sorted(maps.Keys(folders))
+	for _, path := range paths {
+		arranged = append(arranged, listitems.FolderItem{Title: path, Count: len(folders[path])})
+		arranged = append(arranged, folders[path]...)
+	}
+	return arranged
+}
+
+// statusRank orders pipelines by what needs attention first when sorting by status
+func statusRank(pipeline listitems.PipelineItem) int {
+	status := utils.StatusOrResult(&pipeline.Status, &pipeline.Result)
+	if rank := slices.Index(statusOrder, status); rank >= 0 {
+		return rank
+	}
+	return len(statusOrder)
+}
+
+// latestRun returns the status of the latest of builds, which are newest first, of commit if set
+func latestRun(builds []build.Build, commit string) listitems.PipelineItem {
+	for _, b := range builds {
+		if commit == "" || (b.SourceVersion != nil && *b.SourceVersion == commit) {
+			return pipelineItemFromBuild(b)
+		}
+	}
+	return listitems.PipelineItem{Status: "noRuns"}
+}
+
+func pipelineItemFromBuild(b build.Build) listitems.PipelineItem {
+	status, result := getStatusAndResult(&b)
+	item := listitems.PipelineItem{Status: status, Result: result, RunId: *b.Id}
+	if b.SourceBranch != nil {
+		item.Branch = *b.SourceBranch
+	}
+	if b.SourceVersion != nil {
+		item.Commit = *b.SourceVersion
+	}
+	if b.QueueTime != nil {
+		item.QueueTime = b.QueueTime.Time
 	}
-	buildValue := builds[0]
-	status, result := getStatusAndResult(&buildValue)
-	return status, result, *buildValue.Id
+	return item
 }
 
 func getStatusAndResult(build *build.Build) (string, string) {
@@ -241,7 +655,7 @@ func getStatusAndResult(build *build.Build) (string, string) {
 
 func (p *PipelineListSection) getSymbol(status, result string) *string {
 	finalStatus := utils.StatusOrResult(&status, &result)
-	p.logger.LogToFile("info", fmt.Sprintf("final status: %s", finalStatus))
+	p.logger.Info("final status", "status", finalStatus)
 	if finalStatus == "inProgress" {
 		return p.spinnerView
 	} else {
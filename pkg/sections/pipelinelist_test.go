@@ -0,0 +1,215 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/logger"
+	"azdoext/pkg/teamsg"
+	"azdoext/pkg/utils"
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/build"
+)
+
+func TestPipelineItemFromBuild(t *testing.T) {
+	queued := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	item := pipelineItemFromBuild(build.Build{
+		Id:            utils.Ptr(7),
+		Status:        &build.BuildStatusValues.InProgress,
+		SourceBranch:  utils.Ptr("refs/heads/main"),
+		SourceVersion: utils.Ptr("1a2b3c4d5e6f"),
+		QueueTime:     &azuredevops.Time{Time: queued},
+	})
+	if item.RunId != 7 || item.Status != "inProgress" || item.Branch != "refs/heads/main" || item.Commit != "1a2b3c4d5e6f" || !item.QueueTime.Equal(queued) {
+		t.Errorf("unexpected pipeline item %+v", item)
+	}
+}
+
+func TestLatestRunMatchesCommit(t *testing.T) {
+	builds := []build.Build{
+		{Id: utils.Ptr(9), SourceVersion: utils.Ptr("newer")},
+		{Id: utils.Ptr(8), SourceVersion: utils.Ptr("head")},
+	}
+	if item := latestRun(builds, ""); item.RunId != 9 {
+		t.Errorf("expected the newest run, got %+v", item)
+	}
+	if item := latestRun(builds, "head"); item.RunId != 8 {
+		t.Errorf("expected the run of the commit, got %+v", item)
+	}
+	if item := latestRun(builds, "other"); item.Status != "noRuns" {
+		t.Errorf("expected no runs, got %+v", item)
+	}
+}
+
+func TestNextPollInterval(t *testing.T) {
+	settled := []list.Item{listitems.PipelineItem{Status: "completed"}, listitems.PipelineItem{Status: "noRuns"}}
+	if got := nextPollInterval(settled); got != idlePollInterval {
+		t.Errorf("expected idle interval, got %s", got)
+	}
+	running := append(settled, listitems.PipelineItem{Status: "inProgress"})
+	if got := nextPollInterval(running); got != activePollInterval {
+		t.Errorf("expected active interval, got %s", got)
+	}
+}
+
+func TestArrangePipelines(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	pipelines := []listitems.PipelineItem{
+		{Id: 1, Name: "deploy", Folder: `\release`, Status: "completed", Result: "succeeded", QueueTime: now.Add(-time.Hour)},
+		{Id: 2, Name: "ci", Folder: `\`, Status: "completed", Result: "failed", QueueTime: now.Add(-2 * time.Hour)},
+		{Id: 3, Name: "Nightly", Folder: `\`, Status: "inProgress", QueueTime: now},
+		{Id: 4, Name: "docs", Folder: `\`, Status: "noRuns"},
+	}
+	describe := func(items []list.Item) []string {
+		var names []string
+		for _, item := range items {
+			switch item := item.(type) {
+			case listitems.FolderItem:
+				names = append(names, item.Title)
+			case listitems.PipelineItem:
+				names = append(names, item.Name)
+			}
+		}
+		return names
+	}
+	tests := []struct {
+		name      string
+		pipelines []listitems.PipelineItem
+		favorites map[int]bool
+		sortBy    pipelineSort
+		want      []string
+	}{
+		{"grouped by folder", pipelines, nil, sortByName, []string{`\`, "ci", "docs", "Nightly", `\release`, "deploy"}},
+		{"favorites first", pipelines, map[int]bool{1: true}, sortByName, []string{"★ Favorites", "deploy", `\`, "ci", "docs", "Nightly"}},
+		{"by last run", pipelines[1:], nil, sortByLastRun, []string{"Nightly", "ci", "docs"}},
+		{"by status", pipelines[1:], nil, sortByStatus, []string{"Nightly", "ci", "docs"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := describe(arrangePipelines(tt.pipelines, tt.favorites, tt.sortBy))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("arrangePipelines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPipelineItemFilterValue(t *testing.T) {
+	if got := (listitems.PipelineItem{Name: "azdoext-ci"}).FilterValue(); got != "azdoext-ci" {
+		t.Errorf("expected pipelines to be searchable by name, got %q", got)
+	}
+	if got := (listitems.FolderItem{Title: `\release`}).FilterValue(); got != "" {
+		t.Errorf("expected folder headers to be left out of searches, got %q", got)
+	}
+}
+
+func TestPipelineListSkipsFolderHeaders(t *testing.T) {
+	pipelinelist := list.New([]list.Item{}, listitems.ItemDelegate{}, 40, 10)
+	section := &PipelineListSection{pipelinelist: pipelinelist, focused: true, favorites: map[int]bool{}}
+	section.pipelines = []listitems.PipelineItem{
+		{Id: 1, Name: "ci", Folder: `\`},
+		{Id: 2, Name: "deploy", Folder: `\release`},
+	}
+	section.refreshList()
+	selected := func() string {
+		pipeline, _ := section.pipelinelist.SelectedItem().(listitems.PipelineItem)
+		return pipeline.Name
+	}
+	if selected() != "ci" {
+		t.Fatalf("expected the first pipeline to be selected, got %q", selected())
+	}
+	section.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	if selected() != "deploy" {
+		t.Errorf("expected moving down to skip the folder header, got %q", selected())
+	}
+	section.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+	if selected() != "ci" {
+		t.Errorf("expected moving up to skip the folder header, got %q", selected())
+	}
+	section.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+	if selected() != "ci" {
+		t.Errorf("expected to stay on the first pipeline, got %q", selected())
+	}
+}
+
+type definitionsClient struct {
+	azdo.BuildClientInterface
+	repository []build.BuildDefinitionReference
+	project    []build.BuildDefinitionReference
+	builds     []build.Build
+	branches   []string
+}
+
+func (c *definitionsClient) GetDefinitions(ctx context.Context, args build.GetDefinitionsArgs) ([]build.BuildDefinitionReference, error) {
+	if args.RepositoryId != nil {
+		return c.repository, nil
+	}
+	return c.project, nil
+}
+
+func (c *definitionsClient) GetBuilds(ctx context.Context, args build.GetBuildsArgs) ([]build.Build, error) {
+	branch := ""
+	if args.BranchName != nil {
+		branch = *args.BranchName
+	}
+	c.branches = append(c.branches, branch)
+	var builds []build.Build
+	for _, b := range c.builds {
+		if slices.Contains(*args.Definitions, *b.Definition.Id) {
+			builds = append(builds, b)
+		}
+	}
+	return builds, nil
+}
+
+func TestFetchBuildsListsOtherRepositories(t *testing.T) {
+	definition := func(id int, folder, name string) build.BuildDefinitionReference {
+		return build.BuildDefinitionReference{Id: utils.Ptr(id), Path: utils.Ptr(folder), Name: utils.Ptr(name)}
+	}
+	client := &definitionsClient{
+		repository: []build.BuildDefinitionReference{definition(1, `\`, "app-ci")},
+		project:    []build.BuildDefinitionReference{definition(1, `\`, "app-ci"), definition(2, `\infra`, "deploy-app")},
+		builds: []build.Build{{
+			Id:           utils.Ptr(20),
+			Definition:   &build.DefinitionReference{Id: utils.Ptr(2)},
+			Status:       &build.BuildStatusValues.Completed,
+			Result:       &build.BuildResultValues.Succeeded,
+			SourceBranch: utils.Ptr("refs/heads/main"),
+			Repository:   &build.BuildRepository{Name: utils.Ptr("infra")},
+		}},
+	}
+	section := &PipelineListSection{
+		logger:         logger.NewLogger("pipelinelist"),
+		buildclient:    client,
+		currentBranch:  "feature",
+		scope:          scopeCurrentBranch,
+		extraPipelines: []string{`\infra\deploy-app`},
+	}
+	msg, ok := section.fetchBuilds(context.Background(), 0)().(teamsg.BuildsFetchedMsg)
+	if !ok || len(msg.Items) != 2 {
+		t.Fatalf("expected the repository and the extra pipeline, got %+v", msg)
+	}
+	own, other := msg.Items[0].(listitems.PipelineItem), msg.Items[1].(listitems.PipelineItem)
+	if own.External || own.Status != "noRuns" {
+		t.Errorf("unexpected repository pipeline %+v", own)
+	}
+	if !other.External || other.Repository != "infra" || other.RunId != 20 || other.Folder != `\infra` {
+		t.Errorf("unexpected extra pipeline %+v", other)
+	}
+	if !reflect.DeepEqual(client.branches, []string{"refs/heads/feature", ""}) {
+		t.Errorf("expected the extra pipeline to ignore the branch scope, got %v", client.branches)
+	}
+
+	section.extraPipelines, section.browseProject = nil, true
+	msg = section.fetchBuilds(context.Background(), 0)().(teamsg.BuildsFetchedMsg)
+	if len(msg.Items) != 2 || !msg.Items[1].(listitems.PipelineItem).External {
+		t.Errorf("expected every pipeline of the project while browsing it, got %+v", msg.Items)
+	}
+}
@@ -20,6 +20,7 @@ import (
 
 	"charm.land/bubbles/v2/list"
 	"charm.land/bubbles/v2/spinner"
+	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/google/uuid"
@@ -37,14 +38,27 @@ type PipelineTasksSection struct {
 	cancelCtx         context.CancelFunc
 	spinner           spinner.Model
 	buildclient       azdo.BuildClientInterface
+	approvalsclient   azdo.ApprovalsClientInterface
+	approvals         map[uuid.UUID]azdo.Approval
 	followRun         bool
 	result            string
 	buildStatus       string
 	sectionIdentifier SectionName
+
+	// confirmPrompt is shown until the user answers it, y runs confirmCmd
+	confirmPrompt string
+	confirmCmd    tea.Cmd
+	actionMessage string
+	actionFailed  bool
+
+	// commentInput is shown while approving or rejecting approvalId
+	commentInput textinput.Model
+	approvalId   uuid.UUID
+	approve      bool
 }
 
-func NewPipelineTasks(ctx context.Context, secid SectionName, buildclient azdo.BuildClientInterface) Section {
-	logger := logger.NewLogger("pipelinetasks.log")
+func NewPipelineTasks(ctx context.Context, secid SectionName, buildclient azdo.BuildClientInterface, approvalsclient azdo.ApprovalsClientInterface) Section {
+	logger := logger.NewLogger("pipelinetasks")
 	tasklist := list.New([]list.Item{}, listitems.PipelineRecordItemDelegate{}, 0, 0)
 	tasklist.SetShowTitle(false)
 	tasklist.SetShowStatusBar(false)
@@ -55,6 +69,10 @@ func NewPipelineTasks(ctx context.Context, secid SectionName, buildclient azdo.B
 	spner.Spinner = spinner.Dot
 	spner.Style = styles.SpinnerStyle
 
+	commentInput := textinput.New()
+	commentInput.Placeholder = "optional comment"
+	commentInput.SetWidth(styles.DefaultSectionWidth - 4)
+
 	return &PipelineTasksSection{
 		logger:            logger,
 		tasklist:          tasklist,
@@ -62,6 +80,8 @@ func NewPipelineTasks(ctx context.Context, secid SectionName, buildclient azdo.B
 		spinner:           spner,
 		spinnerView:       utils.Ptr(spner.View()),
 		buildclient:       buildclient,
+		approvalsclient:   approvalsclient,
+		commentInput:      commentInput,
 		sectionIdentifier: secid,
 	}
 }
@@ -112,6 +132,23 @@ func (p *PipelineTasksSection) View() string {
 	tasklistWidth := p.tasklist.Width()
 	followViewStyle := lipgloss.NewStyle().PaddingLeft(tasklistWidth - p.tasklist.Paginator.TotalPages - lipgloss.Width(p.followView()))
 	bottomView := lipgloss.JoinHorizontal(lipgloss.Bottom, p.tasklist.Paginator.View(), followViewStyle.Render(p.followView()))
+	switch {
+	case p.CapturingInput():
+		action := "Reject"
+		if p.approve {
+			action = "Approve"
+		}
+		prompt := lipgloss.NewStyle().Foreground(styles.Yellow).Render(action + " (enter to submit, esc to cancel)")
+		bottomView = lipgloss.JoinVertical(lipgloss.Left, prompt, p.commentInput.View())
+	case p.confirmPrompt != "":
+		bottomView = lipgloss.NewStyle().Foreground(styles.Yellow).Width(tasklistWidth).Render(p.confirmPrompt)
+	case p.actionMessage != "":
+		color := styles.Grey
+		if p.actionFailed {
+			color = styles.Red
+		}
+		bottomView = lipgloss.NewStyle().Foreground(color).Width(tasklistWidth).Render(p.actionMessage)
+	}
 	secView := lipgloss.JoinVertical(lipgloss.Top, title, p.tasklist.View(), bottomView)
 	if p.focused {
 		return styles.ActiveStyle.MaxWidth(tasklistWidth).Render(secView)
@@ -123,6 +160,8 @@ func (p *PipelineTasksSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 	var cmds []tea.Cmd
 	switch msg := msg.(type) {
 	case teamsg.PipelineRunStateMsg:
+		p.buildStatus = msg.Status
+		p.approvals = msg.Approvals
 		setitemscmd := p.tasklist.SetItems(msg.Items)
 		tasks, listupdatecmd := p.tasklist.Update(msg)
 		p.tasklist = tasks
@@ -135,6 +174,8 @@ func (p *PipelineTasksSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 		setEmptyListCmd := p.tasklist.SetItems([]list.Item{})
 		p.tasklist.Title = fmt.Sprint(msg.PipelineName)
 		p.monitoredRunId = msg.RunId
+		p.buildStatus = msg.Status
+		p.confirmPrompt, p.confirmCmd, p.actionMessage = "", nil, ""
 		return p, tea.Batch(p.getRunState(p.ctx, msg.RunId, 0), p.spinner.Tick, setEmptyListCmd)
 	case teamsg.LogMsg:
 		if len(msg.BuildResult) > 0 {
@@ -164,13 +205,46 @@ func (p *PipelineTasksSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 		p.spinner = spinner
 		*p.spinnerView = spinner.View()
 		return p, cmd
+	case teamsg.RunActionMsg:
+		if msg.RunId != p.monitoredRunId {
+			return p, nil
+		}
+		p.actionMessage, p.actionFailed = msg.Description, msg.Err != nil
+		if msg.Err != nil {
+			p.logger.Error("run action failed", "runId", msg.RunId, "error", msg.Err)
+			p.actionMessage = msg.Err.Error()
+		}
+		return p, nil
 	case tea.KeyPressMsg:
+		if p.CapturingInput() {
+			return p, p.updateComment(msg)
+		}
+		if p.focused {
+			p.actionMessage, p.actionFailed = "", false
+			if p.confirmPrompt != "" {
+				cmd := p.confirmCmd
+				p.confirmPrompt, p.confirmCmd = "", nil
+				if msg.String() == "y" {
+					return p, cmd
+				}
+				return p, nil
+			}
+		}
 		switch msg.String() {
 		case "q":
 			return p, nil
 		case "f":
 			p.followRun = !p.followRun
 			return p, nil
+		case "c", "r", "R":
+			if p.focused && p.monitoredRunId != 0 {
+				p.requestAction(msg.String())
+				return p, nil
+			}
+		case "a", "x":
+			if p.focused {
+				return p, p.startApproval(msg.String() == "a")
+			}
 		}
 		if p.focused {
 			tasks, cmd := p.tasklist.Update(msg)
@@ -190,13 +264,125 @@ func (p *PipelineTasksSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 	return p, tea.Batch(cmds...)
 }
 
+// requestAction asks for confirmation of the action bound to key: c cancels the run, r retries the failed jobs of the
+// selected stage or of the whole run, R queues a new run of the same commit
+func (p *PipelineTasksSection) requestAction(key string) {
+	runId := p.monitoredRunId
+	switch key {
+	case "c":
+		if p.buildStatus == string(build.BuildStatusValues.Completed) {
+			p.actionMessage = "run already completed"
+			return
+		}
+		p.confirmPrompt = fmt.Sprintf("Cancel run %d? (y/n)", runId)
+		p.confirmCmd = p.runAction(runId, fmt.Sprintf("cancel of run %d requested", runId), false, func() error {
+			return p.buildclient.CancelBuild(p.ctx, runId)
+		})
+	case "r":
+		if stage, ok := p.selectedStage(); ok && stage.Identifier != "" && isRetryableResult(stage.Result) {
+			p.confirmPrompt = fmt.Sprintf("Retry failed jobs of stage %s? (y/n)", stage.Name)
+			p.confirmCmd = p.runAction(runId, fmt.Sprintf("retrying stage %s", stage.Name), true, func() error {
+				return p.buildclient.RetryStage(p.ctx, runId, stage.Identifier)
+			})
+			return
+		}
+		if p.buildStatus != string(build.BuildStatusValues.Completed) {
+			p.actionMessage = "select a failed stage to retry it while the run is in progress"
+			return
+		}
+		p.confirmPrompt = fmt.Sprintf("Retry failed jobs of run %d? (y/n)", runId)
+		p.confirmCmd = p.runAction(runId, fmt.Sprintf("retrying failed jobs of run %d", runId), true, func() error {
+			return p.buildclient.RetryFailedJobs(p.ctx, runId)
+		})
+	case "R":
+		pipelineName := p.tasklist.Title
+		p.confirmPrompt = fmt.Sprintf("Queue a new run of %s on the same commit? (y/n)", pipelineName)
+		p.confirmCmd = func() tea.Msg {
+			newRunId, err := p.buildclient.RerunBuild(p.ctx, runId)
+			if err != nil {
+				return teamsg.RunActionMsg{RunId: runId, Err: err}
+			}
+			return teamsg.PipelineRunIdMsg{RunId: newRunId, PipelineName: pipelineName, Status: string(build.BuildStatusValues.NotStarted)}
+		}
+	}
+}
+
+// CapturingInput tells whether the section is reading an approval comment, so the page sends it every key
+func (p *PipelineTasksSection) CapturingInput() bool {
+	return p.approvalId != uuid.Nil
+}
+
+// startApproval asks for a comment to approve or reject the selected approval, if it is pending
+func (p *PipelineTasksSection) startApproval(approve bool) tea.Cmd {
+	record, ok := p.tasklist.SelectedItem().(listitems.PipelineRecordItem)
+	if !ok || record.Type != "Checkpoint.Approval" {
+		p.actionMessage = "select an approval to approve or reject it"
+		return nil
+	}
+	if approval, ok := p.approvals[record.Id]; !ok || !approval.IsPending() {
+		p.actionMessage = "approval is not pending"
+		return nil
+	}
+	p.approvalId = record.Id
+	p.approve = approve
+	p.commentInput.Reset()
+	return p.commentInput.Focus()
+}
+
+func (p *PipelineTasksSection) updateComment(msg tea.KeyPressMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		p.approvalId = uuid.Nil
+		p.commentInput.Blur()
+		return nil
+	case "enter":
+		approvalId, approve, comment := p.approvalId, p.approve, p.commentInput.Value()
+		p.approvalId = uuid.Nil
+		p.commentInput.Blur()
+		description := "rejected"
+		if approve {
+			description = "approved"
+		}
+		return p.runAction(p.monitoredRunId, description, false, func() error {
+			return p.approvalsclient.UpdateApproval(p.ctx, approvalId, approve, comment)
+		})
+	}
+	input, cmd := p.commentInput.Update(msg)
+	p.commentInput = input
+	return cmd
+}
+
+func (p *PipelineTasksSection) runAction(runId int, description string, retried bool, action func() error) tea.Cmd {
+	return func() tea.Msg {
+		if err := action(); err != nil {
+			return teamsg.RunActionMsg{RunId: runId, Err: err}
+		}
+		return teamsg.RunActionMsg{RunId: runId, Description: description, Retried: retried}
+	}
+}
+
+// selectedStage returns the stage of the selected record, records are listed in Stage->Job->Task order
+func (p *PipelineTasksSection) selectedStage() (listitems.PipelineRecordItem, bool) {
+	items := p.tasklist.Items()
+	for i := min(p.tasklist.Index(), len(items)-1); i >= 0; i-- {
+		if record, ok := items[i].(listitems.PipelineRecordItem); ok && record.Type == "Stage" {
+			return record, true
+		}
+	}
+	return listitems.PipelineRecordItem{}, false
+}
+
+func isRetryableResult(result build.TaskResult) bool {
+	return result == build.TaskResultValues.Failed || result == build.TaskResultValues.Canceled
+}
+
 func (p *PipelineTasksSection) getRunState(ctx context.Context, runId int, wait time.Duration) tea.Cmd {
 	return func() tea.Msg {
-		p.logger.LogToFile("debug", fmt.Sprintf("fetching run state for runId %d after %s", runId, wait))
+		p.logger.Debug("fetching run state", "runId", runId, "wait", wait)
 		err := utils.SleepWithContext(ctx, wait)
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
-				p.logger.LogToFile("debug", "context cancelled while sleeping")
+				p.logger.Debug("context cancelled while sleeping")
 				return nil
 			}
 			panic(err)
@@ -206,10 +392,10 @@ func (p *PipelineTasksSection) getRunState(ctx context.Context, runId int, wait
 		})
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
-				p.logger.LogToFile("debug", "context cancelled while fetching timeline records")
+				p.logger.Debug("context cancelled while fetching timeline records")
 				return nil
 			}
-			p.logger.LogToFile("error", fmt.Sprintf("error while fetching build timeline: %s", err))
+			p.logger.Error("error while fetching build timeline", "error", err)
 			return nil
 		}
 		if len(records) == 0 {
@@ -219,6 +405,7 @@ func (p *PipelineTasksSection) getRunState(ctx context.Context, runId int, wait
 		sortedRecordItems := convertToItems(sortedRecords)
 		sortedRecordItems = filterRecords(sortedRecordItems)
 		items := p.addSymbol(sortedRecordItems)
+		approvals := p.getApprovals(ctx, records)
 		build, err := p.buildclient.GetBuilds(ctx, build.GetBuildsArgs{
 			BuildIds: &[]int{runId},
 		})
@@ -230,10 +417,30 @@ func (p *PipelineTasksSection) getRunState(ctx context.Context, runId int, wait
 		}
 		buildstatus := build[0].Status
 		return teamsg.PipelineRunStateMsg{
-			Items:  items,
-			Status: string(*buildstatus),
+			Items:     items,
+			Status:    string(*buildstatus),
+			Approvals: approvals,
+		}
+	}
+}
+
+// getApprovals fetches the approvals of the run, failing to do so only hides who must approve
+func (p *PipelineTasksSection) getApprovals(ctx context.Context, records []build.TimelineRecord) map[uuid.UUID]azdo.Approval {
+	var approvalIds []uuid.UUID
+	for _, record := range records {
+		if *record.Type == "Checkpoint.Approval" {
+			approvalIds = append(approvalIds, *record.Id)
 		}
 	}
+	if len(approvalIds) == 0 {
+		return nil
+	}
+	approvals, err := p.approvalsclient.GetApprovals(ctx, approvalIds)
+	if err != nil {
+		p.logger.Error("error while fetching approvals", "error", err)
+		return nil
+	}
+	return approvals
 }
 
 func getResultFromRecord(record build.TimelineRecord) build.TaskResult {
@@ -273,21 +480,30 @@ func buildPipelineRecordItemFromRecord(record build.TimelineRecord) listitems.Pi
 	if record.StartTime != nil {
 		recordStartTime = record.StartTime.Time
 	}
+	identifier := ""
+	if record.Identifier != nil {
+		identifier = *record.Identifier
+	}
 
 	return listitems.PipelineRecordItem{
-		StartTime: recordStartTime,
-		Type:      *record.Type,
-		Name:      *record.Name,
-		State:     *record.State,
-		Result:    getResultFromRecord(record),
-		Id:        *record.Id,
+		StartTime:  recordStartTime,
+		Type:       *record.Type,
+		Name:       *record.Name,
+		Identifier: identifier,
+		State:      *record.State,
+		Result:     getResultFromRecord(record),
+		Id:         *record.Id,
 	}
 }
 
-// We use this filter function to remove Phase records since they seem to have a 1-1 relationship to jobs, and also filter out pending tasks
+// We use this filter function to remove Phase and Checkpoint records since they only group jobs and checks,
+// and also filter out pending tasks, approvals and checks stay since pending is when they need attention
 func filterRecords(records []listitems.PipelineRecordItem) []listitems.PipelineRecordItem {
 	return slices.DeleteFunc(records, func(record listitems.PipelineRecordItem) bool {
-		return record.Type == "Phase" || record.State == build.TimelineRecordStateValues.Pending
+		if azdo.IsCheckRecord(record.Type) {
+			return false
+		}
+		return record.Type == "Phase" || record.Type == "Checkpoint" || record.State == build.TimelineRecordStateValues.Pending
 	})
 }
 
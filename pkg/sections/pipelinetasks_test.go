@@ -15,6 +15,7 @@ import (
 	"testing"
 	"time"
 
+	"charm.land/bubbles/v2/list"
 	"github.com/google/uuid"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/build"
 )
@@ -61,6 +62,26 @@ func (b buildClient) GetBuilds(ctx context.Context, args build.GetBuildsArgs) ([
 	return []build.Build{}, nil
 }
 
+func (b buildClient) GetBuildsPage(ctx context.Context, args build.GetBuildsArgs) ([]build.Build, string, error) {
+	return []build.Build{}, "", nil
+}
+
+func (b buildClient) CancelBuild(ctx context.Context, buildId int) error {
+	return nil
+}
+
+func (b buildClient) RetryFailedJobs(ctx context.Context, buildId int) error {
+	return nil
+}
+
+func (b buildClient) RetryStage(ctx context.Context, buildId int, stageRefName string) error {
+	return nil
+}
+
+func (b buildClient) RerunBuild(ctx context.Context, buildId int) (int, error) {
+	return 0, nil
+}
+
 func TestSortRecords(t *testing.T) {
 	records, err := buildClient{}.GetFilteredBuildTimelineRecords(context.Background(), build.GetBuildTimelineArgs{})
 	if err != nil {
@@ -159,3 +180,41 @@ func TestBuildPipelineRecordItemWithoutStartTime(t *testing.T) {
 		t.Errorf("Expected %v, got %v", expectedRecord, record)
 	}
 }
+
+func TestRetryRequestsSelectedFailedStage(t *testing.T) {
+	p := NewPipelineTasks(context.Background(), PipelineTasks, buildClient{}, nil).(*PipelineTasksSection)
+	p.monitoredRunId = 1
+	p.buildStatus = "inProgress"
+	p.tasklist.SetItems([]list.Item{
+		listitems.PipelineRecordItem{Type: "Stage", Name: "Build", Identifier: "build", Result: build.TaskResultValues.Succeeded},
+		listitems.PipelineRecordItem{Type: "Stage", Name: "Deploy", Identifier: "deploy", Result: build.TaskResultValues.Failed},
+		listitems.PipelineRecordItem{Type: "Job", Name: "Deploy job", Identifier: "deploy.job", Result: build.TaskResultValues.Failed},
+	})
+	p.tasklist.Select(2)
+
+	p.requestAction("r")
+	if p.confirmPrompt != "Retry failed jobs of stage Deploy? (y/n)" {
+		t.Errorf("unexpected prompt %q", p.confirmPrompt)
+	}
+
+	p.confirmPrompt, p.confirmCmd = "", nil
+	p.tasklist.Select(0)
+	p.requestAction("r")
+	if p.confirmPrompt != "" || p.actionMessage == "" {
+		t.Errorf("expected no retry of a succeeded stage while the run is in progress, got prompt %q", p.confirmPrompt)
+	}
+}
+
+func TestFilterRecordsKeepsPendingApprovals(t *testing.T) {
+	records := []listitems.PipelineRecordItem{
+		{Type: "Stage", Name: "Deploy", State: build.TimelineRecordStateValues.Pending},
+		{Type: "Checkpoint", Name: "Checkpoint", State: build.TimelineRecordStateValues.InProgress},
+		{Type: "Checkpoint.Approval", Name: "Approval", State: build.TimelineRecordStateValues.Pending},
+		{Type: "Phase", Name: "Deploy job", State: build.TimelineRecordStateValues.Pending},
+		{Type: "Job", Name: "Deploy job", State: build.TimelineRecordStateValues.Pending},
+	}
+	filtered := filterRecords(records)
+	if len(filtered) != 1 || filtered[0].Type != "Checkpoint.Approval" {
+		t.Errorf("expected only the pending approval, got %+v", filtered)
+	}
+}
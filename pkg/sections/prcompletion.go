@@ -0,0 +1,330 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/logger"
+	"azdoext/pkg/styles"
+	"azdoext/pkg/teamsg"
+	"context"
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+// the actions that finish a pull request, auto-complete first as it's the one that waits for policies
+const (
+	autoCompleteAction = "set auto-complete"
+	completeAction     = "complete now"
+	abandonAction      = "abandon"
+)
+
+var pullRequestActions = []string{autoCompleteAction, completeAction, abandonAction}
+
+// mergeStrategies are named the way Azure DevOps names them when completing a pull request
+var mergeStrategies = []struct {
+	name     string
+	strategy git.GitPullRequestMergeStrategy
+}{
+	{"merge", git.GitPullRequestMergeStrategyValues.NoFastForward},
+	{"squash", git.GitPullRequestMergeStrategyValues.Squash},
+	{"rebase", git.GitPullRequestMergeStrategyValues.Rebase},
+	{"semi-linear", git.GitPullRequestMergeStrategyValues.RebaseMerge},
+}
+
+// the fields of the completion form, in order
+const (
+	completionActionField = iota
+	completionStrategyField
+	completionDeleteBranchField
+	completionWorkItemsField
+	completionMessageField
+	completionFields
+)
+
+// PullRequestCompletionSection completes, sets to auto-complete or abandons the pull request shown on the pull
+// requests page
+type PullRequestCompletionSection struct {
+	logger              *logger.Logger
+	hidden              bool
+	focused             bool
+	ctx                 context.Context
+	gitclient           azdo.GitClientInterface
+	project             string
+	repositoryId        uuid.UUID
+	sectionIdentifier   SectionName
+	pr                  listitems.PullRequestItem
+	cursor              int
+	action              int
+	strategy            int
+	deleteSourceBranch  bool
+	transitionWorkItems bool
+	messageInput        textinput.Model
+	// confirmPrompt is shown until the user answers it, y sends the action
+	confirmPrompt string
+	submitting    bool
+	errorMessage  string
+	width         int
+	help          string
+}
+
+func NewPullRequestCompletion(ctx context.Context, secid SectionName, gitclient azdo.GitClientInterface, azdoconfig azdo.Config) Section {
+	logger := logger.NewLogger("prcompletion")
+	messageInput := textinput.New()
+	messageInput.Prompt = ""
+	messageInput.Placeholder = "generated by Azure DevOps"
+	help := styles.ShortHelpStyle.Render("↑/↓ fields • ←/→ change • ↵ send • esc back")
+	return &PullRequestCompletionSection{
+		logger:              logger,
+		ctx:                 ctx,
+		gitclient:           gitclient,
+		project:             azdoconfig.ProjectId,
+		repositoryId:        azdoconfig.RepositoryId,
+		sectionIdentifier:   secid,
+		transitionWorkItems: true,
+		messageInput:        messageInput,
+		width:               styles.DefaultSectionWidth,
+		help:                help,
+	}
+}
+
+func (c *PullRequestCompletionSection) GetSectionIdentifier() SectionName {
+	return c.sectionIdentifier
+}
+
+func (c *PullRequestCompletionSection) IsHidden() bool {
+	return c.hidden
+}
+
+func (c *PullRequestCompletionSection) IsFocused() bool {
+	return c.focused
+}
+
+func (c *PullRequestCompletionSection) Hide() {
+	c.hidden = true
+	c.Blur()
+}
+
+func (c *PullRequestCompletionSection) Show() {
+	c.hidden = false
+}
+
+func (c *PullRequestCompletionSection) Focus() {
+	c.Show()
+	c.focused = true
+	c.focusCursor()
+}
+
+func (c *PullRequestCompletionSection) Blur() {
+	c.focused = false
+	c.confirmPrompt = ""
+	c.focusCursor()
+}
+
+func (c *PullRequestCompletionSection) SetDimensions(width, height int) {
+	// the form takes the place of the status, below the pull request details
+	c.width = max(styles.Width-max(styles.Width/2, styles.DefaultSectionWidth+20)-3, styles.DefaultSectionWidth)
+	c.messageInput.SetWidth(c.width)
+}
+
+func (c *PullRequestCompletionSection) focusCursor() {
+	if c.focused && c.cursor == completionMessageField {
+		c.messageInput.Focus()
+		return
+	}
+	c.messageInput.Blur()
+}
+
+// completion is how the pull request gets merged, the merge commit message is left to Azure DevOps when empty
+func (c *PullRequestCompletionSection) completion() azdo.PullRequestCompletion {
+	return azdo.PullRequestCompletion{
+		MergeStrategy:       mergeStrategies[c.strategy].strategy,
+		DeleteSourceBranch:  c.deleteSourceBranch,
+		TransitionWorkItems: c.transitionWorkItems,
+		MergeCommitMessage:  strings.TrimSpace(c.messageInput.Value()),
+	}
+}
+
+// confirmation asks before sending the chosen action, none of them can be undone from here
+func (c *PullRequestCompletionSection) confirmation() string {
+	strategy := mergeStrategies[c.strategy].name
+	switch pullRequestActions[c.action] {
+	case completeAction:
+		return fmt.Sprintf("Complete #%d with %s now? (y/n)", c.pr.Id, strategy)
+	case abandonAction:
+		return fmt.Sprintf("Abandon #%d? (y/n)", c.pr.Id)
+	}
+	return fmt.Sprintf("Complete #%d with %s once its policies are satisfied? (y/n)", c.pr.Id, strategy)
+}
+
+func (c *PullRequestCompletionSection) submit() tea.Cmd {
+	c.submitting = true
+	action, pr, completion := pullRequestActions[c.action], c.pr, c.completion()
+	return func() tea.Msg {
+		var (
+			updated git.GitPullRequest
+			err     error
+		)
+		repositoryId := c.repositoryId.String()
+		switch action {
+		case completeAction:
+			updated, err = c.gitclient.CompletePullRequest(c.ctx, c.project, repositoryId, pr.Id, pr.LastMergeSourceCommit, completion)
+		case autoCompleteAction:
+			updated, err = c.gitclient.SetAutoComplete(c.ctx, c.project, repositoryId, pr.Id, completion)
+		case abandonAction:
+			updated, err = c.gitclient.AbandonPullRequest(c.ctx, c.project, repositoryId, pr.Id)
+		}
+		if err != nil {
+			return teamsg.PullRequestUpdatedMsg{Action: action, PullRequest: pr, Err: err}
+		}
+		return teamsg.PullRequestUpdatedMsg{Action: action, PullRequest: pullRequestItem(updated, pr.PolicyState)}
+	}
+}
+
+func (c *PullRequestCompletionSection) Update(msg tea.Msg) (Section, tea.Cmd) {
+	switch msg := msg.(type) {
+	case teamsg.PullRequestSelectedMsg:
+		c.pr = listitems.PullRequestItem(msg)
+		c.cursor, c.confirmPrompt, c.errorMessage = completionActionField, "", ""
+		// the message is left to Azure DevOps unless the user types one
+		c.messageInput.Reset()
+		c.focusCursor()
+		return c, nil
+	case teamsg.PullRequestUpdatedMsg:
+		if msg.PullRequest.Id != c.pr.Id {
+			return c, nil
+		}
+		c.submitting = false
+		if msg.Err != nil {
+			c.logger.Error("error updating pull request", "pullRequestId", msg.PullRequest.Id, "action", msg.Action, "error", msg.Err)
+			c.errorMessage = msg.Err.Error()
+			return c, nil
+		}
+		c.logger.Info("pull request updated", "pullRequestId", msg.PullRequest.Id, "action", msg.Action)
+		c.errorMessage = ""
+		return c, nil
+	case tea.KeyPressMsg:
+		if !c.focused || c.submitting {
+			return c, nil
+		}
+		if c.confirmPrompt != "" {
+			c.confirmPrompt = ""
+			if msg.String() == "y" {
+				return c, c.submit()
+			}
+			return c, nil
+		}
+		return c, c.updateField(msg)
+	}
+	return c, nil
+}
+
+// updateField handles the keys of the form, ←/→ and space change the choices and enter asks to send the action
+func (c *PullRequestCompletionSection) updateField(msg tea.KeyPressMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "shift+tab":
+		c.cursor = max(c.cursor-1, 0)
+		c.focusCursor()
+		return nil
+	case "down":
+		c.cursor = min(c.cursor+1, completionFields-1)
+		c.focusCursor()
+		return nil
+	case "enter":
+		if c.pr.Status != string(git.PullRequestStatusValues.Active) {
+			c.errorMessage = fmt.Sprintf("#%d is %s, only active pull requests can be completed or abandoned", c.pr.Id, c.pr.Status)
+			return nil
+		}
+		c.errorMessage = ""
+		c.confirmPrompt = c.confirmation()
+		return nil
+	}
+	step := 0
+	switch msg.String() {
+	case "right", "space":
+		step = 1
+	case "left":
+		step = -1
+	}
+	switch c.cursor {
+	case completionActionField:
+		c.action = (c.action + step + len(pullRequestActions)) % len(pullRequestActions)
+	case completionStrategyField:
+		c.strategy = (c.strategy + step + len(mergeStrategies)) % len(mergeStrategies)
+	case completionDeleteBranchField:
+		c.deleteSourceBranch = c.deleteSourceBranch != (step != 0)
+	case completionWorkItemsField:
+		c.transitionWorkItems = c.transitionWorkItems != (step != 0)
+	case completionMessageField:
+		var cmd tea.Cmd
+		c.messageInput, cmd = c.messageInput.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+func (c *PullRequestCompletionSection) View() string {
+	labelStyle := lipgloss.NewStyle().Bold(true)
+	greyStyle := lipgloss.NewStyle().Foreground(styles.Grey)
+	abandoning := pullRequestActions[c.action] == abandonAction
+	label := func(field int, text string) string {
+		switch {
+		case c.focused && c.cursor == field:
+			return labelStyle.Foreground(styles.Yellow).Render(text)
+		case abandoning && field != completionActionField:
+			// merging options don't apply to an abandoned pull request
+			return greyStyle.Render(text)
+		}
+		return labelStyle.Render(text)
+	}
+	choices := func(names []string, selected int) string {
+		rendered := make([]string, 0, len(names))
+		for i, name := range names {
+			if i == selected {
+				rendered = append(rendered, "["+name+"]")
+			} else {
+				rendered = append(rendered, greyStyle.Render(" "+name+" "))
+			}
+		}
+		return strings.Join(rendered, " ")
+	}
+	checkbox := func(checked bool) string {
+		if checked {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	strategies := make([]string, 0, len(mergeStrategies))
+	for _, strategy := range mergeStrategies {
+		strategies = append(strategies, strategy.name)
+	}
+	lines := []string{
+		styles.TitleStyle.Render(fmt.Sprintf("Complete #%d", c.pr.Id)),
+		label(completionActionField, "Action ") + choices(pullRequestActions, c.action),
+		label(completionStrategyField, "Merge type ") + choices(strategies, c.strategy),
+		label(completionDeleteBranchField, "Delete source branch ") + checkbox(c.deleteSourceBranch),
+		label(completionWorkItemsField, "Complete linked work items ") + checkbox(c.transitionWorkItems),
+		label(completionMessageField, "Merge commit message"),
+		c.messageInput.View(),
+	}
+	var status string
+	switch {
+	case c.submitting:
+		status = fmt.Sprintf("Sending %s...", pullRequestActions[c.action])
+	case c.confirmPrompt != "":
+		status = lipgloss.NewStyle().Foreground(styles.Yellow).Width(c.width).Render(c.confirmPrompt)
+	case c.errorMessage != "":
+		status = lipgloss.NewStyle().Foreground(styles.Red).Width(c.width).Render(c.errorMessage)
+	}
+	lines = append(lines, status, c.help)
+	secView := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	if c.focused {
+		return styles.ActiveStyle.Render(secView)
+	}
+	return styles.InactiveStyle.Render(secView)
+}
@@ -0,0 +1,141 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/teamsg"
+	"azdoext/pkg/utils"
+	"context"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+// completionClient records how the pull request was finished, embedding the interface for the methods it doesn't use
+type completionClient struct {
+	azdo.GitClientInterface
+	action     string
+	completion azdo.PullRequestCompletion
+	// lastMergeSourceCommit is the commit the pull request was completed with
+	lastMergeSourceCommit string
+}
+
+func (c *completionClient) update(action string, pullRequestId int, completion azdo.PullRequestCompletion, status git.PullRequestStatus) (git.GitPullRequest, error) {
+	c.action, c.completion = action, completion
+	return git.GitPullRequest{PullRequestId: utils.Ptr(pullRequestId), Title: utils.Ptr("Add login"), Status: &status}, nil
+}
+
+func (c *completionClient) CompletePullRequest(ctx context.Context, project, repositoryId string, pullRequestId int, lastMergeSourceCommit string, completion azdo.PullRequestCompletion) (git.GitPullRequest, error) {
+	c.lastMergeSourceCommit = lastMergeSourceCommit
+	return c.update("complete", pullRequestId, completion, git.PullRequestStatusValues.Completed)
+}
+
+func (c *completionClient) SetAutoComplete(ctx context.Context, project, repositoryId string, pullRequestId int, completion azdo.PullRequestCompletion) (git.GitPullRequest, error) {
+	return c.update("autoComplete", pullRequestId, completion, git.PullRequestStatusValues.Active)
+}
+
+func (c *completionClient) AbandonPullRequest(ctx context.Context, project, repositoryId string, pullRequestId int) (git.GitPullRequest, error) {
+	return c.update("abandon", pullRequestId, azdo.PullRequestCompletion{}, git.PullRequestStatusValues.Abandoned)
+}
+
+func newTestPRCompletion(status string) (*PullRequestCompletionSection, *completionClient) {
+	client := &completionClient{}
+	section := NewPullRequestCompletion(context.Background(), PullRequestCompletion, client, azdo.Config{RepositoryId: uuid.New()}).(*PullRequestCompletionSection)
+	section.Focus()
+	section.Update(teamsg.PullRequestSelectedMsg(listitems.PullRequestItem{Id: 7, Title: "Add login", Status: status, LastMergeSourceCommit: "abc123"}))
+	return section, client
+}
+
+func press(section Section, keys ...string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		switch k {
+		case "down":
+			_, cmd = section.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+		case "left":
+			_, cmd = section.Update(tea.KeyPressMsg{Code: tea.KeyLeft})
+		case "right":
+			_, cmd = section.Update(tea.KeyPressMsg{Code: tea.KeyRight})
+		case "space":
+			_, cmd = section.Update(tea.KeyPressMsg{Code: tea.KeySpace, Text: " "})
+		case "enter":
+			_, cmd = section.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+		default:
+			_, cmd = section.Update(tea.KeyPressMsg{Code: rune(k[0]), Text: k})
+		}
+	}
+	return cmd
+}
+
+func TestPRCompletionSetsAutoComplete(t *testing.T) {
+	section, client := newTestPRCompletion("active")
+	if got := section.messageInput.Value(); got != "" {
+		t.Errorf("merge commit message = %q, want it left to Azure DevOps", got)
+	}
+
+	// squash, delete the source branch and keep the linked work items as they are
+	press(section, "down", "right", "down", "space", "down", "space")
+	if cmd := press(section, "enter", "n"); cmd != nil || section.confirmPrompt != "" {
+		t.Fatal("expected n to dismiss the confirmation without sending anything")
+	}
+	cmd := press(section, "enter", "y")
+	if cmd == nil {
+		t.Fatal("expected y to send the action")
+	}
+	msg := cmd().(teamsg.PullRequestUpdatedMsg)
+	if msg.Err != nil || msg.PullRequest.Id != 7 {
+		t.Fatalf("unexpected result %+v", msg)
+	}
+	want := azdo.PullRequestCompletion{
+		MergeStrategy:       git.GitPullRequestMergeStrategyValues.Squash,
+		DeleteSourceBranch:  true,
+		TransitionWorkItems: false,
+	}
+	if client.action != "autoComplete" || client.completion != want {
+		t.Errorf("sent %s with %+v, want autoComplete with %+v", client.action, client.completion, want)
+	}
+}
+
+func TestPRCompletionActions(t *testing.T) {
+	section, client := newTestPRCompletion("active")
+	// ← wraps around the choices
+	press(section, "left", "down", "left")
+	if pullRequestActions[section.action] != abandonAction || mergeStrategies[section.strategy].name != "semi-linear" {
+		t.Fatalf("action %q and strategy %q, want abandon and semi-linear", pullRequestActions[section.action], mergeStrategies[section.strategy].name)
+	}
+	press(section, "enter", "y")()
+	if client.action != "abandon" {
+		t.Errorf("sent %s, want abandon", client.action)
+	}
+
+	// completing sends the commit the pull request was shown with, so that a later push makes it fail
+	section, client = newTestPRCompletion("active")
+	press(section, "right", "enter", "y")()
+	if client.action != "complete" || client.lastMergeSourceCommit != "abc123" {
+		t.Errorf("sent %s with commit %q, want complete with abc123", client.action, client.lastMergeSourceCommit)
+	}
+
+	section, _ = newTestPRCompletion("completed")
+	if cmd := press(section, "enter", "y"); cmd != nil || section.errorMessage == "" {
+		t.Error("expected a completed pull request not to be completed again")
+	}
+}
+
+func TestPRCompletionOverridesMergeCommitMessage(t *testing.T) {
+	section, client := newTestPRCompletion("active")
+	press(section, "down", "down", "down", "down")
+	press(section, strings.Split("Release", "")...)
+	press(section, "enter", "y")()
+	if client.completion.MergeCommitMessage != "Release" {
+		t.Errorf("merge commit message = %q, want the one typed", client.completion.MergeCommitMessage)
+	}
+
+	// selecting the pull request again leaves the message to Azure DevOps
+	section.Update(teamsg.PullRequestSelectedMsg(listitems.PullRequestItem{Id: 7, Status: "active"}))
+	if got := section.messageInput.Value(); got != "" {
+		t.Errorf("merge commit message = %q, want it cleared", got)
+	}
+}
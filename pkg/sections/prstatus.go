@@ -0,0 +1,244 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/logger"
+	"azdoext/pkg/styles"
+	"azdoext/pkg/teamsg"
+	"azdoext/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/policy"
+)
+
+// PRStatusHeight is the room the PR status takes below the pull request details, borders included
+const PRStatusHeight = 14
+
+// policyPollInterval is how often the evaluations are fetched again while a blocking policy is queued or running
+const policyPollInterval = 10 * time.Second
+
+// PullRequestStatusSection lists the branch policies evaluated on a pull request, e.g. build validations, minimum
+// reviewers, linked work items and comment resolution, and opens the run of a build validation
+type PullRequestStatusSection struct {
+	logger            *logger.Logger
+	hidden            bool
+	focused           bool
+	ctx               context.Context
+	policyclient      azdo.PolicyClientInterface
+	sectionIdentifier SectionName
+	pullRequestId     int
+	policylist        list.Model
+	state             string
+	loading           bool
+	errorMessage      string
+	help              string
+	// request identifies the latest fetch, the messages of the older ones are dropped
+	request int
+}
+
+func NewPullRequestStatus(ctx context.Context, secid SectionName, policyclient azdo.PolicyClientInterface) Section {
+	logger := logger.NewLogger("prstatus")
+	policylist := list.New([]list.Item{}, listitems.PolicyItemDelegate{}, 0, 0)
+	policylist.SetShowTitle(false)
+	policylist.SetShowStatusBar(false)
+	policylist.SetShowHelp(false)
+	policylist.SetShowPagination(false)
+	policylist.SetFilteringEnabled(false)
+	policylist.DisableQuitKeybindings()
+	help := styles.ShortHelpStyle.Render("↵ open build validation run • c complete • r refresh • esc back")
+	return &PullRequestStatusSection{
+		logger:            logger,
+		ctx:               ctx,
+		policyclient:      policyclient,
+		sectionIdentifier: secid,
+		policylist:        policylist,
+		help:              help,
+	}
+}
+
+func (s *PullRequestStatusSection) GetSectionIdentifier() SectionName {
+	return s.sectionIdentifier
+}
+
+func (s *PullRequestStatusSection) IsHidden() bool {
+	return s.hidden
+}
+
+func (s *PullRequestStatusSection) IsFocused() bool {
+	return s.focused
+}
+
+func (s *PullRequestStatusSection) Hide() {
+	s.hidden = true
+	s.focused = false
+}
+
+func (s *PullRequestStatusSection) Show() {
+	s.hidden = false
+}
+
+func (s *PullRequestStatusSection) Focus() {
+	s.Show()
+	s.focused = true
+}
+
+func (s *PullRequestStatusSection) Blur() {
+	s.focused = false
+}
+
+func (s *PullRequestStatusSection) SetDimensions(width, height int) {
+	// the status sits below the pull request details, next to the pull request list
+	s.policylist.SetWidth(max(styles.Width-max(styles.Width/2, styles.DefaultSectionWidth+20)-3, styles.DefaultSectionWidth))
+	// borders, title, state, status and help
+	s.policylist.SetHeight(max(PRStatusHeight-6, 1))
+}
+
+// fetchPolicies loads the evaluations of the pull request after waiting, a fetch is pending until its message arrives
+func (s *PullRequestStatusSection) fetchPolicies(wait time.Duration) tea.Cmd {
+	s.loading = true
+	s.request++
+	request := s.request
+	pullRequestId := s.pullRequestId
+	return func() tea.Msg {
+		if err := utils.SleepWithContext(s.ctx, wait); err != nil {
+			return nil
+		}
+		evaluations, err := s.policyclient.GetPolicyEvaluations(s.ctx, pullRequestId)
+		return teamsg.PullRequestPoliciesMsg{Request: request, PullRequestId: pullRequestId, Evaluations: evaluations, Err: err}
+	}
+}
+
+func (s *PullRequestStatusSection) Update(msg tea.Msg) (Section, tea.Cmd) {
+	switch msg := msg.(type) {
+	case teamsg.PullRequestSelectedMsg:
+		s.pullRequestId = msg.Id
+		s.state, s.errorMessage = "", ""
+		s.policylist.Select(0)
+		return s, tea.Batch(s.policylist.SetItems([]list.Item{}), s.fetchPolicies(0))
+	case teamsg.PullRequestPoliciesMsg:
+		if msg.Request != s.request {
+			return s, nil
+		}
+		s.loading = false
+		if msg.Err != nil {
+			if errors.Is(msg.Err, context.Canceled) {
+				return s, nil
+			}
+			s.logger.Error("error fetching policy evaluations", "pullRequestId", msg.PullRequestId, "error", msg.Err)
+			s.errorMessage = msg.Err.Error()
+			return s, nil
+		}
+		s.errorMessage = ""
+		s.state = azdo.PolicyState(msg.Evaluations)
+		cmds := []tea.Cmd{s.policylist.SetItems(policyItems(msg.Evaluations))}
+		if s.state == string(policy.PolicyEvaluationStatusValues.Running) {
+			cmds = append(cmds, s.fetchPolicies(policyPollInterval))
+		}
+		return s, tea.Batch(cmds...)
+	case tea.KeyPressMsg:
+		if !s.focused {
+			return s, nil
+		}
+		switch msg.String() {
+		case "r":
+			// a pending fetch, e.g. the next poll, already refreshes them
+			if s.loading {
+				return s, nil
+			}
+			return s, s.fetchPolicies(0)
+		case "enter":
+			item, ok := s.policylist.SelectedItem().(listitems.PolicyItem)
+			if !ok {
+				return s, nil
+			}
+			if item.BuildId == 0 {
+				s.errorMessage = fmt.Sprintf("%s has no build validation run", item.Name)
+				return s, nil
+			}
+			s.errorMessage = ""
+			return s, func() tea.Msg {
+				return teamsg.PipelineRunIdMsg{RunId: item.BuildId, PipelineName: item.Name, Status: runStatus(item.Status)}
+			}
+		}
+		policylist, cmd := s.policylist.Update(msg)
+		s.policylist = policylist
+		return s, cmd
+	}
+	return s, nil
+}
+
+func policyItems(evaluations []azdo.PolicyEvaluation) []list.Item {
+	items := make([]list.Item, 0, len(evaluations))
+	for _, evaluation := range evaluations {
+		items = append(items, listitems.PolicyItem{
+			Name:       evaluation.Name,
+			Status:     evaluation.Status,
+			IsBlocking: evaluation.IsBlocking,
+			BuildId:    evaluation.BuildId,
+			Symbol:     utils.Ptr(policySymbol(evaluation.Status)),
+		})
+	}
+	return items
+}
+
+func policySymbol(status string) string {
+	switch policy.PolicyEvaluationStatus(status) {
+	case policy.PolicyEvaluationStatusValues.Approved:
+		return styles.SymbolMap["succeeded"].String()
+	case policy.PolicyEvaluationStatusValues.Rejected, policy.PolicyEvaluationStatusValues.Broken:
+		return styles.SymbolMap["failed"].String()
+	case policy.PolicyEvaluationStatusValues.Queued, policy.PolicyEvaluationStatusValues.Running:
+		return styles.SymbolMap["pending"].String()
+	}
+	return styles.SymbolMap["noRuns"].String()
+}
+
+// runStatus is the status of the run a build validation queued, as the pipeline run page expects it
+func runStatus(policyStatus string) string {
+	switch policy.PolicyEvaluationStatus(policyStatus) {
+	case policy.PolicyEvaluationStatusValues.Queued:
+		return string(build.BuildStatusValues.NotStarted)
+	case policy.PolicyEvaluationStatusValues.Running:
+		return string(build.BuildStatusValues.InProgress)
+	}
+	return string(build.BuildStatusValues.Completed)
+}
+
+// stateLine sums up the policies the way the pull request list does
+func (s *PullRequestStatusSection) stateLine() string {
+	switch policy.PolicyEvaluationStatus(s.state) {
+	case policy.PolicyEvaluationStatusValues.Approved:
+		return policySymbol(s.state) + " all blocking policies approved"
+	case policy.PolicyEvaluationStatusValues.Rejected:
+		return policySymbol(s.state) + " a blocking policy was rejected"
+	case policy.PolicyEvaluationStatusValues.Running:
+		return policySymbol(s.state) + " waiting on blocking policies"
+	}
+	return styles.SymbolMap["noRuns"].String() + " no blocking policy to wait on"
+}
+
+func (s *PullRequestStatusSection) View() string {
+	title := styles.TitleStyle.Render(fmt.Sprintf("Status of #%d", s.pullRequestId))
+	var status string
+	switch {
+	case s.errorMessage != "":
+		status = lipgloss.NewStyle().Foreground(styles.Red).Width(s.policylist.Width()).Render(s.errorMessage)
+	case len(s.policylist.Items()) == 0 && s.loading:
+		status = "Loading policies..."
+	case len(s.policylist.Items()) == 0:
+		status = "no policies"
+	}
+	secView := lipgloss.JoinVertical(lipgloss.Left, title, s.stateLine(), s.policylist.View(), status, s.help)
+	if s.focused {
+		return styles.ActiveStyle.Render(secView)
+	}
+	return styles.InactiveStyle.Render(secView)
+}
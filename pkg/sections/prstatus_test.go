@@ -0,0 +1,104 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/teamsg"
+	"context"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+type policyClient struct {
+	evaluations []azdo.PolicyEvaluation
+}
+
+func (c *policyClient) GetPolicyEvaluations(ctx context.Context, pullRequestId int) ([]azdo.PolicyEvaluation, error) {
+	return c.evaluations, nil
+}
+
+func newTestPRStatus(t *testing.T, evaluations []azdo.PolicyEvaluation) *PullRequestStatusSection {
+	t.Helper()
+	client := &policyClient{evaluations: evaluations}
+	section := NewPullRequestStatus(context.Background(), PullRequestStatus, client).(*PullRequestStatusSection)
+	section.SetDimensions(0, PRStatusHeight)
+	section.Focus()
+	section.Update(teamsg.PullRequestSelectedMsg(listitems.PullRequestItem{Id: 7}))
+	section.Update(teamsg.PullRequestPoliciesMsg{Request: section.request, PullRequestId: 7, Evaluations: evaluations})
+	return section
+}
+
+func TestPRStatusOpensBuildValidationRun(t *testing.T) {
+	section := newTestPRStatus(t, []azdo.PolicyEvaluation{
+		{Name: "ci", Status: "running", IsBlocking: true, BuildId: 345},
+		{Name: "Minimum number of reviewers", Status: "approved", IsBlocking: true},
+	})
+
+	_, cmd := section.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected enter on a build validation to open its run")
+	}
+	want := teamsg.PipelineRunIdMsg{RunId: 345, PipelineName: "ci", Status: "inProgress"}
+	if got := cmd(); got != want {
+		t.Errorf("enter = %+v, want %+v", got, want)
+	}
+
+	section.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	if _, cmd := section.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); cmd != nil || section.errorMessage == "" {
+		t.Errorf("expected enter on a policy without run to explain it, got cmd %v and message %q", cmd, section.errorMessage)
+	}
+}
+
+func TestPRStatusPollsWhileRunning(t *testing.T) {
+	section := newTestPRStatus(t, []azdo.PolicyEvaluation{{Name: "ci", Status: "queued", IsBlocking: true, BuildId: 1}})
+	if !section.loading {
+		t.Error("expected the evaluations to be fetched again while a blocking policy is queued")
+	}
+	// the pending poll already refreshes them
+	if _, cmd := section.Update(tea.KeyPressMsg{Code: 'r', Text: "r"}); cmd != nil {
+		t.Error("expected refresh to wait for the pending poll")
+	}
+
+	section = newTestPRStatus(t, []azdo.PolicyEvaluation{{Name: "ci", Status: "approved", IsBlocking: true}})
+	if section.loading {
+		t.Error("expected no poll once the blocking policies are approved")
+	}
+
+	// evaluations of a pull request that is no longer shown are dropped
+	section.Update(teamsg.PullRequestPoliciesMsg{Request: section.request - 1, PullRequestId: 8, Evaluations: []azdo.PolicyEvaluation{{Name: "other"}}})
+	if item := section.policylist.Items()[0].(listitems.PolicyItem); item.Name != "ci" {
+		t.Errorf("policies = %+v, want the ones of #7", item)
+	}
+}
+
+func TestPRStatusReselectDropsPendingPoll(t *testing.T) {
+	running := []azdo.PolicyEvaluation{{Name: "ci", Status: "running", IsBlocking: true, BuildId: 1}}
+	section := newTestPRStatus(t, running)
+	pending := section.request
+
+	// selecting the same pull request again, e.g. after completing it, starts a new fetch
+	section.Update(teamsg.PullRequestSelectedMsg(listitems.PullRequestItem{Id: 7}))
+	if _, cmd := section.Update(teamsg.PullRequestPoliciesMsg{Request: pending, PullRequestId: 7, Evaluations: running}); cmd != nil {
+		t.Error("expected the poll of the previous selection to be dropped instead of polling again")
+	}
+	if !section.loading {
+		t.Error("expected the fetch of the new selection to stay pending")
+	}
+	if _, cmd := section.Update(teamsg.PullRequestPoliciesMsg{Request: section.request, PullRequestId: 7, Evaluations: running}); cmd == nil {
+		t.Error("expected the new selection to keep polling while the build validation runs")
+	}
+}
+
+func TestRunStatus(t *testing.T) {
+	for policyStatus, want := range map[string]string{
+		"queued":   "notStarted",
+		"running":  "inProgress",
+		"approved": "completed",
+		"rejected": "completed",
+	} {
+		if got := runStatus(policyStatus); got != want {
+			t.Errorf("runStatus(%q) = %q, want %q", policyStatus, got, want)
+		}
+	}
+}
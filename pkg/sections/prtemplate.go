@@ -0,0 +1,84 @@
+package sections
+
+import (
+	"azdoext/pkg/gitexec"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// prTemplateFolders are the folders Azure Repos looks for PR templates in, in order, the root of the repository last
+var prTemplateFolders = []string{".azuredevops", ".vsts", "docs", ""}
+
+var prTemplateExtensions = []string{".md", ".txt"}
+
+// prTemplateCandidates lists the paths, relative to the root of the repository, a PR template targeting the branch
+// can be found at, in the order Azure Repos resolves them: the templates of the branch first, then the ones of its
+// prefix (feature for feature/login) and lastly the default templates
+func prTemplateCandidates(targetBranch string) []string {
+	branch := strings.TrimPrefix(targetBranch, "refs/heads/")
+	var names []string
+	if branch != "" {
+		names = append(names, branch)
+		if prefix, _, ok := strings.Cut(branch, "/"); ok {
+			names = append(names, prefix)
+		}
+	}
+	var candidates []string
+	for _, name := range names {
+		for _, folder := range prTemplateFolders {
+			for _, ext := range prTemplateExtensions {
+				candidates = append(candidates, filepath.Join(folder, "pull_request_template", "branches", name+ext))
+			}
+		}
+	}
+	for _, folder := range prTemplateFolders {
+		for _, ext := range prTemplateExtensions {
+			candidates = append(candidates, filepath.Join(folder, "pull_request_template"+ext))
+		}
+	}
+	return candidates
+}
+
+// FindPRTemplate returns the content of the PR template of the repository at root that applies to the target branch,
+// empty when the repository has none
+func FindPRTemplate(root, targetBranch string) (string, error) {
+	for _, candidate := range prTemplateCandidates(targetBranch) {
+		content, err := os.ReadFile(filepath.Join(root, candidate))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("error reading PR template %s: %v", candidate, err)
+		}
+		return strings.TrimSpace(strings.ReplaceAll(string(content), "\r\n", "\n")), nil
+	}
+	return "", nil
+}
+
+// seedFromCommits builds the title and description of a PR from the messages of its commits: a single commit gives
+// both, several commits are listed under a title made from the name of the source branch
+func seedFromCommits(sourceBranch string, commits []gitexec.CommitMessage) (title, description string) {
+	switch len(commits) {
+	case 0:
+		return "", ""
+	case 1:
+		return commits[0].Subject, commits[0].Body
+	}
+	branch := strings.TrimPrefix(sourceBranch, "refs/heads/")
+	if i := strings.LastIndex(branch, "/"); i >= 0 {
+		branch = branch[i+1:]
+	}
+	title = strings.Join(strings.FieldsFunc(branch, func(r rune) bool { return r == '-' || r == '_' }), " ")
+	if title != "" {
+		title = strings.ToUpper(title[:1]) + title[1:]
+	}
+	lines := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		lines = append(lines, "- "+commit.Subject)
+	}
+	return title, strings.Join(lines, "\n")
+}
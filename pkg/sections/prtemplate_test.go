@@ -0,0 +1,95 @@
+package sections
+
+import (
+	"azdoext/pkg/gitexec"
+	"azdoext/pkg/teamsg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTemplate(t *testing.T, root, path, content string) {
+	t.Helper()
+	full := filepath.Join(root, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindPRTemplate(t *testing.T) {
+	root := t.TempDir()
+	writeTemplate(t, root, "pull_request_template.md", "root")
+	writeTemplate(t, root, ".azuredevops/pull_request_template.md", "default\r\n")
+	writeTemplate(t, root, "docs/pull_request_template/branches/release.md", "release")
+	writeTemplate(t, root, ".vsts/pull_request_template/branches/release/1.0.txt", "release 1.0")
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"refs/heads/main", "default"},
+		{"refs/heads/release/2.0", "release"},
+		{"refs/heads/release/1.0", "release 1.0"},
+		{"release", "release"},
+	}
+	for _, test := range tests {
+		got, err := FindPRTemplate(root, test.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("FindPRTemplate(%q) = %q, want %q", test.target, got, test.want)
+		}
+	}
+
+	got, err := FindPRTemplate(t.TempDir(), "refs/heads/main")
+	if err != nil || got != "" {
+		t.Errorf("FindPRTemplate without templates = %q, %v, want no template", got, err)
+	}
+}
+
+func TestSeedFromCommits(t *testing.T) {
+	title, description := seedFromCommits("refs/heads/feature/login", []gitexec.CommitMessage{{Subject: "Add login", Body: "with a form"}})
+	if title != "Add login" || description != "with a form" {
+		t.Errorf("single commit seeded %q, %q", title, description)
+	}
+
+	title, description = seedFromCommits("refs/heads/feature/add-login_form", []gitexec.CommitMessage{
+		{Subject: "Add login"},
+		{Subject: "Fix typo", Body: "ignored"},
+	})
+	if title != "Add login form" {
+		t.Errorf("title = %q, want the name of the branch", title)
+	}
+	if description != "- Add login\n- Fix typo" {
+		t.Errorf("description = %q, want the commit subjects", description)
+	}
+}
+
+func TestPrefillKeepsTypedText(t *testing.T) {
+	pr := newTestPRSection()
+	pr.Update(teamsg.PRTemplateMsg{Target: "refs/heads/main", Template: "## Checklist"})
+	if got := pr.textarea.Value(); got != "\n## Checklist" {
+		t.Fatalf("textarea = %q, want the template below an empty title", got)
+	}
+
+	pr.Update(teamsg.PRCommitsMsg{Commits: []gitexec.CommitMessage{{Subject: "Add login", Body: "with a form"}}})
+	if got := pr.textarea.Value(); got != "Add login\nwith a form\n\n## Checklist" {
+		t.Fatalf("textarea = %q, want the commit followed by the template", got)
+	}
+
+	// a template of another target arriving late is dropped
+	pr.Update(teamsg.PRTemplateMsg{Target: "refs/heads/release", Template: "release"})
+	if pr.template != "## Checklist" {
+		t.Errorf("template = %q, want the one of the target branch", pr.template)
+	}
+
+	pr.textarea.SetValue("typed")
+	pr.Update(teamsg.PRTemplateMsg{Target: "refs/heads/main", Template: "other"})
+	if got := pr.textarea.Value(); got != "typed" {
+		t.Errorf("textarea = %q, typed text shouldn't be replaced by a template", got)
+	}
+}
@@ -2,21 +2,45 @@ package sections
 
 import (
 	"azdoext/pkg/azdo"
+	"azdoext/pkg/gitexec"
 	"azdoext/pkg/logger"
 	"azdoext/pkg/styles"
 	"azdoext/pkg/teamsg"
 	"azdoext/pkg/utils"
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 
+	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/textarea"
+	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
 )
 
+// the fields of the PR form, in order, the title and description come first
+const (
+	prTextField = iota
+	prTargetField
+	prDraftField
+	prRequiredReviewersField
+	prOptionalReviewersField
+	prLabelsField
+	prWorkItemsField
+	prFields
+)
+
+// prFormHeight is the room the fields below the title and description take
+const prFormHeight = 14
+
 type PRSection struct {
 	errorDisplayed    bool
 	logger            *logger.Logger
@@ -24,13 +48,36 @@ type PRSection struct {
 	focused           bool
 	title             string
 	textarea          textarea.Model
+	ctx               context.Context
 	project           string
 	repositoryId      uuid.UUID
 	currentBranch     string
 	defaultBranch     string
 	gitclient         azdo.GitClientInterface
+	teamsclient       azdo.TeamsClientInterface
 	sectionIdentifier SectionName
 	help              string
+
+	cursor           int
+	targetInput      textinput.Model
+	isDraft          bool
+	reviewerInputs   map[int]*textinput.Model
+	reviewers        map[int][]azdo.Identity
+	labelsInput      textinput.Model
+	workItemsInput   textinput.Model
+	identities       []azdo.Identity
+	formLoading      bool
+	formLoaded       bool
+	formErrorMessage string
+	loadErrorMessage string
+
+	// the text the textarea was prefilled with, replaced as long as it's left untouched
+	prefilled       string
+	template        string
+	templateTarget  string
+	seedTitle       string
+	seedDescription string
+	seeding         bool
 }
 
 func (pr *PRSection) IsHidden() bool {
@@ -41,10 +88,10 @@ func (pr *PRSection) IsFocused() bool {
 	return pr.focused
 }
 
-func NewPRSection(secid SectionName, gitclient azdo.GitClientInterface, azdoconfig azdo.Config) Section {
-	logger := logger.NewLogger("pr.log")
+func NewPRSection(ctx context.Context, secid SectionName, gitclient azdo.GitClientInterface, teamsclient azdo.TeamsClientInterface, azdoconfig azdo.Config) Section {
+	logger := logger.NewLogger("pr")
 	title := "Open PR:"
-	styledHelpText := styles.ShortHelpStyle.Render("ctrl+s save and open PR")
+	styledHelpText := styles.ShortHelpStyle.Render("ctrl+s open PR • ctrl+g from commits • ↑/↓ fields • → complete • ↵ add reviewer • space draft")
 	ta := textarea.New()
 	ta.SetHeight(styles.ActiveStyle.GetHeight() - 2)
 	ta.Placeholder = "Title and description"
@@ -55,35 +102,77 @@ func NewPRSection(secid SectionName, gitclient azdo.GitClientInterface, azdoconf
 			return " Desc:"
 		}
 	})
+	newInput := func(placeholder string) textinput.Model {
+		input := textinput.New()
+		input.Prompt = ""
+		input.Placeholder = placeholder
+		input.SetWidth(styles.DefaultSectionWidth + 20)
+		// tab switches sections, so suggestions are accepted with → and cycled through with ctrl+n/ctrl+p
+		input.KeyMap.AcceptSuggestion = key.NewBinding(key.WithKeys("right"))
+		input.KeyMap.NextSuggestion = key.NewBinding(key.WithKeys("ctrl+n"))
+		input.KeyMap.PrevSuggestion = key.NewBinding(key.WithKeys("ctrl+p"))
+		return input
+	}
+	targetInput := newInput("branch")
+	targetInput.ShowSuggestions = true
+	targetInput.SetValue(strings.TrimPrefix(azdoconfig.DefaultBranch, "refs/heads/"))
+	requiredInput, optionalInput := newInput("name or email"), newInput("name or email")
+	requiredInput.ShowSuggestions, optionalInput.ShowSuggestions = true, true
 	return &PRSection{
 		logger:            logger,
 		title:             title,
 		textarea:          ta,
+		ctx:               ctx,
 		sectionIdentifier: secid,
 		project:           azdoconfig.ProjectId,
 		repositoryId:      azdoconfig.RepositoryId,
-		currentBranch:     formatBranchName(azdoconfig.CurrentBranch),
+		currentBranch:     utils.FormatBranchName(azdoconfig.CurrentBranch),
 		defaultBranch:     azdoconfig.DefaultBranch,
 		gitclient:         gitclient,
+		teamsclient:       teamsclient,
 		help:              styledHelpText,
+		targetInput:       targetInput,
+		reviewerInputs: map[int]*textinput.Model{
+			prRequiredReviewersField: &requiredInput,
+			prOptionalReviewersField: &optionalInput,
+		},
+		reviewers:      make(map[int][]azdo.Identity),
+		labelsInput:    newInput("comma separated"),
+		workItemsInput: newInput("ids, e.g. 123, 456"),
 	}
 }
 
-// formatBranchName adds refs/heads/ prefix to the branch name if it is not already present
-func formatBranchName(branch string) string {
-	if strings.HasPrefix(branch, "refs/heads/") {
-		return branch
-	}
-	return "refs/heads/" + branch
-}
-
 func (pr *PRSection) GetSectionIdentifier() SectionName {
 	return pr.sectionIdentifier
 }
 
 func (pr *PRSection) SetDimensions(width, height int) {
 	pr.textarea.SetWidth(styles.DefaultSectionWidth + 20)
-	pr.textarea.SetHeight(height - 4)
+	pr.textarea.SetHeight(max(height-4-prFormHeight, 3))
+}
+
+// fetchFormData loads the branches the PR can target and the identities that can review it
+func (pr *PRSection) fetchFormData() tea.Cmd {
+	pr.formLoading = true
+	return func() tea.Msg {
+		var (
+			wg                         sync.WaitGroup
+			branches                   []string
+			identities                 []azdo.Identity
+			branchesErr, identitiesErr error
+		)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			branches, branchesErr = pr.gitclient.GetBranches(pr.ctx, pr.project, pr.repositoryId.String())
+		}()
+		go func() {
+			defer wg.Done()
+			identities, identitiesErr = pr.teamsclient.GetIdentities(pr.ctx)
+		}()
+		wg.Wait()
+		return teamsg.PRFormDataMsg{Branches: branches, Identities: identities, Err: errors.Join(branchesErr, identitiesErr)}
+	}
 }
 
 func (pr *PRSection) Update(msg tea.Msg) (Section, tea.Cmd) {
@@ -96,31 +185,102 @@ func (pr *PRSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 					pr.textarea.Blur()
 				}
 				return pr, func() tea.Msg { return teamsg.SubmitPRMsg(pr.textarea.Value()) }
+			case "ctrl+g":
+				if pr.seeding {
+					return pr, nil
+				}
+				return pr, pr.fetchCommits()
 			}
 			if pr.errorDisplayed && msg.String() == "enter" {
 				pr.textarea.Reset()
 				return pr, nil
 			}
-
+			if pr.cursor != prTextField {
+				return pr, pr.updateField(msg)
+			}
+			if msg.String() == "down" && pr.onLastLine() {
+				pr.moveCursor(1)
+				return pr, nil
+			}
+		}
+	case teamsg.SubmitChoiceMsg:
+		if string(msg) == string(Options.OpenPR) {
+			var cmds []tea.Cmd
+			if !pr.formLoaded && !pr.formLoading {
+				cmds = append(cmds, pr.fetchFormData())
+			}
+			cmds = append(cmds, pr.fetchTemplateIfTargetChanged())
+			return pr, tea.Batch(cmds...)
+		}
+	case teamsg.PRTemplateMsg:
+		if msg.Target != pr.targetBranch() {
+			return pr, nil
+		}
+		if msg.Err != nil {
+			pr.logger.Error("error loading the PR template", "target", msg.Target, "error", msg.Err)
+			pr.formErrorMessage = msg.Err.Error()
+			return pr, nil
+		}
+		pr.template = msg.Template
+		pr.prefill(false)
+		return pr, nil
+	case teamsg.PRCommitsMsg:
+		pr.seeding = false
+		if msg.Err != nil {
+			pr.logger.Error("error listing the commits of the PR", "error", msg.Err)
+			pr.formErrorMessage = msg.Err.Error()
+			return pr, nil
+		}
+		if len(msg.Commits) == 0 {
+			pr.formErrorMessage = fmt.Sprintf("%s has no commits that aren't on %s", strings.TrimPrefix(pr.currentBranch, "refs/heads/"), strings.TrimPrefix(pr.targetBranch(), "refs/heads/"))
+			return pr, nil
+		}
+		pr.formErrorMessage = ""
+		pr.seedTitle, pr.seedDescription = seedFromCommits(pr.currentBranch, msg.Commits)
+		pr.prefill(true)
+		return pr, nil
+	case teamsg.PRFormDataMsg:
+		// the form loads them again the next time it opens if they couldn't be loaded
+		pr.formLoading, pr.formLoaded = false, msg.Err == nil
+		pr.loadErrorMessage = ""
+		if msg.Err != nil {
+			pr.logger.Error("error loading branches and reviewers", "error", msg.Err)
+			pr.loadErrorMessage = msg.Err.Error()
+		}
+		pr.targetInput.SetSuggestions(msg.Branches)
+		pr.identities = msg.Identities
+		names := make([]string, 0, len(msg.Identities))
+		for _, identity := range msg.Identities {
+			names = append(names, identity.DisplayName)
+		}
+		for _, input := range pr.reviewerInputs {
+			input.SetSuggestions(names)
 		}
+		return pr, nil
 	case teamsg.SubmitPRMsg:
 		titleAndDescription := strings.SplitN(string(msg), "\n", 2)
-		title := titleAndDescription[0]
-		if title == "" {
-			panic("PR title cannot be empty")
-		}
+		title := strings.TrimSpace(titleAndDescription[0])
 		var description string
 		if len(titleAndDescription) == 2 {
 			description = titleAndDescription[1]
 		}
-		pr.logger.LogToFile("info", fmt.Sprintf("submitting PR with title: %s and description: %s, from %s to %s", title, description, pr.currentBranch, pr.defaultBranch))
-		return pr, func() tea.Msg { return pr.openPR(pr.currentBranch, pr.defaultBranch, title, description) }
+		toCreate, err := pr.pullRequestToCreate(title, description)
+		if err != nil {
+			pr.formErrorMessage = err.Error()
+			// ctrl+s blurred the textarea
+			pr.focusCursor()
+			return pr, nil
+		}
+		pr.formErrorMessage = ""
+		pr.logger.Info("submitting PR", "title", title, "description", description, "source", pr.currentBranch, "target", *toCreate.TargetRefName)
+		return pr, func() tea.Msg { return pr.openPR(toCreate) }
 	case teamsg.PRErrorMsg:
 		s := pr.textarea.Styles()
 		s.Focused.Text = lipgloss.NewStyle().Foreground(styles.Red)
 		s.Blurred.Text = lipgloss.NewStyle().Foreground(styles.Red)
 		pr.textarea.SetStyles(s)
-		pr.textarea.Focus()
+		pr.cursor = prTextField
+		pr.focusCursor()
 		pr.errorDisplayed = true
 		pr.textarea.SetValue(string(msg) + "\nPress 'enter' to dismiss")
 	}
@@ -129,33 +289,303 @@ func (pr *PRSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 	return pr, cmd
 }
 
-func (pr *PRSection) openPR(currentBranch, defaultBranch, title, description string) tea.Msg {
-	pr.logger.LogToFile("info", fmt.Sprintf("creating PR with title: %s and description: %s, from %s to %s", title, description, currentBranch, defaultBranch))
-	createdpr, err := pr.gitclient.CreatePullRequest(context.Background(), git.CreatePullRequestArgs{
-		RepositoryId: utils.Ptr(pr.repositoryId.String()),
-		Project:      &pr.project,
-		GitPullRequestToCreate: &git.GitPullRequest{
-			Title:         &title,
-			Description:   &description,
-			SourceRefName: &currentBranch,
-			TargetRefName: &defaultBranch,
-		},
+// targetBranch is the full name of the branch the PR targets, the default branch when none is typed
+func (pr *PRSection) targetBranch() string {
+	if value := strings.TrimSpace(pr.targetInput.Value()); value != "" {
+		return utils.FormatBranchName(value)
+	}
+	return pr.defaultBranch
+}
+
+// fetchTemplateIfTargetChanged loads the PR template of the target branch unless it's already loaded
+func (pr *PRSection) fetchTemplateIfTargetChanged() tea.Cmd {
+	target := pr.targetBranch()
+	if target == pr.templateTarget {
+		return nil
+	}
+	pr.templateTarget = target
+	return func() tea.Msg {
+		root, err := gitexec.TopLevel()
+		if err != nil {
+			return teamsg.PRTemplateMsg{Target: target, Err: err}
+		}
+		template, err := FindPRTemplate(root, target)
+		return teamsg.PRTemplateMsg{Target: target, Template: template, Err: err}
+	}
+}
+
+// fetchCommits lists the commits of the source branch that aren't on the remote target branch yet
+func (pr *PRSection) fetchCommits() tea.Cmd {
+	pr.seeding = true
+	target := strings.TrimPrefix(pr.targetBranch(), "refs/heads/")
+	return func() tea.Msg {
+		commits, err := gitexec.CommitMessages("origin/"+target, "HEAD")
+		if err != nil {
+			err = fmt.Errorf("couldn't list the commits that aren't on origin/%s, fetch origin and try again: %v", target, err)
+		}
+		return teamsg.PRCommitsMsg{Commits: commits, Err: err}
+	}
+}
+
+// prefill fills the textarea with the seeded title and description followed by the template,
+// text typed by the user is only replaced when forced
+func (pr *PRSection) prefill(force bool) {
+	if !force && pr.textarea.Value() != "" && pr.textarea.Value() != pr.prefilled {
+		return
+	}
+	pr.prefilled = prefilledText(pr.seedTitle, pr.seedDescription, pr.template)
+	pr.textarea.SetValue(pr.prefilled)
+	pr.textarea.MoveToBegin()
+}
+
+// prefilledText lays out the title on the first line and the description and template below it
+func prefilledText(title, description, template string) string {
+	var body []string
+	for _, part := range []string{description, template} {
+		if part != "" {
+			body = append(body, part)
+		}
+	}
+	if len(body) == 0 {
+		return title
+	}
+	return title + "\n" + strings.Join(body, "\n\n")
+}
+
+// onLastLine tells whether the textarea cursor can't go further down, so down moves to the next field
+func (pr *PRSection) onLastLine() bool {
+	info := pr.textarea.LineInfo()
+	return pr.textarea.Line() == pr.textarea.LineCount()-1 && info.RowOffset == info.Height-1
+}
+
+func (pr *PRSection) moveCursor(delta int) {
+	pr.cursor = min(max(pr.cursor+delta, 0), prFields-1)
+	pr.focusCursor()
+}
+
+func (pr *PRSection) focusCursor() {
+	pr.textarea.Blur()
+	pr.targetInput.Blur()
+	pr.labelsInput.Blur()
+	pr.workItemsInput.Blur()
+	for _, input := range pr.reviewerInputs {
+		input.Blur()
+	}
+	if !pr.focused {
+		return
+	}
+	switch pr.cursor {
+	case prTextField:
+		pr.textarea.Focus()
+	case prTargetField:
+		pr.targetInput.Focus()
+	case prRequiredReviewersField, prOptionalReviewersField:
+		pr.reviewerInputs[pr.cursor].Focus()
+	case prLabelsField:
+		pr.labelsInput.Focus()
+	case prWorkItemsField:
+		pr.workItemsInput.Focus()
+	}
+}
+
+// updateField handles the keys of the fields below the title and description
+func (pr *PRSection) updateField(msg tea.KeyPressMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "shift+tab":
+		pr.moveCursor(-1)
+		return pr.fetchTemplateIfTargetChanged()
+	case "down":
+		pr.moveCursor(1)
+		return pr.fetchTemplateIfTargetChanged()
+	case "enter":
+		// enter adds the reviewer being typed on reviewer fields
+		if _, ok := pr.reviewerInputs[pr.cursor]; !ok {
+			pr.moveCursor(1)
+			return pr.fetchTemplateIfTargetChanged()
+		}
+	}
+	var cmd tea.Cmd
+	switch pr.cursor {
+	case prTargetField:
+		pr.targetInput, cmd = pr.targetInput.Update(msg)
+	case prDraftField:
+		switch msg.String() {
+		case "space", "left", "right":
+			pr.isDraft = !pr.isDraft
+		}
+	case prRequiredReviewersField, prOptionalReviewersField:
+		input := pr.reviewerInputs[pr.cursor]
+		switch msg.String() {
+		case "enter":
+			if err := pr.addReviewer(pr.cursor); err != nil {
+				pr.formErrorMessage = err.Error()
+			}
+			return nil
+		case "backspace":
+			if input.Value() == "" && len(pr.reviewers[pr.cursor]) > 0 {
+				pr.reviewers[pr.cursor] = pr.reviewers[pr.cursor][:len(pr.reviewers[pr.cursor])-1]
+				return nil
+			}
+		}
+		*input, cmd = input.Update(msg)
+	case prLabelsField:
+		pr.labelsInput, cmd = pr.labelsInput.Update(msg)
+	case prWorkItemsField:
+		pr.workItemsInput, cmd = pr.workItemsInput.Update(msg)
+	}
+	return cmd
+}
+
+// addReviewer adds the identity typed in the reviewer field, a reviewer is either required or optional
+func (pr *PRSection) addReviewer(field int) error {
+	input := pr.reviewerInputs[field]
+	name := strings.TrimSpace(input.Value())
+	if name == "" {
+		return nil
+	}
+	identity, ok := azdo.FindIdentity(pr.identities, name)
+	if !ok {
+		return fmt.Errorf("no team or member of the project named %q", name)
+	}
+	for f := range pr.reviewers {
+		pr.reviewers[f] = slices.DeleteFunc(pr.reviewers[f], func(i azdo.Identity) bool { return i.Id == identity.Id })
+	}
+	pr.reviewers[field] = append(pr.reviewers[field], identity)
+	input.SetValue("")
+	pr.formErrorMessage = ""
+	return nil
+}
+
+// pullRequestToCreate builds the PR from the form, reviewers still being typed are added first
+func (pr *PRSection) pullRequestToCreate(title, description string) (git.GitPullRequest, error) {
+	if title == "" {
+		return git.GitPullRequest{}, errors.New("the PR needs a title, the first line")
+	}
+	for _, field := range []int{prRequiredReviewersField, prOptionalReviewersField} {
+		if err := pr.addReviewer(field); err != nil {
+			return git.GitPullRequest{}, err
+		}
+	}
+	target := pr.targetBranch()
+	if target == pr.currentBranch {
+		return git.GitPullRequest{}, fmt.Errorf("the PR can't target its source branch %s", strings.TrimPrefix(target, "refs/heads/"))
+	}
+	workItems, err := parseWorkItemRefs(pr.workItemsInput.Value())
+	if err != nil {
+		return git.GitPullRequest{}, err
+	}
+	var reviewers []git.IdentityRefWithVote
+	for _, field := range []int{prRequiredReviewersField, prOptionalReviewersField} {
+		for _, identity := range pr.reviewers[field] {
+			reviewers = append(reviewers, git.IdentityRefWithVote{
+				Id:         utils.Ptr(identity.Id),
+				IsRequired: utils.Ptr(field == prRequiredReviewersField),
+			})
+		}
+	}
+	var labels []core.WebApiTagDefinition
+	for _, label := range splitList(pr.labelsInput.Value()) {
+		labels = append(labels, core.WebApiTagDefinition{Name: utils.Ptr(label)})
+	}
+	return git.GitPullRequest{
+		Title:         &title,
+		Description:   &description,
+		SourceRefName: &pr.currentBranch,
+		TargetRefName: &target,
+		IsDraft:       utils.Ptr(pr.isDraft),
+		Reviewers:     &reviewers,
+		Labels:        &labels,
+		WorkItemRefs:  &workItems,
+	}, nil
+}
+
+// splitList splits a comma separated list, dropping empty values
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func parseWorkItemRefs(list string) ([]webapi.ResourceRef, error) {
+	refs := []webapi.ResourceRef{}
+	for _, value := range splitList(list) {
+		id, err := strconv.Atoi(strings.TrimPrefix(value, "#"))
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%q is not a work item id", value)
+		}
+		refs = append(refs, webapi.ResourceRef{Id: utils.Ptr(strconv.Itoa(id))})
+	}
+	return refs, nil
+}
+
+func (pr *PRSection) openPR(toCreate git.GitPullRequest) tea.Msg {
+	createdpr, err := pr.gitclient.CreatePullRequest(pr.ctx, git.CreatePullRequestArgs{
+		RepositoryId:           utils.Ptr(pr.repositoryId.String()),
+		Project:                &pr.project,
+		GitPullRequestToCreate: &toCreate,
 	})
-	pr.logger.LogToFile("info", fmt.Sprintf("PR created: %v", createdpr))
+	pr.logger.Info("PR created", "pr", createdpr)
 	if err != nil {
-		pr.logger.LogToFile("error", "error while creating PR: "+err.Error())
+		pr.logger.Error("error while creating PR", "error", err)
 		return teamsg.PRErrorMsg(err.Error())
 	}
-	return teamsg.GitPRCreatedMsg{}
+	return teamsg.GitPRCreatedMsg(pullRequestItem(createdpr, ""))
+}
+
+func (pr *PRSection) formView() string {
+	labelStyle := lipgloss.NewStyle().Bold(true)
+	label := func(field int, text string) string {
+		if pr.focused && pr.cursor == field {
+			return labelStyle.Foreground(styles.Yellow).Render(text)
+		}
+		return labelStyle.Render(text)
+	}
+	reviewers := func(field int) string {
+		var names []string
+		for _, identity := range pr.reviewers[field] {
+			names = append(names, identity.DisplayName)
+		}
+		if len(names) == 0 {
+			return pr.reviewerInputs[field].View()
+		}
+		return lipgloss.NewStyle().Width(styles.DefaultSectionWidth + 20).Render(strings.Join(names, ", ") + ", " + pr.reviewerInputs[field].View())
+	}
+	draft := "[ ] no"
+	if pr.isDraft {
+		draft = "[x] yes"
+	}
+	lines := []string{
+		label(prTargetField, fmt.Sprintf("Target branch (from %s)", strings.TrimPrefix(pr.currentBranch, "refs/heads/"))), pr.targetInput.View(),
+		label(prDraftField, "Draft ") + draft,
+		label(prRequiredReviewersField, "Required reviewers"), reviewers(prRequiredReviewersField),
+		label(prOptionalReviewersField, "Optional reviewers"), reviewers(prOptionalReviewersField),
+		label(prLabelsField, "Labels"), pr.labelsInput.View(),
+		label(prWorkItemsField, "Work items"), pr.workItemsInput.View(),
+	}
+	errorStyle := lipgloss.NewStyle().Foreground(styles.Red).Width(styles.DefaultSectionWidth + 20)
+	switch {
+	case pr.formErrorMessage != "":
+		lines = append(lines, errorStyle.Render(pr.formErrorMessage))
+	case pr.loadErrorMessage != "":
+		lines = append(lines, errorStyle.Render(pr.loadErrorMessage))
+	case pr.formLoading:
+		lines = append(lines, "Loading branches and reviewers...")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
 func (pr *PRSection) View() string {
 	title := styles.TitleStyle.Render(pr.title)
 	if !pr.hidden {
+		secView := lipgloss.JoinVertical(lipgloss.Top, title, "", pr.textarea.View(), "", pr.formView(), "", pr.help)
 		if pr.focused {
-			return styles.ActiveStyle.Render(lipgloss.JoinVertical(lipgloss.Top, title, "", pr.textarea.View(), "", pr.help))
+			return styles.ActiveStyle.Render(secView)
 		}
-		return styles.InactiveStyle.Render(lipgloss.JoinVertical(lipgloss.Top, title, "", pr.textarea.View(), "", pr.help))
+		return styles.InactiveStyle.Render(secView)
 	}
 	return ""
 }
@@ -171,11 +601,11 @@ func (pr *PRSection) Show() {
 
 func (pr *PRSection) Focus() {
 	pr.Show()
-	pr.textarea.Focus()
 	pr.focused = true
+	pr.focusCursor()
 }
 
 func (pr *PRSection) Blur() {
-	pr.textarea.Blur()
 	pr.focused = false
+	pr.focusCursor()
 }
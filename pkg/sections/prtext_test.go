@@ -0,0 +1,115 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/teamsg"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newTestPRSection() *PRSection {
+	pr := NewPRSection(context.Background(), OpenPR, nil, nil, azdo.Config{
+		RepositoryId:  uuid.New(),
+		CurrentBranch: "feature/login",
+		DefaultBranch: "refs/heads/main",
+	}).(*PRSection)
+	pr.identities = []azdo.Identity{
+		{Id: "team", DisplayName: "Release Team", IsTeam: true},
+		{Id: "sam", DisplayName: "Sam", UniqueName: "sam@example.com"},
+	}
+	return pr
+}
+
+func TestPullRequestToCreate(t *testing.T) {
+	pr := newTestPRSection()
+	pr.isDraft = true
+	pr.reviewerInputs[prRequiredReviewersField].SetValue("release team")
+	if err := pr.addReviewer(prRequiredReviewersField); err != nil {
+		t.Fatal(err)
+	}
+	// typed but not added yet, it's added on submit
+	pr.reviewerInputs[prOptionalReviewersField].SetValue("sam@example.com")
+	pr.labelsInput.SetValue("frontend, ,urgent")
+	pr.workItemsInput.SetValue("#12, 34")
+	pr.targetInput.SetValue("release/1.0")
+
+	toCreate, err := pr.pullRequestToCreate("Add login", "the description")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *toCreate.SourceRefName != "refs/heads/feature/login" || *toCreate.TargetRefName != "refs/heads/release/1.0" {
+		t.Errorf("unexpected branches %s -> %s", *toCreate.SourceRefName, *toCreate.TargetRefName)
+	}
+	if !*toCreate.IsDraft {
+		t.Error("expected a draft")
+	}
+	reviewers := *toCreate.Reviewers
+	if len(reviewers) != 2 || *reviewers[0].Id != "team" || !*reviewers[0].IsRequired || *reviewers[1].Id != "sam" || *reviewers[1].IsRequired {
+		t.Errorf("unexpected reviewers %+v", reviewers)
+	}
+	labels := *toCreate.Labels
+	if len(labels) != 2 || *labels[0].Name != "frontend" || *labels[1].Name != "urgent" {
+		t.Errorf("unexpected labels %+v", labels)
+	}
+	workItems := *toCreate.WorkItemRefs
+	if len(workItems) != 2 || *workItems[0].Id != "12" || *workItems[1].Id != "34" {
+		t.Errorf("unexpected work items %+v", workItems)
+	}
+}
+
+func TestPullRequestToCreateErrors(t *testing.T) {
+	for name, setup := range map[string]func(pr *PRSection){
+		"no title":         func(pr *PRSection) {},
+		"same branch":      func(pr *PRSection) { pr.targetInput.SetValue("feature/login") },
+		"unknown reviewer": func(pr *PRSection) { pr.reviewerInputs[prOptionalReviewersField].SetValue("nobody") },
+		"bad work item":    func(pr *PRSection) { pr.workItemsInput.SetValue("12, abc") },
+	} {
+		pr := newTestPRSection()
+		setup(pr)
+		title := "Add login"
+		if name == "no title" {
+			title = ""
+		}
+		if _, err := pr.pullRequestToCreate(title, ""); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestAddReviewerMovesBetweenRequiredAndOptional(t *testing.T) {
+	pr := newTestPRSection()
+	pr.reviewerInputs[prOptionalReviewersField].SetValue("Sam")
+	if err := pr.addReviewer(prOptionalReviewersField); err != nil {
+		t.Fatal(err)
+	}
+	pr.reviewerInputs[prRequiredReviewersField].SetValue("sam")
+	if err := pr.addReviewer(prRequiredReviewersField); err != nil {
+		t.Fatal(err)
+	}
+	if len(pr.reviewers[prOptionalReviewersField]) != 0 || len(pr.reviewers[prRequiredReviewersField]) != 1 {
+		t.Errorf("expected Sam to be only required, got %+v", pr.reviewers)
+	}
+	if pr.reviewerInputs[prRequiredReviewersField].Value() != "" {
+		t.Error("expected the input to be cleared once the reviewer was added")
+	}
+}
+
+func TestFormDataRetriedAfterError(t *testing.T) {
+	pr := newTestPRSection()
+	pr.Update(teamsg.PRFormDataMsg{Err: errors.New("unauthorized")})
+	if pr.formLoaded || pr.loadErrorMessage == "" {
+		t.Fatalf("expected the error to be shown and the form left unloaded, got loaded %v message %q", pr.formLoaded, pr.loadErrorMessage)
+	}
+
+	pr.Update(teamsg.SubmitChoiceMsg(Options.OpenPR))
+	if !pr.formLoading {
+		t.Fatal("expected reopening the form to load the branches and reviewers again")
+	}
+	pr.Update(teamsg.PRFormDataMsg{Branches: []string{"refs/heads/main"}})
+	if !pr.formLoaded || pr.loadErrorMessage != "" {
+		t.Errorf("expected the form to be loaded, got loaded %v message %q", pr.formLoaded, pr.loadErrorMessage)
+	}
+}
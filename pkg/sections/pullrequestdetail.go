@@ -0,0 +1,145 @@
+package sections
+
+import (
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/logger"
+	"azdoext/pkg/styles"
+	"azdoext/pkg/teamsg"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// PullRequestDetailSection shows a pull request selected on the pull request list with its reviewers,
+// its policies are shown by the PR status below it
+type PullRequestDetailSection struct {
+	logger            *logger.Logger
+	hidden            bool
+	focused           bool
+	sectionIdentifier SectionName
+	pr                listitems.PullRequestItem
+	width             int
+	height            int
+	help              string
+}
+
+func NewPullRequestDetail(secid SectionName) Section {
+	logger := logger.NewLogger("pullrequestdetail")
+	help := styles.ShortHelpStyle.Render("tab policies • c complete • esc back")
+	return &PullRequestDetailSection{
+		logger:            logger,
+		sectionIdentifier: secid,
+		width:             styles.DefaultSectionWidth,
+		help:              help,
+	}
+}
+
+func (d *PullRequestDetailSection) GetSectionIdentifier() SectionName {
+	return d.sectionIdentifier
+}
+
+func (d *PullRequestDetailSection) IsHidden() bool {
+	return d.hidden
+}
+
+func (d *PullRequestDetailSection) IsFocused() bool {
+	return d.focused
+}
+
+func (d *PullRequestDetailSection) Hide() {
+	d.hidden = true
+	d.focused = false
+}
+
+func (d *PullRequestDetailSection) Show() {
+	d.hidden = false
+}
+
+func (d *PullRequestDetailSection) Focus() {
+	d.Show()
+	d.focused = true
+}
+
+func (d *PullRequestDetailSection) Blur() {
+	d.focused = false
+}
+
+func (d *PullRequestDetailSection) SetDimensions(width, height int) {
+	// the details sit next to the pull request list, so they take the rest of the terminal
+	d.width = max(styles.Width-max(styles.Width/2, styles.DefaultSectionWidth+20)-3, styles.DefaultSectionWidth)
+	d.height = height
+}
+
+func (d *PullRequestDetailSection) Update(msg tea.Msg) (Section, tea.Cmd) {
+	if msg, ok := msg.(teamsg.PullRequestSelectedMsg); ok {
+		d.pr = listitems.PullRequestItem(msg)
+	}
+	return d, nil
+}
+
+// reviewerLines lists the reviewers with their vote, required reviewers first
+func reviewerLines(reviewers []listitems.PullRequestReviewer) []string {
+	if len(reviewers) == 0 {
+		return []string{"no reviewers"}
+	}
+	var required, optional []string
+	for _, reviewer := range reviewers {
+		line := fmt.Sprintf("%s %s: %s", voteSymbol(reviewer.Vote), reviewer.Name, listitems.VoteName(reviewer.Vote))
+		if reviewer.IsRequired {
+			required = append(required, line+" (required)")
+		} else {
+			optional = append(optional, line)
+		}
+	}
+	return append(required, optional...)
+}
+
+func voteSymbol(vote int) string {
+	switch {
+	case vote >= 5:
+		return styles.SymbolMap["succeeded"].String()
+	case vote <= -10:
+		return styles.SymbolMap["failed"].String()
+	case vote < 0:
+		return styles.SymbolMap["partiallySucceeded"].String()
+	}
+	return styles.SymbolMap["noRuns"].String()
+}
+
+func (d *PullRequestDetailSection) View() string {
+	labelStyle := lipgloss.NewStyle().Bold(true)
+	greyStyle := lipgloss.NewStyle().Foreground(styles.Grey)
+	pr := d.pr
+	state := pr.Status
+	if pr.IsDraft {
+		state += ", draft"
+	}
+	if pr.AutoCompleteSetBy != "" {
+		state += ", auto-complete set by " + pr.AutoCompleteSetBy
+	}
+	lines := []string{
+		styles.TitleStyle.Render(fmt.Sprintf("Pull request #%d", pr.Id)),
+		lipgloss.NewStyle().Bold(true).Width(d.width).Render(pr.Title),
+		greyStyle.Render(fmt.Sprintf("%s • %s • %s", pr.Author, listitems.FormatAge(time.Since(pr.CreationDate)), state)),
+		fmt.Sprintf("%s → %s", strings.TrimPrefix(pr.SourceBranch, "refs/heads/"), strings.TrimPrefix(pr.TargetBranch, "refs/heads/")),
+		"",
+		labelStyle.Render("Merge status: ") + pr.MergeStatus,
+		"",
+		labelStyle.Render("Reviewers"),
+	}
+	lines = append(lines, reviewerLines(pr.Reviewers)...)
+	if pr.Url != "" {
+		lines = append(lines, "", greyStyle.Render(pr.Url))
+	}
+	details := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	// the description takes whatever room is left above the help
+	description := lipgloss.NewStyle().Width(d.width).MaxHeight(max(d.height-lipgloss.Height(details)-4, 0)).Render(pr.Description)
+	secView := lipgloss.JoinVertical(lipgloss.Left, details, "", description, "", d.help)
+	if d.focused {
+		return styles.ActiveStyle.Render(secView)
+	}
+	return styles.InactiveStyle.Render(secView)
+}
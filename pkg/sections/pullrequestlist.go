@@ -0,0 +1,365 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/logger"
+	"azdoext/pkg/styles"
+	"azdoext/pkg/teamsg"
+	"azdoext/pkg/utils"
+	"context"
+	"fmt"
+	"sync"
+
+	"charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/policy"
+)
+
+const pullRequestsPageSize = 100
+
+type pullRequestScope int
+
+// the pull requests listed, cycled through with the scope key
+const (
+	scopeMine pullRequestScope = iota
+	scopeAssigned
+	scopeAll
+	pullRequestScopes
+)
+
+var pullRequestScopeNames = map[pullRequestScope]string{
+	scopeMine:     "mine",
+	scopeAssigned: "assigned to me",
+	scopeAll:      "all",
+}
+
+// pullRequestStatuses are cycled through with the status filter key
+var pullRequestStatuses = []git.PullRequestStatus{
+	git.PullRequestStatusValues.Active,
+	git.PullRequestStatusValues.Completed,
+	git.PullRequestStatusValues.Abandoned,
+	git.PullRequestStatusValues.All,
+}
+
+type PullRequestListSection struct {
+	logger            *logger.Logger
+	hidden            bool
+	focused           bool
+	ctx               context.Context
+	gitclient         azdo.GitClientInterface
+	policyclient      azdo.PolicyClientInterface
+	sectionIdentifier SectionName
+	project           string
+	repositoryId      uuid.UUID
+	prlist            list.Model
+	scope             pullRequestScope
+	status            int
+	// userId is resolved on the first request that needs it
+	userId       uuid.UUID
+	request      int
+	loading      bool
+	errorMessage string
+	help         string
+}
+
+func NewPullRequestList(ctx context.Context, secid SectionName, gitclient azdo.GitClientInterface, policyclient azdo.PolicyClientInterface, azdoconfig azdo.Config) Section {
+	logger := logger.NewLogger("pullrequestlist")
+	prlist := list.New([]list.Item{}, listitems.PullRequestItemDelegate{}, 0, 0)
+	prlist.SetShowTitle(false)
+	prlist.SetShowStatusBar(false)
+	prlist.SetShowHelp(false)
+	prlist.DisableQuitKeybindings()
+
+	help := styles.ShortHelpStyle.Render("↵ details • / search • m mine/assigned/all • s status • r refresh")
+	return &PullRequestListSection{
+		logger:            logger,
+		ctx:               ctx,
+		gitclient:         gitclient,
+		policyclient:      policyclient,
+		sectionIdentifier: secid,
+		project:           azdoconfig.ProjectId,
+		repositoryId:      azdoconfig.RepositoryId,
+		prlist:            prlist,
+		help:              help,
+	}
+}
+
+func (p *PullRequestListSection) GetSectionIdentifier() SectionName {
+	return p.sectionIdentifier
+}
+
+func (p *PullRequestListSection) IsHidden() bool {
+	return p.hidden
+}
+
+func (p *PullRequestListSection) IsFocused() bool {
+	return p.focused
+}
+
+func (p *PullRequestListSection) Hide() {
+	p.hidden = true
+	p.focused = false
+}
+
+func (p *PullRequestListSection) Show() {
+	p.hidden = false
+}
+
+func (p *PullRequestListSection) Focus() {
+	p.Show()
+	p.focused = true
+}
+
+func (p *PullRequestListSection) Blur() {
+	p.focused = false
+}
+
+// CapturingInput tells whether a search is being typed, so the page sends it every key
+func (p *PullRequestListSection) CapturingInput() bool {
+	return p.prlist.FilterState() == list.Filtering
+}
+
+func (p *PullRequestListSection) SetDimensions(width, height int) {
+	p.prlist.SetWidth(max(styles.Width/2, styles.DefaultSectionWidth+20))
+	// title, filters, status and help
+	p.prlist.SetHeight(max(height-4, 1))
+}
+
+// pullRequestCriteria searches the pull requests of the repository in the given scope and status
+func pullRequestCriteria(repositoryId uuid.UUID, scope pullRequestScope, status git.PullRequestStatus, userId uuid.UUID) *git.GitPullRequestSearchCriteria {
+	criteria := &git.GitPullRequestSearchCriteria{
+		RepositoryId: &repositoryId,
+		Status:       &status,
+	}
+	switch scope {
+	case scopeMine:
+		criteria.CreatorId = &userId
+	case scopeAssigned:
+		criteria.ReviewerId = &userId
+	}
+	return criteria
+}
+
+func (p *PullRequestListSection) fetchPullRequests() tea.Cmd {
+	p.request++
+	p.loading = true
+	request := p.request
+	userId := p.userId
+	scope := p.scope
+	status := pullRequestStatuses[p.status]
+	return func() tea.Msg {
+		if userId == uuid.Nil && scope != scopeAll {
+			var err error
+			userId, err = p.gitclient.GetAuthenticatedUserId(p.ctx)
+			if err != nil {
+				return teamsg.PullRequestsFetchedMsg{Request: request, Err: fmt.Errorf("failed to get the authenticated user: %w", err)}
+			}
+		}
+		prs, err := p.gitclient.GetPullRequests(p.ctx, git.GetPullRequestsArgs{
+			RepositoryId:   utils.Ptr(p.repositoryId.String()),
+			Project:        &p.project,
+			SearchCriteria: pullRequestCriteria(p.repositoryId, scope, status, userId),
+			Top:            utils.Ptr(pullRequestsPageSize),
+		})
+		if err != nil {
+			return teamsg.PullRequestsFetchedMsg{Request: request, UserId: userId, Err: err}
+		}
+		policyStates := p.fetchPolicyStates(prs)
+		items := make([]list.Item, 0, len(prs))
+		for _, pr := range prs {
+			items = append(items, pullRequestItem(pr, policyStates[*pr.PullRequestId]))
+		}
+		return teamsg.PullRequestsFetchedMsg{Request: request, UserId: userId, Items: items}
+	}
+}
+
+// policyStateFetches is how many policy evaluations of the listed pull requests are fetched at once, so that a
+// page of pull requests doesn't get the requests throttled
+const policyStateFetches = 4
+
+// fetchPolicyStates evaluates the policies of the active pull requests concurrently, a pull request whose
+// evaluations can't be fetched has no policy state
+func (p *PullRequestListSection) fetchPolicyStates(prs []git.GitPullRequest) map[int]string {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		states = make(map[int]string)
+		slots  = make(chan struct{}, policyStateFetches)
+	)
+	for _, pr := range prs {
+		if pr.Status == nil || *pr.Status != git.PullRequestStatusValues.Active {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(id int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			evaluations, err := p.policyclient.GetPolicyEvaluations(p.ctx, id)
+			if err != nil {
+				p.logger.Warn("error fetching policy evaluations", "pullRequestId", id, "error", err)
+				return
+			}
+			mu.Lock()
+			states[id] = azdo.PolicyState(evaluations)
+			mu.Unlock()
+		}(*pr.PullRequestId)
+	}
+	wg.Wait()
+	return states
+}
+
+func pullRequestItem(pr git.GitPullRequest, policyState string) listitems.PullRequestItem {
+	item := listitems.PullRequestItem{
+		Id:           *pr.PullRequestId,
+		Title:        utils.Deref(pr.Title),
+		Description:  utils.Deref(pr.Description),
+		SourceBranch: utils.Deref(pr.SourceRefName),
+		TargetBranch: utils.Deref(pr.TargetRefName),
+		IsDraft:      utils.Deref(pr.IsDraft),
+		PolicyState:  policyState,
+	}
+	if pr.Status != nil {
+		item.Status = string(*pr.Status)
+	}
+	if pr.MergeStatus != nil {
+		item.MergeStatus = string(*pr.MergeStatus)
+	}
+	if pr.LastMergeSourceCommit != nil {
+		item.LastMergeSourceCommit = utils.Deref(pr.LastMergeSourceCommit.CommitId)
+	}
+	if pr.CreatedBy != nil {
+		item.Author = utils.Deref(pr.CreatedBy.DisplayName)
+	}
+	if pr.AutoCompleteSetBy != nil {
+		item.AutoCompleteSetBy = utils.Deref(pr.AutoCompleteSetBy.DisplayName)
+	}
+	if pr.CreationDate != nil {
+		item.CreationDate = pr.CreationDate.Time
+	}
+	if pr.Reviewers != nil {
+		for _, reviewer := range *pr.Reviewers {
+			item.Reviewers = append(item.Reviewers, listitems.PullRequestReviewer{
+				Name:       utils.Deref(reviewer.DisplayName),
+				Vote:       utils.Deref(reviewer.Vote),
+				IsRequired: utils.Deref(reviewer.IsRequired),
+			})
+		}
+	}
+	if pr.Repository != nil && pr.Repository.WebUrl != nil {
+		item.Url = fmt.Sprintf("%s/pullrequest/%d", *pr.Repository.WebUrl, item.Id)
+	}
+	item.Symbol = utils.Ptr(pullRequestSymbol(item).String())
+	return item
+}
+
+// pullRequestSymbol shows the policy state of an active pull request, and how a closed one ended
+func pullRequestSymbol(item listitems.PullRequestItem) lipgloss.Style {
+	switch {
+	case item.Status == string(git.PullRequestStatusValues.Completed):
+		return styles.SymbolMap["succeeded"]
+	case item.Status == string(git.PullRequestStatusValues.Abandoned):
+		return styles.SymbolMap["canceled"]
+	case item.IsDraft:
+		return styles.SymbolMap["skipped"]
+	case item.MergeStatus == string(git.PullRequestAsyncStatusValues.Conflicts):
+		return styles.SymbolMap["partiallySucceeded"]
+	}
+	switch policy.PolicyEvaluationStatus(item.PolicyState) {
+	case policy.PolicyEvaluationStatusValues.Approved:
+		return styles.SymbolMap["succeeded"]
+	case policy.PolicyEvaluationStatusValues.Rejected:
+		return styles.SymbolMap["failed"]
+	case policy.PolicyEvaluationStatusValues.Running:
+		return styles.SymbolMap["pending"]
+	}
+	return styles.SymbolMap["noRuns"]
+}
+
+func (p *PullRequestListSection) Update(msg tea.Msg) (Section, tea.Cmd) {
+	switch msg := msg.(type) {
+	case teamsg.OpenPullRequestsMsg:
+		p.errorMessage = ""
+		return p, p.fetchPullRequests()
+	case teamsg.PullRequestsFetchedMsg:
+		if msg.Request != p.request {
+			return p, nil
+		}
+		p.loading = false
+		if msg.UserId != uuid.Nil {
+			p.userId = msg.UserId
+		}
+		if msg.Err != nil {
+			p.logger.Error("error fetching pull requests", "error", msg.Err)
+			p.errorMessage = msg.Err.Error()
+			return p, nil
+		}
+		p.errorMessage = ""
+		return p, p.setItems(msg.Items)
+	case tea.KeyPressMsg:
+		if !p.focused {
+			return p, nil
+		}
+		if !p.CapturingInput() {
+			switch msg.String() {
+			case "enter":
+				pr, ok := p.prlist.SelectedItem().(listitems.PullRequestItem)
+				if !ok {
+					return p, nil
+				}
+				return p, func() tea.Msg { return teamsg.PullRequestSelectedMsg(pr) }
+			case "m":
+				p.scope = (p.scope + 1) % pullRequestScopes
+				return p, p.fetchPullRequests()
+			case "s":
+				p.status = (p.status + 1) % len(pullRequestStatuses)
+				return p, p.fetchPullRequests()
+			case "r":
+				return p, p.fetchPullRequests()
+			}
+		}
+		prlist, cmd := p.prlist.Update(msg)
+		p.prlist = prlist
+		return p, cmd
+	}
+	return p, nil
+}
+
+// setItems replaces the pull requests, keeping the selected one selected
+func (p *PullRequestListSection) setItems(items []list.Item) tea.Cmd {
+	selected, hasSelection := p.prlist.SelectedItem().(listitems.PullRequestItem)
+	cmd := p.prlist.SetItems(items)
+	if p.prlist.FilterState() != list.Unfiltered {
+		return cmd
+	}
+	p.prlist.Select(0)
+	for i, item := range items {
+		if pr, ok := item.(listitems.PullRequestItem); ok && hasSelection && pr.Id == selected.Id {
+			p.prlist.Select(i)
+		}
+	}
+	return cmd
+}
+
+func (p *PullRequestListSection) View() string {
+	title := styles.TitleStyle.Render("Pull requests")
+	filters := fmt.Sprintf("%s • %s", pullRequestScopeNames[p.scope], pullRequestStatuses[p.status])
+	var status string
+	switch {
+	case p.errorMessage != "":
+		status = lipgloss.NewStyle().Foreground(styles.Red).Width(p.prlist.Width()).Render(p.errorMessage)
+	case p.loading:
+		status = "Loading pull requests..."
+	case len(p.prlist.Items()) == 0:
+		status = "No pull requests match the filters"
+	}
+	secView := lipgloss.JoinVertical(lipgloss.Left, title, lipgloss.NewStyle().Foreground(styles.Grey).Render(filters), p.prlist.View(), status, p.help)
+	if p.focused {
+		return styles.ActiveStyle.Render(secView)
+	}
+	return styles.InactiveStyle.Render(secView)
+}
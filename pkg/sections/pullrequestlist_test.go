@@ -0,0 +1,140 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/utils"
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+)
+
+func TestPullRequestCriteria(t *testing.T) {
+	repositoryId, userId := uuid.New(), uuid.New()
+	mine := pullRequestCriteria(repositoryId, scopeMine, git.PullRequestStatusValues.Active, userId)
+	if mine.CreatorId == nil || *mine.CreatorId != userId || mine.ReviewerId != nil {
+		t.Errorf("expected my pull requests to be searched by creator, got %+v", mine)
+	}
+	assigned := pullRequestCriteria(repositoryId, scopeAssigned, git.PullRequestStatusValues.Completed, userId)
+	if assigned.ReviewerId == nil || *assigned.ReviewerId != userId || assigned.CreatorId != nil {
+		t.Errorf("expected assigned pull requests to be searched by reviewer, got %+v", assigned)
+	}
+	if *assigned.Status != git.PullRequestStatusValues.Completed || *assigned.RepositoryId != repositoryId {
+		t.Errorf("expected the status and repository to be searched, got %+v", assigned)
+	}
+	all := pullRequestCriteria(repositoryId, scopeAll, git.PullRequestStatusValues.All, uuid.Nil)
+	if all.CreatorId != nil || all.ReviewerId != nil {
+		t.Errorf("expected every pull request to be searched, got %+v", all)
+	}
+}
+
+func TestPullRequestItem(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	pr := git.GitPullRequest{
+		PullRequestId:         utils.Ptr(7),
+		Title:                 utils.Ptr("Add login"),
+		SourceRefName:         utils.Ptr("refs/heads/feature/login"),
+		TargetRefName:         utils.Ptr("refs/heads/main"),
+		Status:                &git.PullRequestStatusValues.Active,
+		MergeStatus:           &git.PullRequestAsyncStatusValues.Conflicts,
+		IsDraft:               utils.Ptr(true),
+		LastMergeSourceCommit: &git.GitCommitRef{CommitId: utils.Ptr("abc123")},
+		CreatedBy:             &webapi.IdentityRef{DisplayName: utils.Ptr("Sam")},
+		CreationDate:          &azuredevops.Time{Time: created},
+		Reviewers: &[]git.IdentityRefWithVote{
+			{DisplayName: utils.Ptr("Alex"), Vote: utils.Ptr(10), IsRequired: utils.Ptr(true)},
+			{DisplayName: utils.Ptr("Kim"), Vote: utils.Ptr(-5)},
+		},
+		Repository: &git.GitRepository{WebUrl: utils.Ptr("https://dev.azure.com/org/project/_git/app")},
+	}
+	item := pullRequestItem(pr, "running")
+	want := listitems.PullRequestItem{
+		Id:                    7,
+		Title:                 "Add login",
+		Author:                "Sam",
+		SourceBranch:          "refs/heads/feature/login",
+		TargetBranch:          "refs/heads/main",
+		Status:                "active",
+		IsDraft:               true,
+		MergeStatus:           "conflicts",
+		PolicyState:           "running",
+		LastMergeSourceCommit: "abc123",
+		Reviewers: []listitems.PullRequestReviewer{
+			{Name: "Alex", Vote: 10, IsRequired: true},
+			{Name: "Kim", Vote: -5},
+		},
+		CreationDate: created,
+		Url:          "https://dev.azure.com/org/project/_git/app/pullrequest/7",
+	}
+	if item.Symbol == nil {
+		t.Fatal("expected a symbol")
+	}
+	item.Symbol = nil
+	if !reflect.DeepEqual(item, want) {
+		t.Errorf("pullRequestItem() = %+v, want %+v", item, want)
+	}
+	if votes := listitems.VoteSummary(item.Reviewers); votes != "✓1 ~1" {
+		t.Errorf("unexpected vote summary %q", votes)
+	}
+}
+
+func TestReviewerLines(t *testing.T) {
+	lines := reviewerLines([]listitems.PullRequestReviewer{
+		{Name: "Kim", Vote: -10},
+		{Name: "Alex", Vote: 5, IsRequired: true},
+	})
+	if len(lines) != 2 {
+		t.Fatalf("expected a line per reviewer, got %v", lines)
+	}
+	for i, want := range []string{"Alex: approved with suggestions (required)", "Kim: rejected"} {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("line %d = %q, want it to end with %q", i, lines[i], want)
+		}
+	}
+}
+
+// countingPolicyClient records how many evaluations are fetched at once
+type countingPolicyClient struct {
+	mu             sync.Mutex
+	inFlight, peak int
+	fetched        int
+}
+
+func (c *countingPolicyClient) GetPolicyEvaluations(ctx context.Context, pullRequestId int) ([]azdo.PolicyEvaluation, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.fetched++
+	c.peak = max(c.peak, c.inFlight)
+	c.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return []azdo.PolicyEvaluation{{Name: "ci", Status: "approved", IsBlocking: true}}, nil
+}
+
+func TestFetchPolicyStatesLimitsConcurrency(t *testing.T) {
+	client := &countingPolicyClient{}
+	section := NewPullRequestList(context.Background(), PullRequestList, nil, client, azdo.Config{}).(*PullRequestListSection)
+	prs := make([]git.GitPullRequest, 20)
+	for i := range prs {
+		prs[i] = git.GitPullRequest{PullRequestId: utils.Ptr(i + 1), Status: &git.PullRequestStatusValues.Active}
+	}
+	prs[0].Status = &git.PullRequestStatusValues.Completed
+
+	states := section.fetchPolicyStates(prs)
+	if len(states) != 19 || client.fetched != 19 {
+		t.Errorf("got %d states from %d fetches, want one per active pull request", len(states), client.fetched)
+	}
+	if client.peak > policyStateFetches {
+		t.Errorf("%d evaluations were fetched at once, want at most %d", client.peak, policyStateFetches)
+	}
+}
@@ -0,0 +1,333 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/logger"
+	"azdoext/pkg/styles"
+	"azdoext/pkg/teamsg"
+	"azdoext/pkg/utils"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/list"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/build"
+)
+
+const runHistoryPageSize = 25
+
+// resultFilters are cycled through with the result filter key, empty means any result
+var resultFilters = []build.BuildResult{
+	"",
+	build.BuildResultValues.Succeeded,
+	build.BuildResultValues.PartiallySucceeded,
+	build.BuildResultValues.Failed,
+	build.BuildResultValues.Canceled,
+}
+
+type runHistoryFilter string
+
+const (
+	noFilter        runHistoryFilter = ""
+	branchFilter    runHistoryFilter = "branch"
+	requesterFilter runHistoryFilter = "requester"
+)
+
+type RunHistorySection struct {
+	logger            *logger.Logger
+	hidden            bool
+	focused           bool
+	ctx               context.Context
+	buildclient       azdo.BuildClientInterface
+	sectionIdentifier SectionName
+	pipeline          listitems.PipelineItem
+	runlist           list.Model
+
+	branch    string
+	requester string
+	result    int
+
+	// pageTokens holds the continuation token of every page up to the current one, the first page has none
+	pageTokens []string
+	nextToken  string
+	request    int
+	loading    bool
+
+	errorMessage string
+	filterInput  textinput.Model
+	editing      runHistoryFilter
+	help         string
+}
+
+func NewRunHistory(ctx context.Context, secid SectionName, buildclient azdo.BuildClientInterface) Section {
+	logger := logger.NewLogger("runhistory")
+	runlist := list.New([]list.Item{}, listitems.RunItemDelegate{}, 0, 0)
+	runlist.SetShowTitle(false)
+	runlist.SetShowStatusBar(false)
+	runlist.SetShowHelp(false)
+	runlist.SetShowPagination(false)
+	runlist.SetFilteringEnabled(false)
+	runlist.DisableQuitKeybindings()
+
+	filterInput := textinput.New()
+	filterInput.SetWidth(styles.DefaultSectionWidth)
+
+	help := styles.ShortHelpStyle.Render("↵ open • b branch • u requester • s result • n/p next/previous page • esc back")
+	return &RunHistorySection{
+		logger:            logger,
+		ctx:               ctx,
+		buildclient:       buildclient,
+		sectionIdentifier: secid,
+		runlist:           runlist,
+		filterInput:       filterInput,
+		help:              help,
+	}
+}
+
+func (r *RunHistorySection) GetSectionIdentifier() SectionName {
+	return r.sectionIdentifier
+}
+
+func (r *RunHistorySection) IsHidden() bool {
+	return r.hidden
+}
+
+func (r *RunHistorySection) IsFocused() bool {
+	return r.focused
+}
+
+func (r *RunHistorySection) Hide() {
+	r.hidden = true
+	r.focused = false
+}
+
+func (r *RunHistorySection) Show() {
+	r.hidden = false
+}
+
+func (r *RunHistorySection) Focus() {
+	r.Show()
+	r.focused = true
+}
+
+func (r *RunHistorySection) Blur() {
+	r.focused = false
+}
+
+// CapturingInput tells whether a filter is being typed, so the page sends it every key
+func (r *RunHistorySection) CapturingInput() bool {
+	return r.editing != noFilter
+}
+
+func (r *RunHistorySection) SetDimensions(width, height int) {
+	// the history sits next to the pipeline list, so it takes the rest of the terminal
+	r.runlist.SetWidth(max(styles.Width-styles.DefaultSectionWidth-3, styles.DefaultSectionWidth))
+	// title, header, filters, status and help
+	r.runlist.SetHeight(max(height-7, 1))
+}
+
+func (r *RunHistorySection) fetchPage(token string) tea.Cmd {
+	r.request++
+	r.loading = true
+	request := r.request
+	args := build.GetBuildsArgs{
+		Definitions: &[]int{r.pipeline.Id},
+		Top:         utils.Ptr(runHistoryPageSize),
+		QueryOrder:  &build.BuildQueryOrderValues.QueueTimeDescending,
+	}
+	if token != "" {
+		args.ContinuationToken = utils.Ptr(token)
+	}
+	if r.branch != "" {
+		args.BranchName = utils.Ptr(utils.FormatBranchName(r.branch))
+	}
+	if r.requester != "" {
+		args.RequestedFor = utils.Ptr(r.requester)
+	}
+	if result := resultFilters[r.result]; result != "" {
+		args.ResultFilter = &result
+	}
+	return func() tea.Msg {
+		builds, nextToken, err := r.buildclient.GetBuildsPage(r.ctx, args)
+		if err != nil {
+			return teamsg.RunHistoryFetchedMsg{Request: request, Err: err}
+		}
+		items := make([]list.Item, 0, len(builds))
+		for _, b := range builds {
+			items = append(items, runItemFromBuild(b, time.Now()))
+		}
+		return teamsg.RunHistoryFetchedMsg{Request: request, Items: items, ContinuationToken: nextToken}
+	}
+}
+
+// reload goes back to the first page, e.g. after a filter changed
+func (r *RunHistorySection) reload() tea.Cmd {
+	r.pageTokens = []string{""}
+	return r.fetchPage("")
+}
+
+func runItemFromBuild(b build.Build, now time.Time) listitems.RunItem {
+	status, result := getStatusAndResult(&b)
+	item := listitems.RunItem{
+		Id:     *b.Id,
+		Status: status,
+		Result: result,
+	}
+	if b.SourceBranch != nil {
+		item.Branch = strings.TrimPrefix(*b.SourceBranch, "refs/heads/")
+	}
+	if b.Reason != nil {
+		item.Reason = string(*b.Reason)
+	}
+	if b.RequestedFor != nil && b.RequestedFor.DisplayName != nil {
+		item.RequestedFor = *b.RequestedFor.DisplayName
+	}
+	if b.StartTime != nil {
+		end := now
+		if b.FinishTime != nil {
+			end = b.FinishTime.Time
+		}
+		item.Duration = end.Sub(b.StartTime.Time)
+	}
+	symbolStyle, ok := styles.SymbolMap[utils.StatusOrResult(&status, &result)]
+	if !ok {
+		symbolStyle = styles.SymbolMap["notStarted"]
+	}
+	item.Symbol = utils.Ptr(symbolStyle.String())
+	return item
+}
+
+func (r *RunHistorySection) Update(msg tea.Msg) (Section, tea.Cmd) {
+	switch msg := msg.(type) {
+	case teamsg.RunHistoryMsg:
+		r.pipeline = listitems.PipelineItem(msg)
+		r.branch, r.requester, r.result = "", "", 0
+		r.errorMessage = ""
+		r.runlist.SetItems([]list.Item{})
+		return r, r.reload()
+	case teamsg.RunHistoryFetchedMsg:
+		if msg.Request != r.request {
+			return r, nil
+		}
+		r.loading = false
+		if msg.Err != nil {
+			r.logger.Error("error fetching run history", "pipelineId", r.pipeline.Id, "error", msg.Err)
+			r.errorMessage = msg.Err.Error()
+			return r, nil
+		}
+		r.errorMessage = ""
+		r.nextToken = msg.ContinuationToken
+		r.runlist.Select(0)
+		return r, r.runlist.SetItems(msg.Items)
+	case tea.KeyPressMsg:
+		if !r.focused {
+			return r, nil
+		}
+		if r.CapturingInput() {
+			return r, r.updateFilter(msg)
+		}
+		switch msg.String() {
+		case "enter":
+			run, ok := r.runlist.SelectedItem().(listitems.RunItem)
+			if !ok {
+				return r, nil
+			}
+			pipelineName := r.pipeline.Name
+			return r, func() tea.Msg {
+				return teamsg.PipelineRunIdMsg{RunId: run.Id, PipelineName: pipelineName, Status: run.Status}
+			}
+		case "b":
+			return r, r.editFilter(branchFilter, r.branch, "branch, empty for any")
+		case "u":
+			return r, r.editFilter(requesterFilter, r.requester, "requester name or email, empty for anyone")
+		case "s":
+			r.result = (r.result + 1) % len(resultFilters)
+			return r, r.reload()
+		case "n":
+			if r.nextToken == "" || r.loading {
+				return r, nil
+			}
+			r.pageTokens = append(r.pageTokens, r.nextToken)
+			return r, r.fetchPage(r.nextToken)
+		case "p":
+			if len(r.pageTokens) < 2 || r.loading {
+				return r, nil
+			}
+			r.pageTokens = r.pageTokens[:len(r.pageTokens)-1]
+			return r, r.fetchPage(r.pageTokens[len(r.pageTokens)-1])
+		}
+		runlist, cmd := r.runlist.Update(msg)
+		r.runlist = runlist
+		return r, cmd
+	}
+	return r, nil
+}
+
+func (r *RunHistorySection) editFilter(filter runHistoryFilter, value, placeholder string) tea.Cmd {
+	r.editing = filter
+	r.filterInput.Placeholder = placeholder
+	r.filterInput.SetValue(value)
+	r.filterInput.CursorEnd()
+	return r.filterInput.Focus()
+}
+
+func (r *RunHistorySection) updateFilter(msg tea.KeyPressMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		r.editing = noFilter
+		r.filterInput.Blur()
+		return nil
+	case "enter":
+		value := strings.TrimSpace(r.filterInput.Value())
+		if r.editing == branchFilter {
+			r.branch = value
+		} else {
+			r.requester = value
+		}
+		r.editing = noFilter
+		r.filterInput.Blur()
+		return r.reload()
+	}
+	input, cmd := r.filterInput.Update(msg)
+	r.filterInput = input
+	return cmd
+}
+
+func (r *RunHistorySection) filtersView() string {
+	valueOrAny := func(v string) string {
+		if v == "" {
+			return "any"
+		}
+		return v
+	}
+	result := valueOrAny(string(resultFilters[r.result]))
+	filters := fmt.Sprintf("branch: %s • requester: %s • result: %s • page %d", valueOrAny(r.branch), valueOrAny(r.requester), result, len(r.pageTokens))
+	if r.CapturingInput() {
+		filters = fmt.Sprintf("%s: %s", r.editing, r.filterInput.View())
+	}
+	return lipgloss.NewStyle().Foreground(styles.Grey).Render(filters)
+}
+
+func (r *RunHistorySection) View() string {
+	title := styles.TitleStyle.Render(fmt.Sprintf("Runs of %s", r.pipeline.Name))
+	header := lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("    %-8s %-24s %-16s %-20s %9s", "Run", "Branch", "Reason", "Requested for", "Duration"))
+	var status string
+	switch {
+	case r.errorMessage != "":
+		status = lipgloss.NewStyle().Foreground(styles.Red).Width(r.runlist.Width()).Render(r.errorMessage)
+	case r.loading:
+		status = "Loading runs..."
+	case len(r.runlist.Items()) == 0:
+		status = "No runs match the filters"
+	}
+	secView := lipgloss.JoinVertical(lipgloss.Left, title, r.filtersView(), lipgloss.NewStyle().MaxWidth(r.runlist.Width()).Render(header), r.runlist.View(), status, r.help)
+	if r.focused {
+		return styles.ActiveStyle.Render(secView)
+	}
+	return styles.InactiveStyle.Render(secView)
+}
@@ -0,0 +1,171 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/teamsg"
+	"azdoext/pkg/utils"
+	"context"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+)
+
+func TestRunItemFromBuild(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	completed := build.Build{
+		Id:           utils.Ptr(42),
+		SourceBranch: utils.Ptr("refs/heads/feature/login"),
+		Reason:       &build.BuildReasonValues.IndividualCI,
+		RequestedFor: &webapi.IdentityRef{DisplayName: utils.Ptr("Sam")},
+		Status:       &build.BuildStatusValues.Completed,
+		Result:       &build.BuildResultValues.Failed,
+		StartTime:    &azuredevops.Time{Time: start},
+		FinishTime:   &azuredevops.Time{Time: start.Add(90 * time.Second)},
+	}
+	item := runItemFromBuild(completed, start.Add(time.Hour))
+	if item.Id != 42 || item.Branch != "feature/login" || item.Reason != "individualCI" || item.RequestedFor != "Sam" {
+		t.Errorf("unexpected run item %+v", item)
+	}
+	if item.Duration != 90*time.Second {
+		t.Errorf("expected a duration of 90s, got %s", item.Duration)
+	}
+	if item.Status != "completed" || item.Result != "failed" || item.Symbol == nil {
+		t.Errorf("unexpected state of run item %+v", item)
+	}
+
+	running := build.Build{
+		Id:        utils.Ptr(43),
+		Status:    &build.BuildStatusValues.InProgress,
+		StartTime: &azuredevops.Time{Time: start},
+	}
+	item = runItemFromBuild(running, start.Add(time.Minute))
+	if item.Duration != time.Minute {
+		t.Errorf("expected a running build to last until now, got %s", item.Duration)
+	}
+	if item.Symbol == nil {
+		t.Error("expected a symbol for a running build")
+	}
+}
+
+// runPagesClient serves three pages of runs, one run per page whose id is its page number, and records the last query
+type runPagesClient struct {
+	azdo.BuildClientInterface
+	args build.GetBuildsArgs
+}
+
+func (c *runPagesClient) GetBuildsPage(ctx context.Context, args build.GetBuildsArgs) ([]build.Build, string, error) {
+	c.args = args
+	pages := map[string]struct {
+		id   int
+		next string
+	}{"": {1, "t2"}, "t2": {2, "t3"}, "t3": {3, ""}}
+	page := pages[utils.Deref(args.ContinuationToken)]
+	return []build.Build{{Id: utils.Ptr(page.id), Status: &build.BuildStatusValues.Completed}}, page.next, nil
+}
+
+func newTestRunHistory(t *testing.T) (*RunHistorySection, *runPagesClient) {
+	t.Helper()
+	client := &runPagesClient{}
+	section := NewRunHistory(context.Background(), RunHistory, client).(*RunHistorySection)
+	section.Focus()
+	_, cmd := section.Update(teamsg.RunHistoryMsg(listitems.PipelineItem{Id: 5, Name: "ci"}))
+	fetchRuns(t, section, cmd)
+	return section, client
+}
+
+// fetchRuns runs the fetch of cmd and hands its page to the section
+func fetchRuns(t *testing.T, section *RunHistorySection, cmd tea.Cmd) {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a page of runs to be fetched")
+	}
+	section.Update(cmd())
+}
+
+func shownRun(section *RunHistorySection) int {
+	run, _ := section.runlist.SelectedItem().(listitems.RunItem)
+	return run.Id
+}
+
+func TestRunHistoryPages(t *testing.T) {
+	section, client := newTestRunHistory(t)
+	if client.args.ContinuationToken != nil || *client.args.Top != runHistoryPageSize || (*client.args.Definitions)[0] != 5 {
+		t.Errorf("unexpected first page query %+v", client.args)
+	}
+	if cmd := press(section, "p"); cmd != nil {
+		t.Error("expected no previous page on the first one")
+	}
+
+	fetchRuns(t, section, press(section, "n"))
+	fetchRuns(t, section, press(section, "n"))
+	if token := utils.Deref(client.args.ContinuationToken); token != "t3" || shownRun(section) != 3 {
+		t.Errorf("third page fetched with token %q shows run %d, want t3 and run 3", token, shownRun(section))
+	}
+	if cmd := press(section, "n"); cmd != nil {
+		t.Error("expected no next page after the last one")
+	}
+
+	fetchRuns(t, section, press(section, "p"))
+	if token := utils.Deref(client.args.ContinuationToken); token != "t2" || shownRun(section) != 2 {
+		t.Errorf("previous page fetched with token %q shows run %d, want t2 and run 2", token, shownRun(section))
+	}
+	fetchRuns(t, section, press(section, "p"))
+	if client.args.ContinuationToken != nil || shownRun(section) != 1 {
+		t.Errorf("first page fetched with token %v shows run %d, want no token and run 1", client.args.ContinuationToken, shownRun(section))
+	}
+
+	// a page is fetched at a time
+	press(section, "n")
+	if cmd := press(section, "n"); cmd != nil {
+		t.Error("expected next to wait for the pending page")
+	}
+}
+
+func TestRunHistoryFiltersGoBackToFirstPage(t *testing.T) {
+	section, client := newTestRunHistory(t)
+	fetchRuns(t, section, press(section, "n"))
+
+	press(section, "b", "m", "a", "i", "n")
+	fetchRuns(t, section, press(section, "enter"))
+	if client.args.ContinuationToken != nil || utils.Deref(client.args.BranchName) != "refs/heads/main" || len(section.pageTokens) != 1 {
+		t.Errorf("branch filter queried %+v on page %d, want the first page of refs/heads/main", client.args, len(section.pageTokens))
+	}
+
+	fetchRuns(t, section, press(section, "n"))
+	press(section, "u", "s", "a", "m")
+	fetchRuns(t, section, press(section, "enter"))
+	if client.args.ContinuationToken != nil || utils.Deref(client.args.RequestedFor) != "sam" || utils.Deref(client.args.BranchName) != "refs/heads/main" {
+		t.Errorf("requester filter queried %+v, want the first page of sam's runs on refs/heads/main", client.args)
+	}
+
+	fetchRuns(t, section, press(section, "n"))
+	fetchRuns(t, section, press(section, "s"))
+	if client.args.ContinuationToken != nil || client.args.ResultFilter == nil || *client.args.ResultFilter != build.BuildResultValues.Succeeded {
+		t.Errorf("result filter queried %+v, want the first page of succeeded runs", client.args)
+	}
+	if len(section.pageTokens) != 1 {
+		t.Errorf("page %d after changing the result filter, want 1", len(section.pageTokens))
+	}
+}
+
+func TestRunHistoryDropsStaleResponses(t *testing.T) {
+	section, _ := newTestRunHistory(t)
+	next := press(section, "n")
+	// the filter changed while the second page was loading
+	first := press(section, "s")
+
+	section.Update(first())
+	section.Update(next())
+	if shownRun(section) != 1 {
+		t.Errorf("shows run %d, want the first page of the new filter", shownRun(section))
+	}
+	if section.loading {
+		t.Error("expected the stale page not to leave the section loading")
+	}
+}
@@ -0,0 +1,391 @@
+package sections
+
+import (
+	"azdoext/pkg/azdo"
+	"azdoext/pkg/listitems"
+	"azdoext/pkg/logger"
+	"azdoext/pkg/styles"
+	"azdoext/pkg/teamsg"
+	"context"
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+type runOptionKind int
+
+const (
+	branchOption runOptionKind = iota
+	commitOption
+	parameterOption
+	variableOption
+	stageOption
+)
+
+// runOption is a field of the run options form, text fields use input,
+// fields with a fixed set of values (e.g. boolean parameters) use values and selected, stages use skip
+type runOption struct {
+	kind     runOptionKind
+	name     string
+	label    string
+	input    textinput.Model
+	values   []string
+	selected int
+	initial  string
+	skip     bool
+}
+
+func (o runOption) value() string {
+	if len(o.values) > 0 {
+		return o.values[o.selected]
+	}
+	return o.input.Value()
+}
+
+type RunOptionsSection struct {
+	logger            *logger.Logger
+	hidden            bool
+	focused           bool
+	ctx               context.Context
+	client            azdo.PipelinesClientInterface
+	sectionIdentifier SectionName
+	currentBranch     string
+	pipeline          listitems.PipelineItem
+	options           []runOption
+	cursor            int
+	loadedBranch      string
+	loading           bool
+	errorMessage      string
+	height            int
+	help              string
+}
+
+func NewRunOptions(ctx context.Context, secid SectionName, client azdo.PipelinesClientInterface, azdoconfig azdo.Config) Section {
+	logger := logger.NewLogger("runoptions")
+	help := styles.ShortHelpStyle.Render("↑/↓ move • ←/→ change value • space skip stage • ctrl+s queue • esc cancel")
+	return &RunOptionsSection{
+		logger:            logger,
+		ctx:               ctx,
+		client:            client,
+		sectionIdentifier: secid,
+		currentBranch:     azdoconfig.CurrentBranch,
+		help:              help,
+	}
+}
+
+func (r *RunOptionsSection) GetSectionIdentifier() SectionName {
+	return r.sectionIdentifier
+}
+
+func (r *RunOptionsSection) IsHidden() bool {
+	return r.hidden
+}
+
+func (r *RunOptionsSection) IsFocused() bool {
+	return r.focused
+}
+
+func (r *RunOptionsSection) Hide() {
+	r.hidden = true
+	r.focused = false
+}
+
+func (r *RunOptionsSection) Show() {
+	r.hidden = false
+}
+
+func (r *RunOptionsSection) Focus() {
+	r.Show()
+	r.focused = true
+	r.focusCursor()
+}
+
+func (r *RunOptionsSection) Blur() {
+	r.focused = false
+	r.blurCursor()
+}
+
+func (r *RunOptionsSection) SetDimensions(width, height int) {
+	r.height = height
+	for i := range r.options {
+		r.options[i].input.SetWidth(styles.DefaultSectionWidth)
+	}
+}
+
+func newTextOption(kind runOptionKind, name, label, value string) runOption {
+	input := textinput.New()
+	input.Prompt = ""
+	input.SetWidth(styles.DefaultSectionWidth)
+	input.SetValue(value)
+	return runOption{kind: kind, name: name, label: label, input: input, initial: value}
+}
+
+// setOptions rebuilds the form, keeping the branch and commit the user typed
+func (r *RunOptionsSection) setOptions(branch, commit string, runOptions azdo.RunOptions) {
+	options := []runOption{
+		newTextOption(branchOption, "branch", "Branch", branch),
+		newTextOption(commitOption, "commit", "Commit (optional)", commit),
+	}
+	for _, p := range runOptions.Parameters {
+		label := p.DisplayName
+		if label == "" {
+			label = p.Name
+		}
+		if len(p.Values) > 0 {
+			option := runOption{kind: parameterOption, name: p.Name, label: label, values: p.Values, initial: p.Default}
+			for i, v := range p.Values {
+				if v == p.Default {
+					option.selected = i
+				}
+			}
+			options = append(options, option)
+			continue
+		}
+		options = append(options, newTextOption(parameterOption, p.Name, label, p.Default))
+	}
+	for _, v := range runOptions.Variables {
+		option := newTextOption(variableOption, v.Name, "$("+v.Name+")", v.Value)
+		if v.IsSecret {
+			option.input.EchoMode = textinput.EchoPassword
+		}
+		options = append(options, option)
+	}
+	for _, s := range runOptions.Stages {
+		options = append(options, runOption{kind: stageOption, name: s, label: s})
+	}
+	r.blurCursor()
+	r.options = options
+	if r.cursor >= len(options) {
+		r.cursor = 0
+	}
+	r.loadedBranch = branch
+	if r.focused {
+		r.focusCursor()
+	}
+}
+
+func (r *RunOptionsSection) focusCursor() {
+	if r.cursor < len(r.options) && len(r.options[r.cursor].values) == 0 && r.options[r.cursor].kind != stageOption {
+		r.options[r.cursor].input.Focus()
+	}
+}
+
+func (r *RunOptionsSection) blurCursor() {
+	if r.cursor < len(r.options) {
+		r.options[r.cursor].input.Blur()
+	}
+}
+
+func (r *RunOptionsSection) fetchOptions(branch string) tea.Cmd {
+	pipelineId := r.pipeline.Id
+	return func() tea.Msg {
+		options, err := r.client.GetRunOptions(r.ctx, pipelineId, branch)
+		return teamsg.RunOptionsFetchedMsg{PipelineId: pipelineId, Options: options, Err: err}
+	}
+}
+
+// moveCursor reloads the options when leaving a changed branch, parameters and stages may differ between branches
+func (r *RunOptionsSection) moveCursor(delta int) tea.Cmd {
+	if len(r.options) == 0 {
+		return nil
+	}
+	r.blurCursor()
+	leaving := r.options[r.cursor]
+	r.cursor = (r.cursor + delta + len(r.options)) % len(r.options)
+	r.focusCursor()
+	if leaving.kind == branchOption && leaving.value() != "" && leaving.value() != r.loadedBranch {
+		r.loading = true
+		r.loadedBranch = leaving.value()
+		return r.fetchOptions(leaving.value())
+	}
+	return nil
+}
+
+func (r *RunOptionsSection) runArgs() azdo.RunPipelineArgs {
+	args := azdo.RunPipelineArgs{
+		PipelineId:         r.pipeline.Id,
+		TemplateParameters: map[string]string{},
+		Variables:          map[string]string{},
+	}
+	for _, o := range r.options {
+		switch o.kind {
+		case branchOption:
+			args.Branch = strings.TrimSpace(o.value())
+		case commitOption:
+			args.Commit = strings.TrimSpace(o.value())
+		case parameterOption:
+			if o.value() != "" {
+				args.TemplateParameters[o.name] = o.value()
+			}
+		case variableOption:
+			// unchanged variables keep the definition value, including secrets that can't be read back
+			if o.value() != o.initial {
+				args.Variables[o.name] = o.value()
+			}
+		case stageOption:
+			if o.skip {
+				args.StagesToSkip = append(args.StagesToSkip, o.name)
+			}
+		}
+	}
+	return args
+}
+
+func (r *RunOptionsSection) queue() tea.Cmd {
+	args := r.runArgs()
+	pipelineName := r.pipeline.Name
+	return func() tea.Msg {
+		runId, err := r.client.RunPipeline(r.ctx, args)
+		if err != nil {
+			return teamsg.RunOptionsErrorMsg(err.Error())
+		}
+		return teamsg.PipelineRunIdMsg{RunId: runId, PipelineName: pipelineName, Status: "notStarted"}
+	}
+}
+
+func (r *RunOptionsSection) Update(msg tea.Msg) (Section, tea.Cmd) {
+	switch msg := msg.(type) {
+	case teamsg.RunOptionsMsg:
+		r.pipeline = listitems.PipelineItem(msg)
+		r.errorMessage = ""
+		r.loading = true
+		// pipelines of other repositories run on their default branch unless one is typed
+		branch := r.currentBranch
+		if r.pipeline.External {
+			branch = ""
+		}
+		r.loadedBranch = branch
+		r.setOptions(branch, "", azdo.RunOptions{})
+		return r, r.fetchOptions(branch)
+	case teamsg.RunOptionsFetchedMsg:
+		if msg.PipelineId != r.pipeline.Id {
+			return r, nil
+		}
+		r.loading = false
+		if msg.Err != nil {
+			r.logger.Error("error fetching run options", "error", msg.Err)
+			r.errorMessage = msg.Err.Error()
+			return r, nil
+		}
+		r.errorMessage = ""
+		branch, commit := r.loadedBranch, ""
+		if len(r.options) > 1 {
+			branch, commit = r.options[0].value(), r.options[1].value()
+		}
+		if branch == "" {
+			branch = msg.Options.Branch
+			r.loadedBranch = branch
+		}
+		r.setOptions(branch, commit, msg.Options)
+		return r, nil
+	case teamsg.RunOptionsErrorMsg:
+		r.logger.Error("error queueing pipeline", "error", string(msg))
+		r.errorMessage = string(msg)
+		return r, nil
+	case tea.KeyPressMsg:
+		if !r.focused {
+			return r, nil
+		}
+		switch msg.String() {
+		case "up", "shift+tab":
+			return r, r.moveCursor(-1)
+		case "down", "enter":
+			return r, r.moveCursor(1)
+		case "ctrl+s":
+			if r.loading {
+				return r, nil
+			}
+			r.errorMessage = ""
+			return r, r.queue()
+		}
+		if r.cursor >= len(r.options) {
+			return r, nil
+		}
+		option := &r.options[r.cursor]
+		switch {
+		case option.kind == stageOption:
+			if msg.String() == "space" {
+				option.skip = !option.skip
+			}
+			return r, nil
+		case len(option.values) > 0:
+			switch msg.String() {
+			case "left":
+				option.selected = (option.selected - 1 + len(option.values)) % len(option.values)
+			case "right", "space":
+				option.selected = (option.selected + 1) % len(option.values)
+			}
+			return r, nil
+		}
+		input, cmd := option.input.Update(msg)
+		option.input = input
+		return r, cmd
+	}
+	return r, nil
+}
+
+func (r *RunOptionsSection) View() string {
+	title := styles.TitleStyle.Render(fmt.Sprintf("Run %s", r.pipeline.Name))
+	var lines []string
+	cursorLine := 0
+	labelStyle := lipgloss.NewStyle().Bold(true)
+	selectedLabelStyle := labelStyle.Foreground(styles.Yellow)
+	headers := map[runOptionKind]string{
+		parameterOption: "Parameters",
+		variableOption:  "Variables",
+		stageOption:     "Stages to skip",
+	}
+	var previousKind runOptionKind = -1
+	for i, o := range r.options {
+		if header, ok := headers[o.kind]; ok && o.kind != previousKind {
+			lines = append(lines, "", lipgloss.NewStyle().Foreground(styles.Grey).Render(header))
+		}
+		previousKind = o.kind
+		label := labelStyle.Render(o.label)
+		if i == r.cursor {
+			cursorLine = len(lines)
+			if r.focused {
+				label = selectedLabelStyle.Render(o.label)
+			}
+		}
+		switch {
+		case o.kind == stageOption:
+			check := "[ ]"
+			if o.skip {
+				check = "[x]"
+			}
+			lines = append(lines, check+" "+label)
+		case len(o.values) > 0:
+			lines = append(lines, label, "‹ "+o.value()+" ›")
+		default:
+			lines = append(lines, label, o.input.View())
+		}
+	}
+	var status []string
+	if r.loading {
+		status = append(status, "", "Loading parameters, variables and stages...")
+	}
+	if r.errorMessage != "" {
+		errorStyle := lipgloss.NewStyle().Foreground(styles.Red).Width(styles.DefaultSectionWidth + 20)
+		status = append(status, "", errorStyle.Render(r.errorMessage))
+	}
+	// scroll the fields around the cursor so the title, status and help stay visible
+	available := r.height - 4 - lipgloss.Height(strings.Join(status, "\n"))
+	if r.height > 0 && len(lines) > available {
+		available = max(available, 2)
+		start := max(cursorLine-available/2, 0)
+		end := min(start+available, len(lines))
+		lines = lines[max(end-available, 0):end]
+	}
+	view := append([]string{title, ""}, lines...)
+	view = append(view, status...)
+	view = append(view, "", r.help)
+	secView := lipgloss.JoinVertical(lipgloss.Left, view...)
+	if r.focused {
+		return styles.ActiveStyle.Render(secView)
+	}
+	return styles.InactiveStyle.Render(secView)
+}
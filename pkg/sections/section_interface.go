@@ -20,14 +20,22 @@ type Section interface {
 type SectionName string
 
 const (
-	PrOrPipelineChoice   SectionName = "prOrPipelineChoice"
-	PipelineActionChoice SectionName = "pipelineActionChoice"
-	Commit               SectionName = "commit"
-	Worktree             SectionName = "worktree"
-	AzdoSection          SectionName = "azdoSection"
-	OpenPR               SectionName = "openPR"
-	Help                 SectionName = "help"
-	PipelineTasks        SectionName = "pipelineTasks"
-	LogViewport          SectionName = "logviewport"
-	PipelineList         SectionName = "pipelineList"
+	PrOrPipelineChoice    SectionName = "prOrPipelineChoice"
+	PipelineActionChoice  SectionName = "pipelineActionChoice"
+	Commit                SectionName = "commit"
+	Worktree              SectionName = "worktree"
+	AzdoSection           SectionName = "azdoSection"
+	OpenPR                SectionName = "openPR"
+	Help                  SectionName = "help"
+	PipelineTasks         SectionName = "pipelineTasks"
+	LogViewport           SectionName = "logviewport"
+	PipelineList          SectionName = "pipelineList"
+	RunOptions            SectionName = "runOptions"
+	RunHistory            SectionName = "runHistory"
+	PipelinesEmptyState   SectionName = "pipelinesEmptyState"
+	CreatePipeline        SectionName = "createPipeline"
+	PullRequestList       SectionName = "pullRequestList"
+	PullRequestDetail     SectionName = "pullRequestDetail"
+	PullRequestStatus     SectionName = "pullRequestStatus"
+	PullRequestCompletion SectionName = "pullRequestCompletion"
 )
@@ -7,7 +7,9 @@ import (
 	"azdoext/pkg/logger"
 	"azdoext/pkg/styles"
 	"azdoext/pkg/teamsg"
+	"context"
 	"errors"
+	"fmt"
 
 	bubbleshelp "charm.land/bubbles/v2/help"
 	"charm.land/bubbles/v2/key"
@@ -25,10 +27,17 @@ type WorktreeSection struct {
 	branch            string
 	sectionIdentifier SectionName
 	azdoconfig        azdo.Config
+	authProvider      azdo.AuthProvider
 }
 
 func (ws *WorktreeSection) push() tea.Msg {
-	gitexec.Push("origin", ws.branch, ws.azdoconfig.AuthHeader)
+	authHeader, err := ws.authProvider(context.Background())
+	if err != nil {
+		return teamsg.GitPushErrorMsg(fmt.Sprintf("failed to get authorization header: %v", err))
+	}
+	if err := gitexec.Push("origin", ws.branch, authHeader); err != nil {
+		return teamsg.GitPushErrorMsg(err.Error())
+	}
 	return teamsg.GitPushedMsg(true)
 }
 
@@ -37,8 +46,8 @@ func (ws *WorktreeSection) addAllToStage() {
 	ws.setStagedFileList()
 }
 
-func NewWorktreeSection(secid SectionName, currentBranch string, azdoconfig azdo.Config) Section {
-	logger := logger.NewLogger("worktree.log")
+func NewWorktreeSection(secid SectionName, currentBranch string, azdoconfig azdo.Config, authProvider azdo.AuthProvider) Section {
+	logger := logger.NewLogger("worktree")
 	worktreeSection := &WorktreeSection{}
 	worktreeSection.branch = currentBranch
 	worktreeSection.logger = logger
@@ -61,6 +70,7 @@ func NewWorktreeSection(secid SectionName, currentBranch string, azdoconfig azdo
 	worktreeSection.customhelp = customhelp
 	worktreeSection.sectionIdentifier = secid
 	worktreeSection.azdoconfig = azdoconfig
+	worktreeSection.authProvider = authProvider
 	return worktreeSection
 }
 
@@ -114,6 +124,9 @@ func (ws *WorktreeSection) Update(msg tea.Msg) (Section, tea.Cmd) {
 		return ws, tea.Batch(ws.push, func() tea.Msg { return teamsg.GitPushingMsg(true) })
 	case teamsg.GitPushedMsg:
 		ws.status.Title = "Pushed"
+	case teamsg.GitPushErrorMsg:
+		ws.logger.Error("push failed", "error", string(msg))
+		ws.status.Title = "Push failed: " + string(msg)
 	}
 	if len(ws.status.Items()) == 0 {
 		return ws, func() tea.Msg { return teamsg.NothingToCommitMsg{} }
@@ -0,0 +1,72 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// favoritesPath returns favorites.json next to config.json
+func favoritesPath() (string, error) {
+	path, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "favorites.json"), nil
+}
+
+// readFavorites reads the favorite pipeline ids of every repository, keyed by repository id
+func readFavorites(path string) (map[string][]int, error) {
+	favorites := make(map[string][]int)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return favorites, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &favorites); err != nil {
+		return nil, fmt.Errorf("invalid favorites file %s: %w", path, err)
+	}
+	return favorites, nil
+}
+
+// LoadFavorites returns the ids of the pipelines pinned on the given repository
+func LoadFavorites(repositoryId string) ([]int, error) {
+	path, err := favoritesPath()
+	if err != nil {
+		return nil, err
+	}
+	favorites, err := readFavorites(path)
+	if err != nil {
+		return nil, err
+	}
+	return favorites[repositoryId], nil
+}
+
+// SaveFavorites replaces the pipelines pinned on the given repository, keeping the other repositories' ones
+func SaveFavorites(repositoryId string, pipelineIds []int) error {
+	path, err := favoritesPath()
+	if err != nil {
+		return err
+	}
+	favorites, err := readFavorites(path)
+	if err != nil {
+		return err
+	}
+	if len(pipelineIds) == 0 {
+		delete(favorites, repositoryId)
+	} else {
+		favorites[repositoryId] = pipelineIds
+	}
+	content, err := json.MarshalIndent(favorites, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0600)
+}
@@ -0,0 +1,47 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Settings is the user configuration read from config.json under the user config directory
+type Settings struct {
+	// Auth is the ordered list of auth methods to try, see azdo.AllAuthMethods
+	Auth []string `json:"auth,omitempty"`
+	// ExtraPipelines are pipelines of other repositories of the project listed along the pipelines of a repository,
+	// keyed by repository name. A pipeline is given by id or by path, e.g. \infra\deploy-app
+	ExtraPipelines map[string][]string `json:"extraPipelines,omitempty"`
+}
+
+// Path returns config.json under the user config directory (e.g. ~/.config/azdoext)
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user config directory: %w", err)
+	}
+	return filepath.Join(dir, "azdoext", "config.json"), nil
+}
+
+// Load reads the settings file, a missing file results in empty settings
+func Load() (Settings, error) {
+	var s Settings
+	path, err := Path()
+	if err != nil {
+		return s, err
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(content, &s); err != nil {
+		return s, fmt.Errorf("invalid settings file %s: %w", path, err)
+	}
+	return s, nil
+}
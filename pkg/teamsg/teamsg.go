@@ -2,6 +2,7 @@ package teamsg
 
 import (
 	"azdoext/pkg/azdo"
+	"azdoext/pkg/gitexec"
 	"azdoext/pkg/listitems"
 	"azdoext/pkg/utils"
 
@@ -11,9 +12,13 @@ import (
 
 /*
 generated by: main loop
-description: this message contains everything needed to interact with Azure Devops and is part return by the Init() function in main.go
+description: this message contains everything needed to interact with Azure Devops and is part return by the Init() function in main.go,
+the config of the repository and the clients of its project
 */
-type AzdoConfigMsg azdo.Config
+type AzdoConfigMsg struct {
+	Config  azdo.Config
+	Clients azdo.Clients
+}
 
 /*
 generated by: main loop
@@ -53,9 +58,70 @@ description: this message indicates that the context for reading logs is done
 /*
 generated by: pipelinelist section in fetchBuilds function
 description: this message contains the list of builds fetched from Azure DevOps matching the current repository. pipelinelist section reacts to it by updating the list
-with current values and calling fetchBuilds() again, creating a loop.
+with current values and calling fetchBuilds() again, creating a loop. Loop identifies the loop, a refresh starts a new loop and the previous one ends.
+*/
+type BuildsFetchedMsg struct {
+	Items []list.Item
+	Loop  int
+}
+
+/*
+generated by: pipelinelist section in fetchBuilds function
+description: this message indicates that the pipelines of the repository can't be listed, Err is nil when the repository has no pipeline,
+PipelineYamls then lists the pipeline YAML files of the worktree a pipeline can be created from.
+it ends the fetchBuilds loop, pipelinelist page reacts to it by showing an empty state section until RefreshPipelinesMsg restarts the loop.
+*/
+type PipelinesUnavailableMsg struct {
+	Err           error
+	PipelineYamls []string
+}
+
+/*
+generated by: pipelinelist page when retrying or after a pipeline was created
+description: this message makes pipelinelist section fetch the pipelines right away and restart the fetchBuilds loop
+*/
+type RefreshPipelinesMsg struct{}
+
+/*
+generated by: pipelinelist page
+description: this message sets what an empty state section shows, Actions are the options it offers, e.g. retry
+*/
+type EmptyStateMsg struct {
+	Title   string
+	Message string
+	Err     error
+	Actions []listitems.OptionName
+}
+
+/*
+generated by: empty state section when an action is selected
+description: this message contains the selected action, the page showing the empty state reacts to it
 */
-type BuildsFetchedMsg []list.Item
+type EmptyStateActionMsg listitems.OptionName
+
+/*
+generated by: pipelinelist section on 'n' key and pipelinelist page on the empty state create pipeline action
+description: this message is used by pipelinelist page to open the create pipeline section, which reacts to it by looking for pipeline YAML files
+*/
+type CreatePipelineMsg struct{}
+
+/*
+generated by: createpipeline section
+description: this message contains the pipeline YAML files of the worktree, relative to its root
+*/
+type PipelineYamlFilesMsg struct {
+	Files []string
+	Err   error
+}
+
+/*
+generated by: createpipeline section after creating a pipeline from a YAML file
+description: this message contains the id of the created pipeline or why it couldn't be created. on success, pipelinelist page closes the form and refreshes the pipelines
+*/
+type PipelineCreatedMsg struct {
+	PipelineId int
+	Err        error
+}
 
 /*
 generated by: pipelinelist section whenever a pipeline is selected
@@ -74,6 +140,51 @@ type PipelineRunIdMsg struct {
 	Status       string
 }
 
+/*
+generated by: pipelinelist page when "Run with options" is chosen for a pipeline
+description: this message tells the runoptions section which pipeline to load the run options (parameters, variables and stages) of
+*/
+type RunOptionsMsg listitems.PipelineItem
+
+/*
+generated by: runoptions section after fetching the run options of a pipeline
+description: this message contains the run options to build the form with, or the error that prevented fetching them
+*/
+type RunOptionsFetchedMsg struct {
+	PipelineId int
+	Options    azdo.RunOptions
+	Err        error
+}
+
+/*
+generated by: pipelinelist page when "Run history" is chosen for a pipeline
+description: this message tells the runhistory section which pipeline to list the runs of
+*/
+type RunHistoryMsg listitems.PipelineItem
+
+/*
+generated by: runhistory section after fetching a page of runs
+description: this message contains the runs of the page and the token of the next one, Request identifies the fetch so stale pages are dropped
+*/
+type RunHistoryFetchedMsg struct {
+	Request           int
+	Items             []list.Item
+	ContinuationToken string
+	Err               error
+}
+
+/*
+generated by: runoptions section when queueing the pipeline fails
+description: this message contains the error returned by the service, e.g. a parameter that failed validation. the form shows it and stays open
+*/
+type RunOptionsErrorMsg string
+
+/*
+generated by: pipelinelist section when queueing the selected pipeline fails
+description: this message contains the error returned by the service, the pipelinelist section shows it instead of opening a run with id 0
+*/
+type RunPipelineErrorMsg string
+
 /*
 generated by: pipelinetasks section whenever a particular task is selected
 description: this is used on logviewport to show the logs of the selected task
@@ -85,17 +196,30 @@ type RecordSelectedMsg struct {
 /*
 generated by: pipelinetasks section on getRunState function
 description: this message is used by pipelinetasks to update the state of the tasks list, it then invokes getRunState again, creating a loop.
+Approvals holds the approvals of the run by timeline record id, logviewport shows them when their record is selected
 */
 type PipelineRunStateMsg struct {
-	Items  []list.Item
-	Status string
+	Items     []list.Item
+	Status    string
+	Approvals map[uuid.UUID]azdo.Approval
+}
+
+/*
+generated by: pipelinetasks section after a confirmed cancel or retry of the monitored run
+description: this message reports the outcome of the action, Retried tells logviewport to stream the logs of the retried jobs
+*/
+type RunActionMsg struct {
+	RunId       int
+	Description string
+	Retried     bool
+	Err         error
 }
 
 /*
 generated by: prtext section on openPR function as a reaction to SubmitPRMsg
-description: this message indicates that a pull request was successfully opened
+description: this message contains the pull request that was successfully opened, the pull requests page shows its status
 */
-type GitPRCreatedMsg struct{}
+type GitPRCreatedMsg listitems.PullRequestItem
 
 /*
 generated by: prtext section on openPR function
@@ -103,18 +227,71 @@ description: this message indicates that there was an error opening the pr
 */
 type PRErrorMsg string
 
+/*
+generated by: prtext section when the PR form opens
+description: this message contains the branches the PR can target and the teams and members of the project that can review it,
+Err joins the errors of whatever couldn't be loaded
+*/
+type PRFormDataMsg struct {
+	Branches   []string
+	Identities []azdo.Identity
+	Err        error
+}
+
 /*
 generated by: prtext section on 'ctrl+s' key
 description: this message contains the content of a submitted pull request
 */
 type SubmitPRMsg string
 
+/*
+generated by: main loop on 'ctrl+o' key from the git or pipelinelist page
+description: this message opens the pull requests page, its pull request list section reloads the pull requests
+*/
+type OpenPullRequestsMsg struct{}
+
+/*
+generated by: pullrequestlist section in fetchPullRequests function
+description: this message contains the pull requests matching the filters of the request with the given number, older requests are ignored.
+UserId is the authenticated user, resolved once to list the pull requests created by or assigned to them
+*/
+type PullRequestsFetchedMsg struct {
+	Request int
+	UserId  uuid.UUID
+	Items   []list.Item
+	Err     error
+}
+
+/*
+generated by: pullrequestlist section on 'enter' key
+description: this message contains the selected pull request, pullrequests page reacts to it by showing its details
+*/
+type PullRequestSelectedMsg listitems.PullRequestItem
+
+/*
+generated by: prstatus section when a pull request is selected or created, again while its blocking policies are running
+description: this message contains the policy evaluations of the pull request shown in the status section, fetched by the request with
+the given number. older requests are ignored, so selecting the pull request again doesn't start a second poll
+*/
+type PullRequestPoliciesMsg struct {
+	Request       int
+	PullRequestId int
+	Evaluations   []azdo.PolicyEvaluation
+	Err           error
+}
+
 /*
 generated by: worktree section
 description: this message indicates whether the git push was successful. it's a reaction to CommitMsg.
 */
 type GitPushedMsg bool
 
+/*
+generated by: worktree section
+description: this message indicates that the git push failed, either getting the authorization header or pushing. it's a reaction to CommitMsg.
+*/
+type GitPushErrorMsg string
+
 /*
 generated by: worktree section
 description: this message indicates that the git push process has started
@@ -138,3 +315,32 @@ type LogMsg struct {
 	BuildResult  string
 	NewContent   string
 }
+
+/*
+generated by: prtext section when the PR form opens or its target branch changes
+description: this message contains the PR template of the repository that applies to the Target branch, empty when there's none
+*/
+type PRTemplateMsg struct {
+	Target   string
+	Template string
+	Err      error
+}
+
+/*
+generated by: prtext section when the title and description are seeded from the commits
+description: this message contains the messages of the commits of the source branch that aren't on the target branch yet, oldest first
+*/
+type PRCommitsMsg struct {
+	Commits []gitexec.CommitMessage
+	Err     error
+}
+
+/*
+generated by: prcompletion section when a pull request is completed, set to auto-complete or abandoned
+description: this message contains the pull request as Azure DevOps left it after the Action, the pull requests page shows it again and refreshes the list
+*/
+type PullRequestUpdatedMsg struct {
+	Action      string
+	PullRequest listitems.PullRequestItem
+	Err         error
+}
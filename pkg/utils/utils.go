@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,15 @@ func Ptr[T any](v T) *T {
 	return &v
 }
 
+// Deref returns the value p points to, or the zero value if p is nil
+func Deref[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
+
 func SleepWithContext(ctx context.Context, wait time.Duration) error {
 	sleepDone := make(chan struct{})
 	go func() {
@@ -63,6 +73,14 @@ func GetRepositoryDefaultBranch(ctx context.Context, azdoconn *azuredevops.Conne
 	return ""
 }
 
+// FormatBranchName returns the full ref name of branch, e.g. refs/heads/main for main
+func FormatBranchName(branch string) string {
+	if strings.HasPrefix(branch, "refs/heads/") {
+		return branch
+	}
+	return "refs/heads/" + branch
+}
+
 // ref: https://forum.golangbridge.org/t/generic-and-typecasting/29903/5
 type Status interface {
 	~string